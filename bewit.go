@@ -0,0 +1,273 @@
+package tcclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	hawk "github.com/tent/hawk-go"
+)
+
+// Failure modes of signed URL verification. The *BewitError returned by
+// VerifySignedURL matches exactly one of these with errors.Is.
+var (
+	ErrBewitMissing   = errors.New("tcclient: url has no bewit")
+	ErrBewitMalformed = errors.New("tcclient: bewit is malformed")
+	ErrBewitSignature = errors.New("tcclient: bewit signature does not match access token")
+	ErrBewitExpired   = errors.New("tcclient: bewit has expired")
+)
+
+// BewitError describes why a signed URL failed verification.
+type BewitError struct {
+	// One of the ErrBewitXxx errors
+	Err error
+	// Human readable details of the failure
+	Detail string
+}
+
+func (err *BewitError) Error() string {
+	if err.Detail == "" {
+		return err.Err.Error()
+	}
+	return err.Err.Error() + ": " + err.Detail
+}
+
+// Unwrap returns the ErrBewitXxx error describing the failure mode.
+func (err *BewitError) Unwrap() error {
+	return err.Err
+}
+
+// Bewit is the decoded bewit of a signed URL (see Client.SignedURL).
+type Bewit struct {
+	// The client id of the credentials that signed the URL
+	ClientID string
+	// Time after which the signed URL is no longer valid, according to the
+	// clock of the service
+	Expiry time.Time
+	// Base64 encoded Hawk MAC
+	MAC string
+	// Base64 encoded ext of the bewit (see ExtHeader), or the empty string
+	Ext string
+	// The certificate of the temporary credentials that signed the URL, or
+	// nil if they were permanent credentials
+	Certificate *Certificate
+	// The scopes that the signed URL was restricted to, or nil if it was
+	// not restricted
+	AuthorizedScopes []string
+
+	raw string
+}
+
+// ParseBewit decodes the value of the bewit query string parameter of a
+// signed URL. The signature of the bewit is not checked.
+func ParseBewit(bewit string) (*Bewit, error) {
+	if bewit == "" {
+		return nil, &BewitError{Err: ErrBewitMissing}
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(bewit, "="))
+	if err != nil {
+		return nil, &BewitError{Err: ErrBewitMalformed, Detail: err.Error()}
+	}
+	parts := strings.Split(string(decoded), `\`)
+	if len(parts) != 4 {
+		return nil, &BewitError{Err: ErrBewitMalformed, Detail: "expected 4 fields but found " + strconv.Itoa(len(parts))}
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, &BewitError{Err: ErrBewitMalformed, Detail: "invalid expiry " + strconv.Quote(parts[1])}
+	}
+	b := &Bewit{
+		ClientID: parts[0],
+		Expiry:   time.Unix(expiry, 0),
+		MAC:      parts[2],
+		Ext:      parts[3],
+		raw:      bewit,
+	}
+	if b.Ext != "" {
+		extJSON, err := base64.StdEncoding.DecodeString(b.Ext)
+		if err != nil {
+			return nil, &BewitError{Err: ErrBewitMalformed, Detail: "ext is not base64 encoded: " + err.Error()}
+		}
+		ext := new(ExtHeader)
+		err = json.Unmarshal(extJSON, ext)
+		if err != nil {
+			return nil, &BewitError{Err: ErrBewitMalformed, Detail: "ext is not valid json: " + err.Error()}
+		}
+		b.Certificate = ext.Certificate
+		if ext.AuthorizedScopes != nil {
+			b.AuthorizedScopes = *ext.AuthorizedScopes
+		}
+	}
+	return b, nil
+}
+
+// ParseSignedURL decodes the bewit of signedURL. The signature of the bewit
+// is not checked; see VerifySignedURL.
+func ParseSignedURL(signedURL string) (*Bewit, error) {
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		return nil, &BewitError{Err: ErrBewitMalformed, Detail: err.Error()}
+	}
+	return ParseBewit(u.Query().Get("bewit"))
+}
+
+// Remaining returns the time until the bewit expires, according to the
+// local clock adjusted by ClockOffset(), or a negative duration if it has
+// expired.
+func (b *Bewit) Remaining() time.Duration {
+	return b.Expiry.Sub(time.Now().Add(ClockOffset()))
+}
+
+// Expired reports whether the bewit has expired.
+func (b *Bewit) Expired() bool {
+	return b.Remaining() <= 0
+}
+
+// VerifySignedURL decodes the bewit of signedURL, and checks that it was
+// signed with accessToken (the access token of the credentials with the
+// client id of the bewit) for the URL it is part of, and has not expired.
+// The returned *Bewit is non-nil whenever the bewit could be decoded, even
+// if verification failed. If verification failed, the error is a
+// *BewitError.
+//
+// The certificate of temporary credentials is not verified; see
+// Credentials.VerifyCertificate.
+func VerifySignedURL(signedURL string, accessToken string) (*Bewit, error) {
+	b, err := ParseSignedURL(signedURL)
+	if err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(signedURL)
+	stripBewit(u)
+	credentials := &hawk.Credentials{
+		ID:   b.ClientID,
+		Key:  accessToken,
+		Hash: sha256.New,
+	}
+	auth, err := hawk.NewURLAuth(u.String(), credentials, 0)
+	if err != nil {
+		return b, &BewitError{Err: ErrBewitMalformed, Detail: err.Error()}
+	}
+	auth.Timestamp = b.Expiry
+	auth.Ext = b.Ext
+	if !hmac.Equal([]byte(auth.Bewit()), []byte(strings.TrimRight(b.raw, "="))) {
+		return b, &BewitError{Err: ErrBewitSignature, Detail: "client " + b.ClientID}
+	}
+	if b.Expired() {
+		return b, &BewitError{Err: ErrBewitExpired, Detail: "expired at " + b.Expiry.UTC().Format(time.RFC3339)}
+	}
+	return b, nil
+}
+
+// stripBewit removes the bewit query string parameter from u, leaving the
+// remaining parameters in their original order
+func stripBewit(u *url.URL) {
+	params := []string{}
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		if param != "" && !strings.HasPrefix(param, "bewit=") {
+			params = append(params, param)
+		}
+	}
+	u.RawQuery = strings.Join(params, "&")
+}
+
+// URLSigner signs URLs with the credentials of a client, all valid until
+// the same expiry. Credentials are resolved once, when the URLSigner is
+// created, so a URLSigner is more efficient than calling SignedURL (or the
+// generated XXX_SignedURL methods) repeatedly when signing many URLs, e.g.
+// links to many artifacts.
+type URLSigner struct {
+	// Time after which URLs signed by the URLSigner are no longer valid,
+	// according to the clock of the service
+	Expiry time.Time
+
+	baseURL     string
+	credentials *hawk.Credentials
+	ext         string
+}
+
+// NewURLSigner returns a *URLSigner which signs URLs with the credentials of
+// client, valid for duration from now.
+func (client *Client) NewURLSigner(duration time.Duration) (*URLSigner, error) {
+	creds, err := client.credentials(client.Context)
+	if err != nil {
+		return nil, err
+	}
+	ext, err := getExtHeader(creds)
+	if err != nil {
+		return nil, err
+	}
+	return &URLSigner{
+		// the bewit timestamp is the expiry time of the signed URL,
+		// according to the clock of the service
+		Expiry:  time.Now().Add(duration + ClockOffset()),
+		baseURL: client.BaseURL,
+		credentials: &hawk.Credentials{
+			ID:   creds.ClientID,
+			Key:  creds.AccessToken,
+			Hash: sha256.New,
+		},
+		ext: ext,
+	}, nil
+}
+
+// Sign returns a signed URL, where route is the url path relative to the
+// BaseURL of the client that created the URLSigner, and query is the set of
+// query string parameters, if any. For example, to sign a link to an
+// artifact with a queue client's URLSigner:
+//
+//  signer.Sign("/task/"+url.QueryEscape(taskID)+"/runs/"+strconv.Itoa(runID)+"/artifacts/"+url.QueryEscape(name), nil)
+func (signer *URLSigner) Sign(route string, query url.Values) (*url.URL, error) {
+	u, err := setURL(&Client{BaseURL: signer.baseURL}, route, query)
+	if err != nil {
+		return nil, err
+	}
+	err = signer.sign(u)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// sign adds a bewit to u, which must not already have one
+func (signer *URLSigner) sign(u *url.URL) error {
+	// the bewit is added by re-encoding the query string, which sorts the
+	// parameters, so sign the url with sorted parameters
+	u.RawQuery = u.Query().Encode()
+	reqAuth, err := hawk.NewURLAuth(u.String(), signer.credentials, 0)
+	if err != nil {
+		return err
+	}
+	reqAuth.Timestamp = signer.Expiry
+	reqAuth.Ext = signer.ext
+	query := u.Query()
+	query.Set("bewit", reqAuth.Bewit())
+	u.RawQuery = query.Encode()
+	return nil
+}
+
+// ResignURL returns signedURL signed afresh with the credentials of client,
+// valid for duration from now. Any existing bewit is replaced. The URL need
+// not be relative to the BaseURL of client.
+func (client *Client) ResignURL(signedURL string, duration time.Duration) (*url.URL, error) {
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		return nil, err
+	}
+	stripBewit(u)
+	signer, err := client.NewURLSigner(duration)
+	if err != nil {
+		return nil, err
+	}
+	err = signer.sign(u)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,165 @@
+package tcclient
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAndVerifySignedURL(t *testing.T) {
+	permaCreds := &Credentials{
+		ClientID:    "perma",
+		AccessToken: "perma-token",
+	}
+	tempCreds, err := permaCreds.CreateNamedTemporaryCredentials("reporter", time.Hour, "queue:get-artifact:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tempCreds.AuthorizedScopes = []string{"queue:get-artifact:private/report.html"}
+	client := &Client{
+		Credentials: tempCreds,
+		BaseURL:     "https://tc.example.com/api/queue/v1",
+	}
+	u, err := client.SignedURL("/task/abc/artifacts/private%2Freport.html", url.Values{"b": {"2"}, "a": {"1"}}, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := VerifySignedURL(u.String(), tempCreds.AccessToken)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b.ClientID != "reporter" || b.Certificate == nil || b.Certificate.Issuer != "perma" || !reflect.DeepEqual(b.AuthorizedScopes, tempCreds.AuthorizedScopes) {
+		t.Errorf("Unexpected bewit %#v", b)
+	}
+	if r := b.Remaining(); r < 9*time.Minute || r > 10*time.Minute || b.Expired() {
+		t.Errorf("Expected bewit to expire in around 10 minutes, but got %v", r)
+	}
+
+	tamperedPath := strings.Replace(u.String(), "/abc/", "/def/", 1)
+	tamperedQuery := strings.Replace(u.String(), "a=1", "a=3", 1)
+	expired, err := client.SignedURL("/task/abc", nil, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name        string
+		signedURL   string
+		accessToken string
+		expected    error
+	}{
+		{"wrong access token", u.String(), "guess", ErrBewitSignature},
+		{"tampered path", tamperedPath, tempCreds.AccessToken, ErrBewitSignature},
+		{"tampered query", tamperedQuery, tempCreds.AccessToken, ErrBewitSignature},
+		{"expired", expired.String(), tempCreds.AccessToken, ErrBewitExpired},
+		{"unsigned", "https://tc.example.com/api/queue/v1/task/abc", tempCreds.AccessToken, ErrBewitMissing},
+		{"malformed", "https://tc.example.com/api/queue/v1/task/abc?bewit=!!!", tempCreds.AccessToken, ErrBewitMalformed},
+		{"wrong field count", "https://tc.example.com/api/queue/v1/task/abc?bewit=YWJj", tempCreds.AccessToken, ErrBewitMalformed},
+	}
+	for _, tc := range testCases {
+		_, err := VerifySignedURL(tc.signedURL, tc.accessToken)
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%v: expected %v but got %v", tc.name, tc.expected, err)
+		}
+		if _, ok := err.(*BewitError); !ok {
+			t.Errorf("%v: expected *BewitError but got %T", tc.name, err)
+		}
+	}
+}
+
+func TestParseBewitPermanentCredentials(t *testing.T) {
+	client := &Client{
+		Credentials: &Credentials{ClientID: "perma", AccessToken: "perma-token"},
+		BaseURL:     "https://tc.example.com/api/queue/v1",
+	}
+	u, err := client.SignedURL("/task/abc", nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseSignedURL(u.String())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b.ClientID != "perma" || b.Ext != "" || b.Certificate != nil || b.AuthorizedScopes != nil {
+		t.Errorf("Unexpected bewit %#v", b)
+	}
+}
+
+func TestResignURL(t *testing.T) {
+	client := &Client{
+		Credentials: &Credentials{ClientID: "perma", AccessToken: "perma-token"},
+		BaseURL:     "https://tc.example.com/api/queue/v1",
+	}
+	u, err := client.SignedURL("/task/abc/artifacts/x", nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resigned, err := client.ResignURL(u.String(), time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := VerifySignedURL(resigned.String(), "perma-token")
+	if err != nil {
+		t.Fatalf("Could not verify resigned URL: %v", err)
+	}
+	if b.Remaining() < 59*time.Minute {
+		t.Errorf("Expected resigned URL to be valid for an hour, but got %v", b.Remaining())
+	}
+	if strings.Count(resigned.RawQuery, "bewit=") != 1 {
+		t.Errorf("Expected exactly one bewit, but got %v", resigned)
+	}
+
+	// unsigned url with unsorted query string
+	resigned, err = client.ResignURL("https://tc.example.com/other/path?z=1&a=2", time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err = VerifySignedURL(resigned.String(), "perma-token"); err != nil {
+		t.Errorf("Could not verify resigned URL %v: %v", resigned, err)
+	}
+}
+
+func TestURLSigner(t *testing.T) {
+	provider := &countingProvider{creds: &Credentials{ClientID: "perma", AccessToken: "perma-token"}}
+	client := &Client{
+		CredentialsProvider: provider,
+		BaseURL:             "https://tc.example.com/api/queue/v1",
+	}
+	signer, err := client.NewURLSigner(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.log", "b.log", "public/c.log"} {
+		u, err := signer.Sign("/task/abc/runs/0/artifacts/"+url.QueryEscape(name), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := VerifySignedURL(u.String(), "perma-token")
+		if err != nil {
+			t.Errorf("Could not verify %v: %v", u, err)
+			continue
+		}
+		if b.Expiry.Unix() != signer.Expiry.Unix() {
+			t.Errorf("Expected expiry %v but got %v", signer.Expiry, b.Expiry)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("Expected credentials to be resolved once, but were resolved %v times", provider.calls)
+	}
+}
+
+// countingProvider is a CredentialsProvider which counts how often it is
+// called
+type countingProvider struct {
+	creds *Credentials
+	calls int
+}
+
+func (p *countingProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	p.calls++
+	return p.creds, nil
+}
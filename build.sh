@@ -1,9 +1,12 @@
 #!/bin/bash -euxv
 
 # options:
-#   -n  skip code generation
-#   -d  update timestamp included in generated docs
+#   -n         skip code generation
+#   -d         update timestamp included in generated docs
+#   -b BUNDLE  generate code from the manifest/references/schemas in BUNDLE
+#              (a local directory or tarball) rather than downloading them
 
+ORIGINAL_DIR="$(pwd)"
 cd "$(dirname "${0}")"
 
 GO_VERSION="$(go version 2>/dev/null | cut -f3 -d' ')"
@@ -12,8 +15,8 @@ GO_MIN="$(echo "${GO_VERSION}" | cut -f2 -d'.')"
 if [ -z "${GO_VERSION}" ]; then
   echo "Have you installed go? I get no result from \`go version\` command." >&2
   exit 64
-elif [ "${GO_MAJ}" != "go1" ] || [ "${GO_MIN}" -lt 8 ]; then
-  echo "Go version go1.x needed, where x >= 8, but the version I found is: '${GO_VERSION}'" >&2
+elif [ "${GO_MAJ}" != "go1" ] || [ "${GO_MIN}" -lt 13 ]; then
+  echo "Go version go1.x needed, where x >= 13, but the version I found is: '${GO_VERSION}'" >&2
   echo "I found it here:" >&2
   which go >&2
   echo "The complete output of \`go version\` command is:" >&2
@@ -33,7 +36,7 @@ UNIX_TIMESTAMP="$(head -1 codegenerator/model-data.txt | sed -n 's/^Generated: /
 GENERATE=true
 NEW_TIMESTAMP=false
 
-while getopts ":nd" opt; do
+while getopts ":ndb:" opt; do
     case "${opt}" in
         n)  GENERATE=false
             ;;
@@ -41,6 +44,14 @@ while getopts ":nd" opt; do
             echo "GENERATING NEW TIMESTAMP IN DOCS"
             NEW_TIMESTAMP=true
             ;;
+        b)  # go generate runs in codegenerator/model, so make the path absolute
+            case "${OPTARG}" in
+                /*) TASKCLUSTER_REFERENCE_BUNDLE="${OPTARG}" ;;
+                *)  TASKCLUSTER_REFERENCE_BUNDLE="${ORIGINAL_DIR}/${OPTARG}" ;;
+            esac
+            export TASKCLUSTER_REFERENCE_BUNDLE
+            echo "GENERATING CODE FROM BUNDLE ${TASKCLUSTER_REFERENCE_BUNDLE}"
+            ;;
     esac
 done
 
@@ -0,0 +1,152 @@
+package tcclient
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Failure modes of certificate verification. The *CertificateError returned
+// by CertificateVerifier.Verify matches exactly one of these with errors.Is.
+var (
+	ErrCertificateMissing     = errors.New("tcclient: credentials have no certificate")
+	ErrCertificateMalformed   = errors.New("tcclient: certificate is not valid json")
+	ErrCertificateVersion     = errors.New("tcclient: unsupported certificate version")
+	ErrCertificateLifetime    = errors.New("tcclient: certificate lifetime exceeds 31 days")
+	ErrCertificateSignature   = errors.New("tcclient: certificate signature does not match issuer access token")
+	ErrCertificateAccessToken = errors.New("tcclient: access token was not derived from certificate seed and issuer access token")
+	ErrCertificateNotYetValid = errors.New("tcclient: certificate is not yet valid")
+	ErrCertificateExpired     = errors.New("tcclient: certificate has expired")
+)
+
+// maxCertificateLifetime is the longest validity period that the auth service
+// accepts for temporary credentials
+const maxCertificateLifetime = 31 * 24 * time.Hour
+
+// certificateClockSkew is how far outside of its validity period the auth
+// service still accepts a certificate, to allow for clock drift
+const certificateClockSkew = 5 * time.Minute
+
+// CertificateError describes why temporary credentials failed verification.
+type CertificateError struct {
+	// One of the ErrCertificateXxx errors
+	Err error
+	// Human readable details of the failure
+	Detail string
+}
+
+func (err *CertificateError) Error() string {
+	if err.Detail == "" {
+		return err.Err.Error()
+	}
+	return err.Err.Error() + ": " + err.Detail
+}
+
+// Unwrap returns the ErrCertificateXxx error describing the failure mode.
+func (err *CertificateError) Unwrap() error {
+	return err.Err
+}
+
+// CertificateInfo describes the certificate of temporary credentials.
+type CertificateInfo struct {
+	Certificate *Certificate
+	// The client id of the temporary credentials
+	ClientID string
+	// The client id of the permanent credentials that issued the certificate
+	Issuer string
+	// Whether the temporary credentials have their own client id, rather
+	// than the client id of the issuer
+	Named bool
+	// Scopes granted by the certificate
+	Scopes []string
+	// Validity period of the certificate
+	Start  time.Time
+	Expiry time.Time
+	// Time until expiry, according to the verifier's clock (negative if
+	// expired)
+	Remaining time.Duration
+}
+
+// CertificateVerifier verifies temporary credentials locally, given the
+// access token of the issuing (permanent) credentials, in the same way that
+// the Taskcluster auth service does. Like the auth service, it allows for 5
+// minutes of clock drift, so a certificate is accepted from 5 minutes before
+// its start until 5 minutes after its expiry.
+type CertificateVerifier struct {
+	// Now returns the current time. If nil, time.Now() adjusted by
+	// ClockOffset() is used.
+	Now func() time.Time
+}
+
+// Verify checks that the certificate of creds was signed with
+// issuerAccessToken, that the access token of creds was derived from it, and
+// that the certificate is currently valid (allowing for clock drift). The returned *CertificateInfo is
+// non-nil whenever the certificate could be parsed, even if verification
+// failed, to help diagnose the failure. If verification failed, the error is
+// a *CertificateError.
+func (v *CertificateVerifier) Verify(creds *Credentials, issuerAccessToken string) (*CertificateInfo, error) {
+	if creds.Certificate == "" {
+		return nil, &CertificateError{Err: ErrCertificateMissing}
+	}
+	cert, err := creds.Cert()
+	if err != nil {
+		return nil, &CertificateError{Err: ErrCertificateMalformed, Detail: err.Error()}
+	}
+	now := time.Now().Add(ClockOffset())
+	if v.Now != nil {
+		now = v.Now()
+	}
+	info := &CertificateInfo{
+		Certificate: cert,
+		ClientID:    creds.ClientID,
+		Issuer:      creds.ClientID,
+		Named:       cert.Issuer != "",
+		Scopes:      cert.Scopes,
+		Start:       time.Unix(0, cert.Start*1e6),
+		Expiry:      time.Unix(0, cert.Expiry*1e6),
+	}
+	if info.Named {
+		info.Issuer = cert.Issuer
+	}
+	info.Remaining = info.Expiry.Sub(now)
+
+	if cert.Version != 1 {
+		return info, &CertificateError{Err: ErrCertificateVersion, Detail: fmt.Sprintf("version %v", cert.Version)}
+	}
+	if lifetime := info.Expiry.Sub(info.Start); lifetime > maxCertificateLifetime {
+		return info, &CertificateError{Err: ErrCertificateLifetime, Detail: "lifetime " + lifetime.String()}
+	}
+	expected := *cert
+	tempClientID := ""
+	if info.Named {
+		tempClientID = creds.ClientID
+	}
+	err = expected.Sign(issuerAccessToken, tempClientID)
+	if err != nil {
+		return info, err
+	}
+	if !hmac.Equal([]byte(expected.Signature), []byte(cert.Signature)) {
+		return info, &CertificateError{Err: ErrCertificateSignature, Detail: "issuer " + info.Issuer}
+	}
+	accessToken, err := generateTemporaryAccessToken(issuerAccessToken, cert.Seed)
+	if err != nil {
+		return info, err
+	}
+	if !hmac.Equal([]byte(accessToken), []byte(creds.AccessToken)) {
+		return info, &CertificateError{Err: ErrCertificateAccessToken}
+	}
+	if now.Add(certificateClockSkew).Before(info.Start) {
+		return info, &CertificateError{Err: ErrCertificateNotYetValid, Detail: "valid from " + info.Start.UTC().Format(time.RFC3339)}
+	}
+	if now.Add(-certificateClockSkew).After(info.Expiry) {
+		return info, &CertificateError{Err: ErrCertificateExpired, Detail: "expired at " + info.Expiry.UTC().Format(time.RFC3339)}
+	}
+	return info, nil
+}
+
+// VerifyCertificate verifies the certificate of temporary credentials with a
+// default CertificateVerifier. See CertificateVerifier.Verify.
+func (creds *Credentials) VerifyCertificate(issuerAccessToken string) (*CertificateInfo, error) {
+	return new(CertificateVerifier).Verify(creds, issuerAccessToken)
+}
@@ -0,0 +1,128 @@
+package tcclient
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyCertificate(t *testing.T) {
+	permaCreds := &Credentials{
+		ClientID:    "perma",
+		AccessToken: "perma-token",
+	}
+	unnamed, err := permaCreds.CreateTemporaryCredentials(time.Hour, "scope:a", "scope:b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := permaCreds.CreateNamedTemporaryCredentials("temp", time.Hour, "scope:a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := unnamed.VerifyCertificate("perma-token")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Named || info.Issuer != "perma" || len(info.Scopes) != 2 || info.Remaining <= 59*time.Minute {
+		t.Errorf("Unexpected certificate info for unnamed credentials: %#v", info)
+	}
+	info, err = named.VerifyCertificate("perma-token")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !info.Named || info.Issuer != "perma" || info.ClientID != "temp" {
+		t.Errorf("Unexpected certificate info for named credentials: %#v", info)
+	}
+
+	tampered := *named
+	tampered.ClientID = "someone-else"
+	wrongToken := *unnamed
+	wrongToken.AccessToken = "guess"
+	badVersion := *unnamed
+	badVersion.Certificate = withCert(t, unnamed, func(cert *Certificate) { cert.Version = 2 })
+	tooLong := *unnamed
+	tooLong.Certificate = withCert(t, unnamed, func(cert *Certificate) { cert.Expiry += int64(31 * 24 * time.Hour / time.Millisecond) })
+	malformed := *unnamed
+	malformed.Certificate = "{"
+
+	testCases := []struct {
+		name              string
+		creds             *Credentials
+		issuerAccessToken string
+		now               time.Time
+		expected          error
+	}{
+		{"permanent credentials", permaCreds, "perma-token", time.Now(), ErrCertificateMissing},
+		{"malformed", &malformed, "perma-token", time.Now(), ErrCertificateMalformed},
+		{"bad version", &badVersion, "perma-token", time.Now(), ErrCertificateVersion},
+		{"too long", &tooLong, "perma-token", time.Now(), ErrCertificateLifetime},
+		{"wrong issuer token", unnamed, "other-token", time.Now(), ErrCertificateSignature},
+		{"renamed", &tampered, "perma-token", time.Now(), ErrCertificateSignature},
+		{"wrong access token", &wrongToken, "perma-token", time.Now(), ErrCertificateAccessToken},
+		{"not yet valid", unnamed, "perma-token", time.Now().Add(-time.Hour), ErrCertificateNotYetValid},
+		{"expired", unnamed, "perma-token", time.Now().Add(2 * time.Hour), ErrCertificateExpired},
+	}
+	for _, tc := range testCases {
+		now := tc.now
+		verifier := &CertificateVerifier{Now: func() time.Time { return now }}
+		_, err := verifier.Verify(tc.creds, tc.issuerAccessToken)
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%v: expected %v but got %v", tc.name, tc.expected, err)
+		}
+		if _, ok := err.(*CertificateError); !ok {
+			t.Errorf("%v: expected *CertificateError but got %T", tc.name, err)
+		}
+	}
+}
+
+func TestVerifyCertificateClockSkew(t *testing.T) {
+	permaCreds := &Credentials{
+		ClientID:    "perma",
+		AccessToken: "perma-token",
+	}
+	creds, err := permaCreds.CreateTemporaryCredentials(time.Hour, "scope:a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := creds.Cert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(0, cert.Start*1e6)
+	expiry := time.Unix(0, cert.Expiry*1e6)
+	// the auth service allows for 5 minutes of clock drift
+	testCases := []struct {
+		name     string
+		now      time.Time
+		expected error
+	}{
+		{"5 minutes before start", start.Add(-5 * time.Minute), nil},
+		{"over 5 minutes before start", start.Add(-5*time.Minute - time.Millisecond), ErrCertificateNotYetValid},
+		{"5 minutes after expiry", expiry.Add(5 * time.Minute), nil},
+		{"over 5 minutes after expiry", expiry.Add(5*time.Minute + time.Millisecond), ErrCertificateExpired},
+	}
+	for _, tc := range testCases {
+		now := tc.now
+		verifier := &CertificateVerifier{Now: func() time.Time { return now }}
+		_, err := verifier.Verify(creds, "perma-token")
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%v: expected %v but got %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+// withCert returns the certificate of creds, modified by alter.
+func withCert(t *testing.T, creds *Credentials, alter func(cert *Certificate)) string {
+	cert, err := creds.Cert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alter(cert)
+	data, err := json.Marshal(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,109 @@
+package tcclient
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	hawk "github.com/tent/hawk-go"
+)
+
+// clockOffset is the estimated difference between the clocks of Taskcluster
+// services and the local clock, in nanoseconds. It is shared by all clients,
+// since the skew is a property of the local machine.
+var clockOffset int64
+
+// ClockOffset returns the offset that is added to the local time when signing
+// requests and URLs, to compensate for the local clock being out of sync with
+// Taskcluster services. It is zero unless a service has reported a Hawk
+// timestamp skew, or SetClockOffset has been called.
+func ClockOffset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&clockOffset))
+}
+
+// SetClockOffset sets the offset that is added to the local time when signing
+// requests and URLs, for all clients. Normally this is not needed, since the
+// offset is corrected automatically when a service reports a Hawk timestamp
+// skew.
+func SetClockOffset(offset time.Duration) {
+	atomic.StoreInt64(&clockOffset, int64(offset))
+}
+
+// minClockCorrection is the smallest change to the clock offset that is worth
+// making; smaller discrepancies are within the tolerance of Hawk, so cannot
+// be the cause of an authentication failure.
+const minClockCorrection = time.Second
+
+var wwwAuthenticateAttr = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// updateClockOffset checks whether resp is a Hawk authentication failure due
+// to timestamp skew, and if so, updates the clock offset and reports whether
+// it changed. The offset is taken from the server timestamp in the
+// WWW-Authenticate header, if present and correctly signed, and otherwise
+// from the Date header of the response.
+func updateClockOffset(auth *hawk.Auth, resp *http.Response) bool {
+	if resp.StatusCode != 401 {
+		return false
+	}
+	now := time.Now()
+	attrs := map[string]string{}
+	for _, match := range wwwAuthenticateAttr.FindAllStringSubmatch(resp.Header.Get("WWW-Authenticate"), -1) {
+		attrs[match[1]] = match[2]
+	}
+	if ts, tsm := attrs["ts"], attrs["tsm"]; ts != "" && validTimestampMAC(auth, ts, tsm) {
+		if seconds, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			return setClockOffset(time.Unix(seconds, 0).Sub(now))
+		}
+	}
+	if !timestampSkew(attrs["error"], resp) {
+		return false
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return false
+	}
+	return setClockOffset(date.Sub(now))
+}
+
+// validTimestampMAC reports whether tsm is the Hawk MAC of the server
+// timestamp ts, which proves that the timestamp came from the service.
+func validTimestampMAC(auth *hawk.Auth, ts, tsm string) bool {
+	mac := hmac.New(auth.Credentials.Hash, []byte(auth.Credentials.Key))
+	mac.Write([]byte("hawk.1.ts\n" + ts + "\n"))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(tsm))
+}
+
+// timestampSkew reports whether the WWW-Authenticate error, or the (buffered)
+// body of the 401 response, indicates a Hawk timestamp skew.
+func timestampSkew(wwwAuthenticateError string, resp *http.Response) bool {
+	if strings.Contains(strings.ToLower(wwwAuthenticateError), "stale timestamp") {
+		return true
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "ts skew") || strings.Contains(message, "stale timestamp")
+}
+
+// setClockOffset sets the clock offset, unless it is too close to the current
+// offset to make a difference, and reports whether it was set.
+func setClockOffset(offset time.Duration) bool {
+	change := offset - ClockOffset()
+	if change > -minClockCorrection && change < minClockCorrection {
+		return false
+	}
+	SetClockOffset(offset)
+	return true
+}
@@ -0,0 +1,126 @@
+package tcclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// skewedServer returns a test server whose clock is an hour ahead of the
+// local clock, and that rejects Hawk timestamps more than a minute away from
+// its own. If signed is true, a signed server timestamp is returned in the
+// WWW-Authenticate header of skew errors; otherwise only the Date header
+// reveals the server time.
+func skewedServer(creds *Credentials, signed bool) (*httptest.Server, *int) {
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		serverTime := time.Now().Add(time.Hour)
+		match := wwwAuthenticateAttr.FindAllStringSubmatch(r.Header.Get("Authorization"), -1)
+		ts := int64(0)
+		for _, attr := range match {
+			if attr[1] == "ts" {
+				ts, _ = strconv.ParseInt(attr[2], 10, 64)
+			}
+		}
+		if skew := serverTime.Sub(time.Unix(ts, 0)); skew > time.Minute || skew < -time.Minute {
+			if signed {
+				serverTS := strconv.FormatInt(serverTime.Unix(), 10)
+				mac := hmac.New(sha256.New, []byte(creds.AccessToken))
+				mac.Write([]byte("hawk.1.ts\n" + serverTS + "\n"))
+				tsm := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+				w.Header().Set("WWW-Authenticate", `Hawk ts="`+serverTS+`", tsm="`+tsm+`", error="Stale timestamp"`)
+			}
+			w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+			w.WriteHeader(401)
+			w.Write([]byte(`{"code": "AuthenticationFailed", "message": "Authentication Error: ts skew"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	return s, &requests
+}
+
+func TestClockSkewCorrection(t *testing.T) {
+	creds := &Credentials{
+		ClientID:    "tester",
+		AccessToken: "no-secret",
+	}
+	for _, signed := range []bool{true, false} {
+		SetClockOffset(0)
+		s, requests := skewedServer(creds, signed)
+		client := Client{
+			Credentials:  creds,
+			BaseURL:      s.URL,
+			Authenticate: true,
+			RetryPolicy:  NoRetries,
+		}
+		cs, err := client.Request(nil, "GET", "/whatever", nil)
+		if err != nil {
+			t.Fatalf("Signed: %v: unexpected error: %v", signed, err)
+		}
+		if *requests != 2 || cs.Attempts != 1 {
+			t.Fatalf("Signed: %v: expected a single retry, but got %v requests", signed, *requests)
+		}
+		if offset := ClockOffset(); offset < 59*time.Minute || offset > 61*time.Minute {
+			t.Fatalf("Signed: %v: expected clock offset of an hour, but got %v", signed, offset)
+		}
+		// the corrected offset is shared with other clients
+		other := client
+		_, err = other.Request(nil, "GET", "/whatever", nil)
+		if err != nil || *requests != 3 {
+			t.Fatalf("Signed: %v: expected other client to succeed first time, but got %v (%v requests)", signed, err, *requests)
+		}
+		s.Close()
+	}
+	SetClockOffset(0)
+}
+
+func TestClockSkewUnrelatedAuthFailure(t *testing.T) {
+	SetClockOffset(0)
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		w.WriteHeader(401)
+		w.Write([]byte(`{"code": "AuthenticationFailed", "message": "Unknown clientId"}`))
+	}))
+	defer s.Close()
+	client := Client{
+		Credentials:  &Credentials{ClientID: "tester", AccessToken: "no-secret"},
+		BaseURL:      s.URL,
+		Authenticate: true,
+	}
+	_, err := client.Request(nil, "GET", "/whatever", nil)
+	if err == nil || requests != 1 || ClockOffset() != 0 {
+		t.Fatalf("Expected a single failed request and no clock correction, but got %v (%v requests, offset %v)", err, requests, ClockOffset())
+	}
+}
+
+func TestSignedURLClockOffset(t *testing.T) {
+	SetClockOffset(time.Hour)
+	defer SetClockOffset(0)
+	client := Client{
+		Credentials: &Credentials{ClientID: "tester", AccessToken: "no-secret"},
+		BaseURL:     "https://tc.example.com/api/queue/v1",
+	}
+	u, err := client.SignedURL("/task/abc", nil, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	bewit, err := base64.RawURLEncoding.DecodeString(u.Query().Get("bewit"))
+	if err != nil {
+		t.Fatalf("Could not decode bewit: %v", err)
+	}
+	expiry, _ := strconv.ParseInt(strings.Split(string(bewit), `\`)[1], 10, 64)
+	expected := time.Now().Add(time.Hour + 15*time.Minute).Unix()
+	if expiry < expected-5 || expiry > expected+5 {
+		t.Fatalf("Expected bewit expiry %v to include clock offset (around %v)", expiry, expected)
+	}
+}
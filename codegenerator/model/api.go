@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/taskcluster/jsonschema2go"
 	"github.com/taskcluster/jsonschema2go/text"
 	tcclient "github.com/taskcluster/taskcluster-client-go"
 	tcurls "github.com/taskcluster/taskcluster-lib-urls"
@@ -49,8 +50,10 @@ func (api *API) postPopulate(apiDef *APIDefinition) {
 
 	// reserved package members
 	api.apiDef.members = map[string]bool{
-		"New":        true,
-		"NewFromEnv": true,
+		"New":            true,
+		"NewFromEnv":     true,
+		"NewFromProfile": true,
+		"API":            true,
 	}
 
 	// make sure each entry defined for this API has a unique generated method name
@@ -122,11 +125,13 @@ func (api *API) generateAPICode(apiName string) string {
 
 	content += `
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/url"
 	"time"
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type ` + api.Name() + ` tcclient.Client
@@ -219,10 +224,126 @@ func NewFromEnv() *` + api.Name() + ` {
 	}
 }
 
+// NewFromProfile returns ` + text.IndefiniteArticle(api.Name()) + ` *` + api.Name() + ` configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*` + api.Name() + `, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("` + api.apiDef.PackageName + `.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
 `
+	methods := ""
 	for _, entry := range api.Entries {
-		content += entry.generateAPICode(apiName)
+		methods += entry.generateAPICode(apiName)
 	}
+	content += api.generateInterface(methods)
+	content += methods
+	content += api.generateIterators()
+	content += api.generateRequiredScopes()
+	return content
+}
+
+// generateInterface returns an API interface listing the signatures of all
+// of the methods in methods (the generated API methods of api), together
+// with an assertion that *<api.Name()> implements it
+func (api *API) generateInterface(methods string) string {
+	prefix := "func (" + api.apiDef.ExampleVarName + " *" + api.Name() + ") "
+	content := "// API is the interface implemented by *" + api.Name() + ", comprising all of its\n"
+	content += "// API methods. Code that depends on API rather than *" + api.Name() + " can be tested\n"
+	content += "// against a fake implementation instead of a live service.\n"
+	content += "type API interface {\n"
+	for _, line := range strings.Split(methods, "\n") {
+		if strings.HasPrefix(line, prefix) {
+			content += "\t" + strings.TrimSuffix(strings.TrimPrefix(line, prefix), " {") + "\n"
+		}
+	}
+	content += "}\n"
+	content += "\n"
+	content += "var _ API = (*" + api.Name() + ")(nil)\n"
+	content += "\n"
+	return content
+}
+
+// generateIterators returns an XXXIterator type for each type of item that
+// a paginated API method lists (see APIEntry.pagination)
+func (api *API) generateIterators() string {
+	content := ""
+	generated := map[string]bool{}
+	for _, entry := range api.Entries {
+		_, itemType, paginated := entry.pagination()
+		if !paginated || generated[itemType] {
+			continue
+		}
+		generated[itemType] = true
+		iterator := iteratorName(itemType)
+		content += "// " + iterator + " iterates over the " + itemType + " items of all pages of\n"
+		content += "// a paginated API method, fetching each page as it is needed. See\n"
+		content += "// tcclient.Pager for details.\n"
+		content += "type " + iterator + " struct {\n"
+		content += "\t*tcclient.Pager\n"
+		content += "\titems []" + itemType + "\n"
+		content += "}\n"
+		content += "\n"
+		content += "// Item returns the current item. It may only be called after Next has\n"
+		content += "// returned true.\n"
+		content += "func (it *" + iterator + ") Item() " + itemType + " {\n"
+		content += "\treturn it.items[it.Index()]\n"
+		content += "}\n"
+		content += "\n"
+		content += "// New" + iterator + " returns " + text.IndefiniteArticle(iterator) + " " + iterator + " which fetches pages of\n"
+		content += "// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of\n"
+		content += "// the arguments and results of fetch, which returns the items of the page\n"
+		content += "// rather than their number. Fakes of API can use it to implement the XXXAll\n"
+		content += "// methods.\n"
+		content += "func New" + iterator + "(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]" + itemType + ", string, error)) *" + iterator + " {\n"
+		content += "\tit := new(" + iterator + ")\n"
+		content += "\tit.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {\n"
+		content += "\t\titems, next, err := fetch(ctx, continuationToken, limit)\n"
+		content += "\t\tif err != nil {\n"
+		content += "\t\t\treturn 0, \"\", err\n"
+		content += "\t\t}\n"
+		content += "\t\tit.items = items\n"
+		content += "\t\treturn len(items), next, nil\n"
+		content += "\t})\n"
+		content += "\treturn it\n"
+		content += "}\n"
+		content += "\n"
+	}
+	return content
+}
+
+// iteratorName returns the name of the generated iterator type for items of
+// Go type itemType, e.g. "ArtifactIterator" or "StringIterator"
+func iteratorName(itemType string) string {
+	return strings.ToUpper(itemType[:1]) + itemType[1:] + "Iterator"
+}
+
+func (api *API) generateRequiredScopes() string {
+	content := "// RequiredScopes holds the scope expression templates of the API methods\n"
+	content += "// that require scopes, keyed by API method name as reported by\n"
+	content += "// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of\n"
+	content += "// scopes will authorize a call.\n"
+	entries := ""
+	for _, entry := range api.Entries {
+		if entry.Scopes.Type != "" {
+			entries += "\t\"" + entry.Name + "\": " + entry.Scopes.goLiteral() + ",\n"
+		}
+	}
+	if entries != "" {
+		entries = "\n" + entries
+	}
+	content += "var RequiredScopes = map[string]scopes.Template{" + entries + "}\n"
 	return content
 }
 
@@ -280,9 +401,125 @@ func (entry *APIEntry) generateAPICode(apiName string) string {
 	if strings.ToUpper(entry.Method) == "GET" {
 		content += entry.generateSignedURLMethod(apiName)
 	}
+	content += entry.generateIteratorMethod(apiName)
 	return content
 }
 
+// pagination reports whether entry is a paginated API method, i.e. it
+// accepts a continuationToken query string parameter and its response
+// contains a continuationToken and exactly one array of items. If so, it
+// returns the Go name of the response field holding the items, and the Go
+// type of the items.
+func (entry *APIEntry) pagination() (field, itemType string, paginated bool) {
+	if entry.OutputURL == "" || !stringInSlice("continuationToken", entry.Query) {
+		return "", "", false
+	}
+	output := entry.Parent.apiDef.schemas.SubSchema(entry.OutputURL)
+	if output.Properties == nil || output.Properties.Properties["continuationToken"] == nil {
+		return "", "", false
+	}
+	for _, name := range output.Properties.SortedPropertyNames {
+		property := output.Properties.Properties[name]
+		if property.Type == nil || *property.Type != "array" || property.Items == nil {
+			continue
+		}
+		if field != "" {
+			// more than one array, so unclear which holds the results
+			return "", "", false
+		}
+		field = text.GoIdentifierFrom(name, true, map[string]bool{})
+		itemType = goItemType(property.Items)
+	}
+	return field, itemType, field != "" && itemType != ""
+}
+
+func stringInSlice(s string, slice []string) bool {
+	for _, x := range slice {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// goItemType returns the Go type that jsonschema2go generates for the items
+// of an array
+func goItemType(items *jsonschema2go.JsonSubSchema) string {
+	if items.RefSubSchema != nil {
+		items = items.RefSubSchema
+	}
+	if items.Type != nil {
+		switch *items.Type {
+		case "string":
+			return "string"
+		case "integer":
+			return "int64"
+		case "number":
+			return "float64"
+		case "boolean":
+			return "bool"
+		}
+	}
+	return items.TypeName
+}
+
+// generateIteratorMethod returns an XXXAll method for a paginated API
+// method, which returns an iterator over the items of all pages
+func (entry *APIEntry) generateIteratorMethod(apiName string) string {
+	field, itemType, paginated := entry.pagination()
+	if !paginated {
+		return ""
+	}
+	varName := entry.Parent.apiDef.ExampleVarName
+	iterator := iteratorName(itemType)
+
+	// the XXXAll method takes the same arguments as the API method, apart
+	// from the pagination query string parameters
+	params := append([]string{}, entry.Args...)
+	sort.Strings(entry.Query)
+	for _, q := range entry.Query {
+		if q != "continuationToken" && q != "limit" {
+			params = append(params, q)
+		}
+	}
+	inputParams := "ctx context.Context"
+	if len(params) > 0 {
+		inputParams += ", " + strings.Join(params, ", ") + " string"
+	}
+
+	content := "// " + entry.MethodName + "All returns an iterator over the " + itemType + " items of all\n"
+	content += "// pages of " + entry.MethodName + " results, fetching each page as it is needed, so\n"
+	content += "// that callers need not follow continuation tokens themselves. The\n"
+	content += "// iteration stops early if ctx is cancelled; always check Err once Next\n"
+	content += "// has returned false.\n"
+	content += "//\n"
+	content += fmt.Sprintf("// See %v for more details.\n", entry.MethodName)
+	content += "func (" + varName + " *" + entry.Parent.Name() + ") " + entry.MethodName + "All(" + inputParams + ") *" + iterator + " {\n"
+	content += "\treturn New" + iterator + "(ctx, func(ctx context.Context, continuationToken, limit string) ([]" + itemType + ", string, error) {\n"
+	content += "\t\tpage, err := " + varName + "." + entry.MethodName + "WithContext(ctx, " + entry.getCallArgs() + ")\n"
+	content += "\t\tif err != nil {\n"
+	content += "\t\t\treturn nil, \"\", err\n"
+	content += "\t\t}\n"
+	content += "\t\treturn page." + field + ", page.ContinuationToken, nil\n"
+	content += "\t})\n"
+	content += "}\n"
+	content += "\n"
+	return content
+}
+
+// getCallArgs returns the comma separated argument names that should be
+// passed when one generated method delegates to another, e.g. "taskId,
+// payload".
+func (entry *APIEntry) getCallArgs() string {
+	args := append([]string{}, entry.Args...)
+	sort.Strings(entry.Query)
+	args = append(args, entry.Query...)
+	if entry.InputURL != "" {
+		args = append(args, "payload")
+	}
+	return strings.Join(args, ", ")
+}
+
 func (entry *APIEntry) getInputParamsAndQueryStringCode() (inputParams, queryCode, queryExpr string) {
 	inputArgs := append([]string{}, entry.Args...)
 
@@ -339,15 +576,36 @@ func (entry *APIEntry) generateDirectMethod(apiName string) string {
 		responseType = "(*" + entry.Parent.apiDef.schemas.SubSchema(entry.OutputURL).TypeName + ", error)"
 	}
 
+	callArgs := entry.Parent.apiDef.ExampleVarName + ".Context"
+	if args := entry.getCallArgs(); args != "" {
+		callArgs += ", " + args
+	}
+
 	content := comment
 	content += "func (" + entry.Parent.apiDef.ExampleVarName + " *" + entry.Parent.Name() + ") " + entry.MethodName + "(" + inputParams + ") " + responseType + " {\n"
+	content += "\treturn " + entry.Parent.apiDef.ExampleVarName + "." + entry.MethodName + "WithContext(" + callArgs + ")\n"
+	content += "}\n"
+	content += "\n"
+
+	if inputParams == "" {
+		inputParams = "ctx context.Context"
+	} else {
+		inputParams = "ctx context.Context, " + inputParams
+	}
+	content += "// " + entry.MethodName + "WithContext is the same as " + entry.MethodName + ", except that the\n"
+	content += "// request is bound to ctx rather than to " + entry.Parent.apiDef.ExampleVarName + ".Context.\n"
+	content += "//\n"
+	content += fmt.Sprintf("// See %v for more details.\n", entry.MethodName)
+	content += "func (" + entry.Parent.apiDef.ExampleVarName + " *" + entry.Parent.Name() + ") " + entry.MethodName + "WithContext(" + inputParams + ") " + responseType + " {\n"
+	// record the endpoint in the context, for the benefit of client middleware
+	endpointCtx := "tcclient.WithEndpoint(ctx, \"" + entry.Parent.ServiceName + "\", \"" + entry.Name + "\")"
 	content += queryCode
 	content += "\tcd := tcclient.Client(*" + entry.Parent.apiDef.ExampleVarName + ")\n"
 	if entry.OutputURL != "" {
-		content += "\tresponseObject, _, err := (&cd).APICall(" + apiArgsPayload + ", \"" + strings.ToUpper(entry.Method) + "\", \"" + strings.Replace(strings.Replace(entry.Route, "<", "\" + url.QueryEscape(", -1), ">", ") + \"", -1) + "\", new(" + entry.Parent.apiDef.schemas.SubSchema(entry.OutputURL).TypeName + "), " + queryExpr + ")\n"
+		content += "\tresponseObject, _, err := (&cd).APICallWithContext(" + endpointCtx + ", " + apiArgsPayload + ", \"" + strings.ToUpper(entry.Method) + "\", \"" + strings.Replace(strings.Replace(entry.Route, "<", "\" + url.QueryEscape(", -1), ">", ") + \"", -1) + "\", new(" + entry.Parent.apiDef.schemas.SubSchema(entry.OutputURL).TypeName + "), " + queryExpr + ")\n"
 		content += "\treturn responseObject.(*" + entry.Parent.apiDef.schemas.SubSchema(entry.OutputURL).TypeName + "), err\n"
 	} else {
-		content += "\t_, _, err := (&cd).APICall(" + apiArgsPayload + ", \"" + strings.ToUpper(entry.Method) + "\", \"" + strings.Replace(strings.Replace(entry.Route, "<", "\" + url.QueryEscape(", -1), ">", ") + \"", -1) + "\", nil, " + queryExpr + ")\n"
+		content += "\t_, _, err := (&cd).APICallWithContext(" + endpointCtx + ", " + apiArgsPayload + ", \"" + strings.ToUpper(entry.Method) + "\", \"" + strings.Replace(strings.Replace(entry.Route, "<", "\" + url.QueryEscape(", -1), ">", ") + \"", -1) + "\", nil, " + queryExpr + ")\n"
 		content += "\treturn err\n"
 	}
 	content += "}\n"
@@ -462,6 +720,44 @@ func (rs *RequiredScope) String() string {
 	return string(*rs)
 }
 
+// goLiteral returns go source code for the scopes.Template equivalent to
+// scopes. AllOf and AnyOf expressions with a single subexpression are
+// replaced by the subexpression.
+func (scopes *ScopeExpressionTemplate) goLiteral() string {
+	switch scopes.Type {
+	case "AllOf":
+		if len(scopes.AllOf.AllOf) == 1 {
+			return scopes.AllOf.AllOf[0].goLiteral()
+		}
+		return "scopes.AllOf{\n" + goLiterals(scopes.AllOf.AllOf) + "}"
+	case "AnyOf":
+		if len(scopes.AnyOf.AnyOf) == 1 {
+			return scopes.AnyOf.AnyOf[0].goLiteral()
+		}
+		return "scopes.AnyOf{\n" + goLiterals(scopes.AnyOf.AnyOf) + "}"
+	case "ForEachIn":
+		return fmt.Sprintf("scopes.ForEachIn{For: %q, In: %q, Each: %q}", scopes.ForEachIn.For, scopes.ForEachIn.In, scopes.ForEachIn.Each)
+	case "IfThen":
+		content := fmt.Sprintf("scopes.IfThen{\nIf: %q,\nThen: %v,\n", scopes.IfThen.If, scopes.IfThen.Then.goLiteral())
+		if scopes.IfThen.Else.Type != "" {
+			content += "Else: " + scopes.IfThen.Else.goLiteral() + ",\n"
+		}
+		return content + "}"
+	case "RequiredScope":
+		return fmt.Sprintf("scopes.RequiredScope(%q)", string(*scopes.RequiredScope))
+	default:
+		panic(fmt.Sprintf("Internal error - did not recognise scope form '%v'", scopes.Type))
+	}
+}
+
+func goLiterals(exps []ScopeExpressionTemplate) string {
+	content := ""
+	for _, exp := range exps {
+		content += exp.goLiteral() + ",\n"
+	}
+	return content
+}
+
 // MarshalJSON calls json.RawMessage method of the same name. Required since
 // ScopeExpressionTemplate is of type json.RawMessage...
 func (this *ScopeExpressionTemplate) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,192 @@
+package model
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Bundle holds the manifest, references and schemas of a Taskcluster
+// deployment, read from a local directory or tarball, so that code can be
+// generated without network access. The files are laid out as they are
+// served under the root URL of the deployment, e.g.
+//
+//  references/manifest.json
+//  references/queue/v1/api.json
+//  schemas/queue/v1/task.json
+//  schemas/common/api-reference-v0.json
+//
+// A tarball may be gzipped, and may contain this layout inside a single top
+// level directory.
+//
+// Bundle implements http.RoundTripper, serving requests for URLs under the
+// root URL from the bundle, so that all references, schemas and $refs are
+// resolved against it. Requests for any other URL fail, so that generation
+// from a bundle is hermetic.
+type Bundle struct {
+	rootURL string
+	// file contents, keyed by url path relative to the root url, e.g.
+	// "references/manifest.json"
+	files map[string][]byte
+}
+
+// LoadBundle reads the bundle at bundlePath, a directory or a (gzipped)
+// tarball, for the deployment with the given root URL.
+func LoadBundle(bundlePath, rootURL string) (*Bundle, error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	var files map[string][]byte
+	if info.IsDir() {
+		files, err = readBundleDir(bundlePath)
+	} else {
+		files, err = readBundleTarball(bundlePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read bundle %v: %v", bundlePath, err)
+	}
+	files, err = stripBundlePrefix(files)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle %v: %v", bundlePath, err)
+	}
+	return &Bundle{
+		rootURL: strings.TrimRight(rootURL, "/"),
+		files:   files,
+	}, nil
+}
+
+func readBundleDir(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)], err = ioutil.ReadFile(file)
+		return err
+	})
+	return files, err
+}
+
+func readBundleTarball(file string) (map[string][]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		files[path.Clean(strings.TrimPrefix(header.Name, "./"))], err = ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// stripBundlePrefix removes any top level directory that contains the
+// bundle layout from the paths of files
+func stripBundlePrefix(files map[string][]byte) (map[string][]byte, error) {
+	manifests := []string{}
+	for name := range files {
+		if name == "references/manifest.json" || strings.HasSuffix(name, "/references/manifest.json") {
+			manifests = append(manifests, name)
+		}
+	}
+	if len(manifests) != 1 {
+		sort.Strings(manifests)
+		return nil, fmt.Errorf("expected exactly one references/manifest.json, but found %q", manifests)
+	}
+	prefix := strings.TrimSuffix(manifests[0], "references/manifest.json")
+	stripped := map[string][]byte{}
+	for name, content := range files {
+		if strings.HasPrefix(name, prefix) {
+			stripped[strings.TrimPrefix(name, prefix)] = content
+		}
+	}
+	return stripped, nil
+}
+
+// relativePath returns the path of the file in the bundle that u refers to,
+// and whether u is under the root URL of the bundle
+func (bundle *Bundle) relativePath(u *url.URL) (string, bool) {
+	if bundle.rootURL == "https://taskcluster.net" {
+		// the legacy deployment serves references and schemas from their
+		// own hosts
+		switch u.Host {
+		case "references.taskcluster.net":
+			return "references" + u.Path, true
+		case "schemas.taskcluster.net":
+			return "schemas" + u.Path, true
+		}
+		return "", false
+	}
+	root := bundle.rootURL + "/"
+	noQuery := *u
+	noQuery.RawQuery = ""
+	noQuery.Fragment = ""
+	if !strings.HasPrefix(noQuery.String(), root) {
+		return "", false
+	}
+	return strings.TrimPrefix(noQuery.String(), root), true
+}
+
+// RoundTrip serves GET requests for URLs under the root URL of the bundle.
+func (bundle *Bundle) RoundTrip(req *http.Request) (*http.Response, error) {
+	rel, ok := bundle.relativePath(req.URL)
+	if !ok || req.Method != "GET" {
+		return nil, fmt.Errorf("%v %v is not served by the reference bundle for %v", req.Method, req.URL, bundle.rootURL)
+	}
+	response := &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Request:    req,
+	}
+	content, found := bundle.files[rel]
+	if !found {
+		response.Status = "404 Not Found"
+		response.StatusCode = http.StatusNotFound
+		content = []byte(rel + " not found in reference bundle\n")
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+	response.ContentLength = int64(len(content))
+	return response, nil
+}
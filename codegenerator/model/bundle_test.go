@@ -0,0 +1,228 @@
+package model
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var bundleFiles = map[string]string{
+	"references/manifest.json":     `{"references": []}`,
+	"references/queue/v1/api.json": `{"serviceName": "queue"}`,
+	"schemas/queue/v1/task.json":   `{"type": "object"}`,
+}
+
+// writeBundleDir writes files to a new directory under dir, and returns its
+// path
+func writeBundleDir(t *testing.T, dir string, files map[string]string) string {
+	bundleDir := filepath.Join(dir, "bundle")
+	for name, content := range files {
+		file := filepath.Join(bundleDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bundleDir
+}
+
+// writeBundleTarball writes files to a new gzipped tarball under dir, with
+// the given prefix prepended to their names, and returns its path
+func writeBundleTarball(t *testing.T, dir, prefix string, files map[string]string) string {
+	tarball := filepath.Join(dir, "bundle.tar.gz")
+	f, err := os.Create(tarball)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     prefix + name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return tarball
+}
+
+func TestBundle(t *testing.T) {
+	duplicateManifests := map[string]string{
+		"a/references/manifest.json": `{}`,
+		"b/references/manifest.json": `{}`,
+	}
+	testCases := []struct {
+		name    string
+		rootURL string
+		write   func(t *testing.T, dir string) string
+		// expected error of LoadBundle, if any
+		loadErr string
+		// expected status code of a GET request for each url, or 0 if the
+		// request should fail
+		requests map[string]int
+	}{
+		{
+			name:    "directory",
+			rootURL: "https://tc.example.com/",
+			write: func(t *testing.T, dir string) string {
+				return writeBundleDir(t, dir, bundleFiles)
+			},
+			requests: map[string]int{
+				"https://tc.example.com/references/manifest.json":          200,
+				"https://tc.example.com/schemas/queue/v1/task.json#":       200,
+				"https://tc.example.com/references/queue/v1/api.json?x=y":  200,
+				"https://tc.example.com/schemas/queue/v1/missing.json":     404,
+				"https://other.example.com/references/manifest.json":       0,
+				"https://tc.example.com.evil.com/references/manifest.json": 0,
+			},
+		},
+		{
+			name:    "gzipped tarball with top level directory",
+			rootURL: "https://tc.example.com",
+			write: func(t *testing.T, dir string) string {
+				return writeBundleTarball(t, dir, "./taskcluster-references/", bundleFiles)
+			},
+			requests: map[string]int{
+				"https://tc.example.com/references/queue/v1/api.json": 200,
+				"https://tc.example.com/schemas/queue/v1/task.json":   200,
+				"https://tc.example.com/references/auth/v1/api.json":  404,
+				"https://taskcluster.net/references/manifest.json":    0,
+			},
+		},
+		{
+			name:    "legacy root url",
+			rootURL: "https://taskcluster.net",
+			write: func(t *testing.T, dir string) string {
+				return writeBundleDir(t, dir, bundleFiles)
+			},
+			requests: map[string]int{
+				"https://references.taskcluster.net/manifest.json":      200,
+				"https://references.taskcluster.net/queue/v1/api.json":  200,
+				"https://schemas.taskcluster.net/queue/v1/task.json":    200,
+				"https://schemas.taskcluster.net/queue/v1/missing.json": 404,
+				"https://taskcluster.net/references/manifest.json":      0,
+				"https://queue.taskcluster.net/v1/ping":                 0,
+			},
+		},
+		{
+			name:    "duplicate manifests in directory",
+			rootURL: "https://tc.example.com",
+			write: func(t *testing.T, dir string) string {
+				return writeBundleDir(t, dir, duplicateManifests)
+			},
+			loadErr: `expected exactly one references/manifest.json, but found ["a/references/manifest.json" "b/references/manifest.json"]`,
+		},
+		{
+			name:    "duplicate manifests in tarball",
+			rootURL: "https://tc.example.com",
+			write: func(t *testing.T, dir string) string {
+				return writeBundleTarball(t, dir, "", duplicateManifests)
+			},
+			loadErr: "expected exactly one references/manifest.json",
+		},
+		{
+			name:    "no manifest",
+			rootURL: "https://tc.example.com",
+			write: func(t *testing.T, dir string) string {
+				return writeBundleDir(t, dir, map[string]string{"schemas/queue/v1/task.json": `{}`})
+			},
+			loadErr: "expected exactly one references/manifest.json, but found []",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "bundle")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			bundle, err := LoadBundle(tc.write(t, dir), tc.rootURL)
+			if tc.loadErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.loadErr) {
+					t.Fatalf("Expected error containing %q, but got %v", tc.loadErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for u, status := range tc.requests {
+				req, err := http.NewRequest("GET", u, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				res, err := bundle.RoundTrip(req)
+				if status == 0 {
+					if err == nil {
+						t.Errorf("Expected an error requesting %v, but got %v", u, res.Status)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("Unexpected error requesting %v: %v", u, err)
+					continue
+				}
+				body, err := ioutil.ReadAll(res.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.StatusCode != status {
+					t.Errorf("Expected status %v requesting %v, but got %v: %s", status, u, res.StatusCode, body)
+				}
+			}
+		})
+	}
+}
+
+func TestBundleServesFileContents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bundle, err := LoadBundle(writeBundleTarball(t, dir, "refs/", bundleFiles), "https://tc.example.com")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client := &http.Client{Transport: bundle}
+	res, err := client.Get("https://tc.example.com/schemas/queue/v1/task.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != bundleFiles["schemas/queue/v1/task.json"] {
+		t.Errorf("Expected task.json from bundle, but got %q", body)
+	}
+	req, err := http.NewRequest("POST", "https://tc.example.com/references/manifest.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundle.RoundTrip(req); err == nil {
+		t.Error("Expected an error for a POST request")
+	}
+}
@@ -0,0 +1,355 @@
+package model
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/taskcluster/jsonschema2go"
+	"github.com/taskcluster/jsonschema2go/text"
+)
+
+// propertyEnums holds the enumerated values of string properties, and of
+// array properties whose items are strings, keyed by the name of the
+// generated struct type and then by property name
+type propertyEnums map[string]map[string]*enumValues
+
+// enumValues are the possible values of a string property, or of the items of
+// an array property
+type enumValues struct {
+	values []string
+	array  bool
+	// whether the property is part of a request payload
+	request bool
+}
+
+// schemaEnums returns the enumerated properties of the schemas that types
+// were generated for. Properties of the schemas of requestURLs (and of their
+// subschemas) are marked as part of a request payload.
+func schemaEnums(schemas *jsonschema2go.SchemaSet, requestURLs []string) propertyEnums {
+	enums := propertyEnums{}
+	visited := map[*jsonschema2go.JsonSubSchema]bool{}
+	for _, url := range requestURLs {
+		enums.add(schemas.SubSchema(url), true, visited)
+	}
+	for _, url := range schemas.SortedSanitizedURLs() {
+		enums.add(schemas.SubSchema(url), false, visited)
+	}
+	return enums
+}
+
+// requestSchemaURLs returns the URLs of the schemas of the request payloads
+// of apiDef, which are empty unless it is an HTTP API
+func (apiDef *APIDefinition) requestSchemaURLs() []string {
+	urls := []string{}
+	if api, ok := apiDef.Data.(*API); ok {
+		for _, entry := range api.Entries {
+			if entry.InputURL != "" {
+				urls = append(urls, entry.InputURL)
+			}
+		}
+	}
+	return urls
+}
+
+// add adds the enumerated properties of schema and its subschemas to enums,
+// marking them as part of a request payload if request is true
+func (enums propertyEnums) add(schema *jsonschema2go.JsonSubSchema, request bool, visited map[*jsonschema2go.JsonSubSchema]bool) {
+	if schema == nil || visited[schema] {
+		return
+	}
+	visited[schema] = true
+	subschemas := jsonschema2go.Items{schema.Items, schema.RefSubSchema}
+	subschemas = append(subschemas, schema.AllOf...)
+	subschemas = append(subschemas, schema.AnyOf...)
+	subschemas = append(subschemas, schema.OneOf...)
+	if schema.Properties != nil {
+		for _, name := range schema.Properties.SortedPropertyNames {
+			property := schema.Properties.Properties[name]
+			subschemas = append(subschemas, property)
+			if schema.TypeName == "" {
+				continue
+			}
+			var values *enumValues
+			if enum := stringEnum(property); enum != nil {
+				values = &enumValues{values: enum, request: request}
+			} else if property = resolveRef(property); property.Items != nil {
+				if enum := stringEnum(property.Items); enum != nil {
+					values = &enumValues{values: enum, array: true, request: request}
+				}
+			}
+			if values == nil {
+				continue
+			}
+			if enums[schema.TypeName] == nil {
+				enums[schema.TypeName] = map[string]*enumValues{}
+			}
+			enums[schema.TypeName][name] = values
+		}
+	}
+	for _, subschema := range subschemas {
+		enums.add(subschema, request, visited)
+	}
+}
+
+// resolveRef returns the schema that schema refers to with $ref, if any
+func resolveRef(schema *jsonschema2go.JsonSubSchema) *jsonschema2go.JsonSubSchema {
+	for schema.RefSubSchema != nil {
+		schema = schema.RefSubSchema
+	}
+	return schema
+}
+
+// stringEnum returns the values of the enum of schema, or nil if it has no
+// enum or not all of its values are strings
+func stringEnum(schema *jsonschema2go.JsonSubSchema) []string {
+	schema = resolveRef(schema)
+	if len(schema.Enum) == 0 {
+		return nil
+	}
+	values := make([]string, len(schema.Enum))
+	for i, value := range schema.Enum {
+		s, ok := value.(string)
+		if !ok {
+			return nil
+		}
+		values[i] = s
+	}
+	return values
+}
+
+// enumProperty is a string (or []string) field of a generated struct whose
+// schema restricts it (or its items) to an enumerated list of values
+type enumProperty struct {
+	structName string
+	fieldName  string
+	jsonName   string
+	values     []string
+	array      bool
+	request    bool
+	// name of the generated type
+	typeName string
+	// byte offsets of the field type (`string`, or the `string` of
+	// `[]string`) in the source
+	typeStart int
+	typeEnd   int
+}
+
+// enumType is a named string type generated for one or more enumProperty
+type enumType struct {
+	name       string
+	values     []string
+	properties []*enumProperty
+	// whether any of the properties is part of a request payload
+	request bool
+}
+
+// typedEnums replaces the `string` type of struct fields whose schema has an
+// enum (and the `string` item type of []string fields whose items have an
+// enum) with a named string type, and declares a constant for each of the
+// possible values and a Valid method. Types of properties of request
+// payloads also get a MarshalJSON method that rejects invalid values. This
+// way a typo such as "worker-shutdwon" in a request is caught at compile or
+// marshal time, rather than as a 400 from the service, while types that only
+// appear in responses and messages marshal any value, so that values added
+// to the service later survive a round trip.
+//
+// Properties with the same field name and the same possible values share a
+// type named after the field (e.g. Priority), or for array items, after the
+// field with an Item suffix (e.g. EventsItem). If the name is ambiguous or
+// already declared, the type is qualified with the struct name (e.g.
+// ArtifactStorageType).
+func typedEnums(source []byte, enums propertyEnums) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "types.go", source, parser.ParseComments)
+	exitOnFail(err)
+
+	declared := map[string]bool{}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				declared[d.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					declared[s.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						declared[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	properties := []*enumProperty{}
+	for _, decl := range file.Decls {
+		d, ok := decl.(*ast.GenDecl)
+		if !ok || d.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range d.Specs {
+			s := spec.(*ast.TypeSpec)
+			st, ok := s.Type.(*ast.StructType)
+			if !ok || enums[s.Name.Name] == nil {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				if len(field.Names) != 1 || field.Tag == nil {
+					continue
+				}
+				tag, err := strconv.Unquote(field.Tag.Value)
+				exitOnFail(err)
+				jsonName := strings.Split(reflect.StructTag(tag).Get("json"), ",")[0]
+				values := enums[s.Name.Name][jsonName]
+				if values == nil {
+					continue
+				}
+				fieldType := field.Type
+				if values.array {
+					array, ok := fieldType.(*ast.ArrayType)
+					if !ok || array.Len != nil {
+						continue
+					}
+					fieldType = array.Elt
+				}
+				if ident, ok := fieldType.(*ast.Ident); !ok || ident.Name != "string" {
+					continue
+				}
+				properties = append(properties, &enumProperty{
+					structName: s.Name.Name,
+					fieldName:  field.Names[0].Name,
+					jsonName:   jsonName,
+					values:     values.values,
+					array:      values.array,
+					request:    values.request,
+					typeStart:  fset.Position(fieldType.Pos()).Offset,
+					typeEnd:    fset.Position(fieldType.End()).Offset,
+				})
+			}
+		}
+	}
+	if len(properties) == 0 {
+		return source
+	}
+
+	// the distinct value lists of each field name
+	valueLists := map[string]map[string]bool{}
+	for _, p := range properties {
+		if valueLists[p.baseName()] == nil {
+			valueLists[p.baseName()] = map[string]bool{}
+		}
+		valueLists[p.baseName()][strings.Join(p.values, "\n")] = true
+	}
+	types := []*enumType{}
+	typesByName := map[string]*enumType{}
+	for _, p := range properties {
+		name := p.baseName()
+		if len(valueLists[name]) > 1 || declared[name] && typesByName[name] == nil {
+			name = p.structName + name
+		}
+		t := typesByName[name]
+		if t == nil {
+			name = text.GoIdentifierFrom(name, true, declared)
+			t = &enumType{
+				name:   name,
+				values: p.values,
+			}
+			typesByName[name] = t
+			types = append(types, t)
+		}
+		p.typeName = t.name
+		t.properties = append(t.properties, p)
+		t.request = t.request || p.request
+	}
+
+	// replace field types, starting from the end of the source so that
+	// offsets remain valid
+	sort.Slice(properties, func(i, j int) bool {
+		return properties[i].typeStart > properties[j].typeStart
+	})
+	result := source
+	for _, p := range properties {
+		result = append(result[:p.typeStart:p.typeStart], append([]byte(p.typeName), result[p.typeEnd:]...)...)
+	}
+
+	buf := bytes.NewBuffer(result)
+	for _, t := range types {
+		buf.WriteString(t.generate(declared))
+	}
+	return buf.Bytes()
+}
+
+// baseName returns the name of the type of p, before any qualification with
+// the struct name
+func (p *enumProperty) baseName() string {
+	if p.array {
+		return p.fieldName + "Item"
+	}
+	return p.fieldName
+}
+
+func (t *enumType) generate(declared map[string]bool) string {
+	owners := []string{}
+	for _, p := range t.properties {
+		if !stringInSlice(p.structName, owners) {
+			owners = append(owners, p.structName)
+		}
+	}
+	sort.Strings(owners)
+	ownerList := owners[0]
+	if len(owners) > 1 {
+		ownerList = strings.Join(owners[:len(owners)-1], ", ") + " and " + owners[len(owners)-1]
+	}
+	constants := make([]string, len(t.values))
+	for i, value := range t.values {
+		constants[i] = text.GoIdentifierFrom(t.name+" "+value, true, declared)
+	}
+
+	content := "\n"
+	of := "the `" + t.properties[0].jsonName + "` property of "
+	if t.properties[0].array {
+		of = "the items of " + of
+	}
+	content += "// " + t.name + " is the type of " + of + ownerList + ".\n"
+	content += "// Its possible values are declared as constants.\n"
+	content += "type " + t.name + " string\n"
+	content += "\n"
+	content += "// Possible values of " + t.name + "\n"
+	content += "const (\n"
+	for i, value := range t.values {
+		content += "\t" + constants[i] + " " + t.name + " = " + strconv.Quote(value) + "\n"
+	}
+	content += ")\n"
+	content += "\n"
+	content += "// Valid returns true if this is one of the possible values of " + t.name + ".\n"
+	content += "func (this " + t.name + ") Valid() bool {\n"
+	content += "\tswitch this {\n"
+	content += "\tcase " + strings.Join(constants, ", ") + ":\n"
+	content += "\t\treturn true\n"
+	content += "\t}\n"
+	content += "\treturn false\n"
+	content += "}\n"
+	if !t.request {
+		return content
+	}
+	content += "\n"
+	content += "// MarshalJSON returns an error unless this is a valid " + t.name + ".\n"
+	content += "// Values are not checked when unmarshaling, so that values added to the\n"
+	content += "// service after this package was generated are still accepted.\n"
+	content += "func (this " + t.name + ") MarshalJSON() ([]byte, error) {\n"
+	content += "\tif !this.Valid() {\n"
+	content += "\t\treturn nil, fmt.Errorf(\"invalid " + t.name + " %q\", string(this))\n"
+	content += "\t}\n"
+	content += "\treturn json.Marshal(string(this))\n"
+	content += "}\n"
+	return content
+}
@@ -0,0 +1,225 @@
+package model
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/taskcluster/jsonschema2go"
+)
+
+const enumsSource = `package example
+
+type (
+	Artifact struct {
+		Name        string ` + "`json:\"name\"`" + `
+		StorageType string ` + "`json:\"storageType\"`" + `
+	}
+
+	Request struct {
+		Events      []string ` + "`json:\"events,omitempty\"`" + `
+		Priority    string   ` + "`json:\"priority\"`" + `
+		Retries     int64    ` + "`json:\"retries\"`" + `
+		Status      string   ` + "`json:\"status\"`" + `
+		StorageType string   ` + "`json:\"storageType\"`" + `
+	}
+
+	Task struct {
+		Priority string ` + "`json:\"priority\"`" + `
+	}
+
+	Status struct {
+		State string ` + "`json:\"state\"`" + `
+	}
+)
+`
+
+// objectSchema returns an object schema for the struct type typeName, with
+// the given properties
+func objectSchema(typeName string, properties map[string]*jsonschema2go.JsonSubSchema) *jsonschema2go.JsonSubSchema {
+	names := []string{}
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &jsonschema2go.JsonSubSchema{
+		TypeName: typeName,
+		Properties: &jsonschema2go.Properties{
+			Properties:          properties,
+			SortedPropertyNames: names,
+		},
+	}
+}
+
+func enumSchema(values ...interface{}) *jsonschema2go.JsonSubSchema {
+	return &jsonschema2go.JsonSubSchema{Enum: values}
+}
+
+func TestTypedEnums(t *testing.T) {
+	priority := enumSchema("high", "low")
+	request := objectSchema("Request", map[string]*jsonschema2go.JsonSubSchema{
+		"events":      {Items: enumSchema("push", "pull_request")},
+		"priority":    priority,
+		"retries":     enumSchema(1.0, 5.0),
+		"status":      enumSchema("ok", "failed"),
+		"storageType": enumSchema("s3", "reference"),
+	})
+	root := &jsonschema2go.JsonSubSchema{
+		OneOf: jsonschema2go.Items{
+			objectSchema("Artifact", map[string]*jsonschema2go.JsonSubSchema{
+				"name":        {},
+				"storageType": enumSchema("s3", "azure"),
+			}),
+			request,
+			objectSchema("Task", map[string]*jsonschema2go.JsonSubSchema{
+				"priority": {RefSubSchema: priority},
+			}),
+			objectSchema("Status", map[string]*jsonschema2go.JsonSubSchema{
+				"state": enumSchema("ok", "failed"),
+			}),
+		},
+	}
+	enums := propertyEnums{}
+	visited := map[*jsonschema2go.JsonSubSchema]bool{}
+	enums.add(request, true, visited)
+	enums.add(root, false, visited)
+
+	generated, err := format.Source(typedEnums([]byte(enumsSource), enums))
+	if err != nil {
+		t.Fatalf("Generated code is invalid: %v", err)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "types.go", generated, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fieldTypes := map[string]string{}
+	constants := map[string]string{}
+	validCases := map[string][]string{}
+	marshalers := map[string]string{}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if st, ok := s.Type.(*ast.StructType); ok {
+						for _, field := range st.Fields.List {
+							fieldTypes[s.Name.Name+"."+field.Names[0].Name] = types.ExprString(field.Type)
+						}
+					}
+				case *ast.ValueSpec:
+					constants[s.Names[0].Name] = types.ExprString(s.Type) + " = " + s.Values[0].(*ast.BasicLit).Value
+				}
+			}
+		case *ast.FuncDecl:
+			receiver := types.ExprString(d.Recv.List[0].Type)
+			switch d.Name.Name {
+			case "Valid":
+				for _, c := range d.Body.List[0].(*ast.SwitchStmt).Body.List[0].(*ast.CaseClause).List {
+					validCases[receiver] = append(validCases[receiver], types.ExprString(c))
+				}
+			case "MarshalJSON":
+				marshalers[receiver] = string(generated[fset.Position(d.Body.Pos()).Offset:fset.Position(d.Body.End()).Offset])
+			}
+		}
+	}
+
+	expectedFieldTypes := map[string]string{
+		"Artifact.Name":        "string",
+		"Artifact.StorageType": "ArtifactStorageType",
+		"Request.Events":       "[]EventsItem",
+		// shared with Task, since the values are the same
+		"Request.Priority": "Priority",
+		// not a string enum
+		"Request.Retries": "int64",
+		// qualified, since Status is already declared
+		"Request.Status": "RequestStatus",
+		// qualified, since the values differ from those of Artifact
+		"Request.StorageType": "RequestStorageType",
+		"Task.Priority":       "Priority",
+		"Status.State":        "State",
+	}
+	if !reflect.DeepEqual(fieldTypes, expectedFieldTypes) {
+		t.Errorf("Expected field types %v but got %v", expectedFieldTypes, fieldTypes)
+	}
+
+	expectedConstants := map[string]string{
+		"ArtifactStorageTypeS3":       `ArtifactStorageType = "s3"`,
+		"ArtifactStorageTypeAzure":    `ArtifactStorageType = "azure"`,
+		"EventsItemPush":              `EventsItem = "push"`,
+		"EventsItemPullRequest":       `EventsItem = "pull_request"`,
+		"PriorityHigh":                `Priority = "high"`,
+		"PriorityLow":                 `Priority = "low"`,
+		"RequestStatusOk":             `RequestStatus = "ok"`,
+		"RequestStatusFailed":         `RequestStatus = "failed"`,
+		"RequestStorageTypeS3":        `RequestStorageType = "s3"`,
+		"RequestStorageTypeReference": `RequestStorageType = "reference"`,
+		"StateOk":                     `State = "ok"`,
+		"StateFailed":                 `State = "failed"`,
+	}
+	if !reflect.DeepEqual(constants, expectedConstants) {
+		t.Errorf("Expected constants %v but got %v", expectedConstants, constants)
+	}
+
+	expectedValidCases := map[string][]string{}
+	for name, value := range expectedConstants {
+		typeName := strings.Split(value, " ")[0]
+		expectedValidCases[typeName] = append(expectedValidCases[typeName], name)
+	}
+	for typeName, cases := range expectedValidCases {
+		if got := validCases[typeName]; !sameStrings(got, cases) {
+			t.Errorf("Expected %v.Valid to accept exactly %v but got %v", typeName, cases, got)
+		}
+	}
+	if len(validCases) != len(expectedValidCases) {
+		t.Errorf("Expected Valid methods for exactly %v types, but got %v", len(expectedValidCases), validCases)
+	}
+
+	// only types of request properties (including Priority, which is shared
+	// with Task) reject invalid values; the others marshal any value
+	expectedMarshalers := []string{"EventsItem", "Priority", "RequestStatus", "RequestStorageType"}
+	for _, typeName := range expectedMarshalers {
+		marshaler, ok := marshalers[typeName]
+		if !ok {
+			t.Errorf("Expected %v to have a MarshalJSON method", typeName)
+			continue
+		}
+		if !strings.Contains(marshaler, "if !this.Valid()") || !strings.Contains(marshaler, `fmt.Errorf("invalid `+typeName+` %q", string(this))`) {
+			t.Errorf("Expected %v.MarshalJSON to reject invalid values, but got %v", typeName, marshaler)
+		}
+	}
+	if len(marshalers) != len(expectedMarshalers) {
+		t.Errorf("Expected MarshalJSON methods for exactly %v, but got %v", expectedMarshalers, marshalers)
+	}
+}
+
+func TestTypedEnumsUnchanged(t *testing.T) {
+	if got := string(typedEnums([]byte(enumsSource), propertyEnums{})); got != enumsSource {
+		t.Errorf("Expected source without enums to be unchanged, but got:\n%v", got)
+	}
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[string]int{}
+	for _, s := range a {
+		counts[s]++
+	}
+	for _, s := range b {
+		counts[s]--
+		if counts[s] < 0 {
+			return false
+		}
+	}
+	return true
+}
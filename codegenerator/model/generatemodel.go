@@ -7,6 +7,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"regexp"
 	"strconv"
@@ -29,7 +30,7 @@ go generate commands in the model package. See go generate --help and ../build.s
 this is used by the build process for this taskcluster-client-go go project.
 
   Usage:
-      generatemodel -o GO-OUTPUT-DIR -m MODEL-DATA-FILE
+      generatemodel -o GO-OUTPUT-DIR -m MODEL-DATA-FILE [-b BUNDLE]
       generatemodel --help
 
   Options:
@@ -39,9 +40,17 @@ this is used by the build process for this taskcluster-client-go go project.
                             parsed, and their dependencies have also been
                             processed, an overview of all the processed data
                             will be written to this file.
+    -b BUNDLE               Read the manifest/references/schemas from BUNDLE, a
+                            local directory or (optionally gzipped) tarball laid
+                            out as they are served under the root URL, instead
+                            of downloading them. Defaults to the value of the
+                            TASKCLUSTER_REFERENCE_BUNDLE environment variable,
+                            if set.
 
 Please note, you *must* set TASKCLUSTER_ROOT_URL to a valid taskcluster deployment to
-retrieve the manifest/references/schemas from.
+retrieve the manifest/references/schemas from. When generating from a bundle, no network
+access is needed, but TASKCLUSTER_ROOT_URL must still be set to the root URL of the
+deployment that the bundle describes.
 `
 )
 
@@ -72,6 +81,22 @@ func main() {
 		log.Fatal("No TASKCLUSTER_ROOT_URL/TASKCLUSTER_PROXY_URL environment variable found to download manifest/references/schemas from.")
 	}
 
+	bundlePath := os.Getenv("TASKCLUSTER_REFERENCE_BUNDLE")
+	if b, ok := arguments["-b"].(string); ok {
+		bundlePath = b
+	}
+	if bundlePath != "" {
+		log.Printf("Loading manifest/references/schemas from bundle %v...", bundlePath)
+		bundle, err := model.LoadBundle(bundlePath, rootURL)
+		if err != nil {
+			log.Fatalf("Error loading bundle: %v", err)
+		}
+		// all http requests of the code generator, including those made by
+		// jsonschema2go and gojsonschema to resolve $refs, are served from
+		// the bundle
+		http.DefaultTransport = bundle
+	}
+
 	// echo "https://taskcluster-staging.net/schemas/common/api-reference-v0.json
 	// https://taskcluster-staging.net/schemas/common/manifest-v3.json" | "${GOPATH}/bin/jsonschema2go" -o model | sed 's/^\([[:space:]]*\)API\(Entry struct\)/\1\2/' | sed 's/json\.RawMessage/ScopeExpressionTemplate/g' > codegenerator/model/types.go
 
@@ -121,6 +121,7 @@ func LoadAPIs(rootURL string) APIDefinitions {
 		fmt.Printf("Could not download api manifest from url: '%v'!\n", apiManifestURL)
 	}
 	exitOnFail(err)
+	exitOnFail(checkStatus(resp))
 	apiManifestDecoder := json.NewDecoder(resp.Body)
 	apiMan := TaskclusterServiceManifest{}
 	err = apiManifestDecoder.Decode(&apiMan)
@@ -130,6 +131,7 @@ func LoadAPIs(rootURL string) APIDefinitions {
 		var resp *http.Response
 		resp, err = http.Get(apiMan.References[i])
 		exitOnFail(err)
+		exitOnFail(checkStatus(resp))
 		defer resp.Body.Close()
 		apiDef := &APIDefinition{
 			URL: apiMan.References[i],
@@ -155,6 +157,14 @@ func LoadAPIs(rootURL string) APIDefinitions {
 	return APIDefinitions(apiDefs)
 }
 
+// checkStatus returns an error if resp is not a 200 response
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not retrieve %v: %v", resp.Request.URL, resp.Status)
+	}
+	return nil
+}
+
 func validateJSON(schemaURL, docURL string) {
 	schemaLoader := gojsonschema.NewReferenceLoader(schemaURL)
 	docLoader := gojsonschema.NewReferenceLoader(docURL)
@@ -230,7 +240,7 @@ func (apiDefs APIDefinitions) GenerateCode(goOutputDir, modelData string, downlo
 
 		apiDefs[i].schemas = result.SchemaSet
 		typesSourceFile := filepath.Join(apiDefs[i].PackagePath, "types.go")
-		FormatSourceAndSave(typesSourceFile, result.SourceCode)
+		FormatSourceAndSave(typesSourceFile, typedEnums(optionalTimes(result.SourceCode), schemaEnums(result.SchemaSet, apiDefs[i].requestSchemaURLs())))
 
 		fmt.Printf("Generating functions and methods for %s\n", job.Package)
 		content := `
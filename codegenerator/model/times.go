@@ -0,0 +1,52 @@
+package model
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// optionalTimes replaces the tcclient.Time type of optional struct fields
+// (those jsonschema2go tags with omitempty, since they are not required by
+// the schema) with *tcclient.Time. Since omitempty has no effect on struct
+// types, an unset optional time would otherwise be marshaled as null, which
+// services reject. With a pointer, an unset time is nil and is omitted.
+func optionalTimes(source []byte) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "types.go", source, parser.ParseComments)
+	exitOnFail(err)
+
+	offsets := []int{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		field, ok := node.(*ast.Field)
+		if !ok || field.Tag == nil {
+			return true
+		}
+		sel, ok := field.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Time" {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "tcclient" {
+			return true
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		exitOnFail(err)
+		options := strings.Split(reflect.StructTag(tag).Get("json"), ",")[1:]
+		if stringInSlice("omitempty", options) {
+			offsets = append(offsets, fset.Position(field.Type.Pos()).Offset)
+		}
+		return true
+	})
+
+	// insert from the end of the source so that offsets remain valid
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	result := source
+	for _, offset := range offsets {
+		result = append(result[:offset:offset], append([]byte("*"), result[offset:]...)...)
+	}
+	return result
+}
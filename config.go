@@ -0,0 +1,167 @@
+package tcclient
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Config represents a Taskcluster configuration file, which holds named
+// profiles, e.g.
+//
+//  defaultProfile: staging
+//  profiles:
+//    staging:
+//      rootUrl: https://stage.taskcluster.example.com
+//      clientId: me/staging
+//      accessToken: ...
+//    production:
+//      rootUrl: https://taskcluster.example.com
+//      clientId: me/production
+//      accessToken: ...
+//      authorizedScopes:
+//        - queue:get-artifact:*
+//
+// See LoadConfig for where the file is located.
+type Config struct {
+	// Profile used when no profile is specified, and TASKCLUSTER_PROFILE is
+	// not set. If empty, the profile named "default" is used.
+	DefaultProfile string `yaml:"defaultProfile"`
+	// Profiles by name
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile holds the settings for accessing a Taskcluster deployment.
+type Profile struct {
+	RootURL          string   `yaml:"rootUrl"`
+	ClientID         string   `yaml:"clientId"`
+	AccessToken      string   `yaml:"accessToken"`
+	Certificate      string   `yaml:"certificate"`
+	AuthorizedScopes []string `yaml:"authorizedScopes"`
+}
+
+// Credentials returns the credentials of the profile, or nil if the profile
+// has no client id.
+func (profile *Profile) Credentials() *Credentials {
+	if profile == nil || profile.ClientID == "" {
+		return nil
+	}
+	return &Credentials{
+		ClientID:         profile.ClientID,
+		AccessToken:      profile.AccessToken,
+		Certificate:      profile.Certificate,
+		AuthorizedScopes: profile.AuthorizedScopes,
+	}
+}
+
+// ConfigPath returns the location of the Taskcluster configuration file: the
+// value of environment variable TASKCLUSTER_CONFIG if set, otherwise
+// taskcluster/config.yml in $XDG_CONFIG_HOME (which defaults to ~/.config).
+func ConfigPath() string {
+	if path := os.Getenv("TASKCLUSTER_CONFIG"); path != "" {
+		return path
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			home = os.Getenv("USERPROFILE")
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "taskcluster", "config.yml")
+}
+
+// LoadConfig reads the Taskcluster configuration file at path. If path is
+// empty, ConfigPath() is used.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = ConfigPath()
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(Config)
+	err = yaml.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot parse Taskcluster config file %v: %v", path, err)
+	}
+	return config, nil
+}
+
+// Profile returns the named profile. If name is empty, the profile named by
+// environment variable TASKCLUSTER_PROFILE is returned, or if that is not set
+// either, config.DefaultProfile, or if that is also empty, the profile named
+// "default".
+func (config *Config) Profile(name string) (*Profile, error) {
+	for _, n := range []string{name, os.Getenv("TASKCLUSTER_PROFILE"), config.DefaultProfile, "default"} {
+		if n != "" {
+			name = n
+			break
+		}
+	}
+	profile, exists := config.Profiles[name]
+	if !exists || profile == nil {
+		return nil, fmt.Errorf("Profile %q not found in Taskcluster config file", name)
+	}
+	return profile, nil
+}
+
+// ResolveSettings determines the root URL and credentials to use, with the
+// following precedence:
+//
+//  1. explicit settings, if non-empty (explicit may be nil)
+//  2. environment variables (see RootURLFromEnvVars and CredentialsFromEnvVars)
+//  3. the named profile of the config file (see LoadConfig and Config.Profile)
+//
+// The root URL and credentials are resolved separately, so that e.g. the
+// root URL of a profile can be combined with credentials from environment
+// variables. Credentials are taken as a whole from the first source with a
+// non-empty client id; if there is none, nil credentials are returned.
+//
+// It is not an error for the config file to be missing, unless a profile was
+// requested by name or via TASKCLUSTER_PROFILE.
+func ResolveSettings(explicit *Profile, profileName string) (creds *Credentials, rootURL string, err error) {
+	if explicit == nil {
+		explicit = new(Profile)
+	}
+	var profile *Profile
+	config, err := LoadConfig("")
+	switch {
+	case err == nil:
+		profile, err = config.Profile(profileName)
+		if err != nil && profileName == "" && os.Getenv("TASKCLUSTER_PROFILE") == "" {
+			// no profile was requested, and there is no default profile
+			profile, err = nil, nil
+		}
+	case os.IsNotExist(err) && profileName == "" && os.Getenv("TASKCLUSTER_PROFILE") == "":
+		err = nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if profile == nil {
+		profile = new(Profile)
+	}
+
+	for _, u := range []string{explicit.RootURL, RootURLFromEnvVars(), profile.RootURL} {
+		if u != "" {
+			rootURL = u
+			break
+		}
+	}
+	creds = explicit.Credentials()
+	if creds == nil {
+		if envCreds := CredentialsFromEnvVars(); envCreds.ClientID != "" {
+			creds = envCreds
+		}
+	}
+	if creds == nil {
+		creds = profile.Credentials()
+	}
+	return creds, rootURL, nil
+}
@@ -0,0 +1,146 @@
+package tcclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `
+defaultProfile: staging
+profiles:
+  staging:
+    rootUrl: https://stage.tc.example.com
+    clientId: me/staging
+    accessToken: staging-token
+  production:
+    rootUrl: https://tc.example.com
+    clientId: me/production
+    accessToken: production-token
+    authorizedScopes:
+      - queue:get-artifact:*
+`
+
+// setEnv sets the given environment variables (unsetting those with empty
+// values), and returns a function that restores their previous values.
+func setEnv(vars map[string]string) func() {
+	old := map[string]*string{}
+	for k, v := range vars {
+		if prev, set := os.LookupEnv(k); set {
+			old[k] = &prev
+		} else {
+			old[k] = nil
+		}
+		if v == "" {
+			os.Unsetenv(k)
+		} else {
+			os.Setenv(k, v)
+		}
+	}
+	return func() {
+		for k, v := range old {
+			if v == nil {
+				os.Unsetenv(k)
+			} else {
+				os.Setenv(k, *v)
+			}
+		}
+	}
+}
+
+func writeTestConfig(t *testing.T) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "tcconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(dir, "config.yml")
+	err = ioutil.WriteFile(path, []byte(testConfig), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestResolveSettingsPrecedence(t *testing.T) {
+	path, cleanup := writeTestConfig(t)
+	defer cleanup()
+	noEnv := map[string]string{
+		"TASKCLUSTER_CONFIG":       path,
+		"TASKCLUSTER_PROFILE":      "",
+		"TASKCLUSTER_ROOT_URL":     "",
+		"TASKCLUSTER_PROXY_URL":    "",
+		"TASKCLUSTER_CLIENT_ID":    "",
+		"TASKCLUSTER_ACCESS_TOKEN": "",
+		"TASKCLUSTER_CERTIFICATE":  "",
+	}
+	defer setEnv(noEnv)()
+
+	// default profile
+	creds, rootURL, err := ResolveSettings(nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rootURL != "https://stage.tc.example.com" || creds.ClientID != "me/staging" {
+		t.Errorf("Expected staging profile, but got %v %v", rootURL, creds)
+	}
+
+	// named profile
+	creds, rootURL, err = ResolveSettings(nil, "production")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rootURL != "https://tc.example.com" || creds.AccessToken != "production-token" || !reflect.DeepEqual(creds.AuthorizedScopes, []string{"queue:get-artifact:*"}) {
+		t.Errorf("Expected production profile, but got %v %v", rootURL, creds)
+	}
+
+	// env vars override profile
+	restore := setEnv(map[string]string{
+		"TASKCLUSTER_PROFILE":      "production",
+		"TASKCLUSTER_ROOT_URL":     "https://env.tc.example.com",
+		"TASKCLUSTER_CLIENT_ID":    "me/env",
+		"TASKCLUSTER_ACCESS_TOKEN": "env-token",
+	})
+	creds, rootURL, err = ResolveSettings(nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rootURL != "https://env.tc.example.com" || creds.ClientID != "me/env" || creds.AuthorizedScopes != nil {
+		t.Errorf("Expected settings from env vars, but got %v %v", rootURL, creds)
+	}
+
+	// explicit settings override env vars
+	creds, rootURL, err = ResolveSettings(&Profile{RootURL: "https://explicit.tc.example.com", ClientID: "me/explicit"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rootURL != "https://explicit.tc.example.com" || creds.ClientID != "me/explicit" {
+		t.Errorf("Expected explicit settings, but got %v %v", rootURL, creds)
+	}
+	restore()
+
+	// unknown profile
+	_, _, err = ResolveSettings(nil, "nonexistent")
+	if err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+}
+
+func TestResolveSettingsNoConfigFile(t *testing.T) {
+	defer setEnv(map[string]string{
+		"TASKCLUSTER_CONFIG":    filepath.Join(os.TempDir(), "nonexistent-tc-config.yml"),
+		"TASKCLUSTER_PROFILE":   "",
+		"TASKCLUSTER_ROOT_URL":  "https://env.tc.example.com",
+		"TASKCLUSTER_PROXY_URL": "",
+		"TASKCLUSTER_CLIENT_ID": "",
+	})()
+	creds, rootURL, err := ResolveSettings(nil, "")
+	if err != nil || creds != nil || rootURL != "https://env.tc.example.com" {
+		t.Fatalf("Expected root URL from env and no credentials, but got %v %v %v", rootURL, creds, err)
+	}
+	_, _, err = ResolveSettings(nil, "production")
+	if err == nil {
+		t.Fatal("Expected an error when a profile is requested but there is no config file")
+	}
+}
@@ -53,6 +53,9 @@ func (creds *Credentials) String() string {
 // required for all HTTP operations.
 type Client struct {
 	Credentials *Credentials
+	// If set, CredentialsProvider is asked for the credentials to sign each
+	// request with, and Credentials is ignored
+	CredentialsProvider CredentialsProvider
 	// The Base URL of the service, beneath the root URL of the deployment.
 	// Typically tcclient.BaseURL function will create it for you.
 	// For example, "https://auth.taskcluster.net/v1" for current production auth service.
@@ -62,7 +65,34 @@ type Client struct {
 	// HTTPClient is a ReducedHTTPClient to be used for the http call instead of
 	// the DefaultHTTPClient.
 	HTTPClient ReducedHTTPClient
-	// Context that aborts all requests with this client
+	// RetryPolicy determines whether, and when, failed http requests are
+	// retried. If nil, DefaultRetryPolicy is used.
+	RetryPolicy RetryPolicy
+	// Middleware intercepts each http request attempt, in order, such that
+	// Middleware[0] sees the request first and the response last
+	Middleware []Middleware
+	// If true, request and successful response bodies are not copied into
+	// the CallSummary, and APICall decodes json responses directly from the
+	// http response stream. This reduces memory usage for large payloads.
+	// Bodies of error responses are still captured, since they are small and
+	// needed for APIError.
+	DisableBodyCapture bool
+	// Redactor masks secrets in CallSummary.String and APICallException
+	// error messages. If nil, DefaultRedactor is used.
+	Redactor *Redactor
+	// If set, Metrics is notified of every completed API call
+	Metrics MetricsSink
+	// If set, every http request attempt waits for RateLimiter before being
+	// sent. It is shared by all copies of the Client.
+	RateLimiter *RateLimiter
+	// If true, successful responses must carry a valid Hawk
+	// Server-Authorization header, including a hash of the response body,
+	// otherwise the call fails with a *ResponseVerificationError. Requires
+	// Authenticate to be true.
+	VerifyResponses bool
+	// Context that aborts all requests with this client, except for requests
+	// made via the XxxWithContext methods, which use the context passed to
+	// them instead
 	Context context.Context
 }
 
@@ -0,0 +1,226 @@
+package tcclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider supplies the credentials that a Client signs requests
+// and URLs with. If Client.CredentialsProvider is set, it is asked for
+// credentials every time a request is signed (including retries), so that
+// credentials can be rotated or renewed without creating a new Client.
+//
+// Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	// Credentials returns the credentials to sign the next request with.
+	// The returned credentials must not be modified by the caller. ctx may
+	// be nil.
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// credentials returns the credentials to sign requests with: those of
+// client.CredentialsProvider if set, otherwise client.Credentials.
+func (client *Client) credentials(ctx context.Context) (*Credentials, error) {
+	if client.CredentialsProvider != nil {
+		creds, err := client.CredentialsProvider.Credentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if creds == nil {
+			return nil, errors.New("CredentialsProvider returned no credentials")
+		}
+		return creds, nil
+	}
+	if client.Credentials == nil {
+		return nil, errors.New("No credentials available to sign request; set Credentials or CredentialsProvider of the client")
+	}
+	return client.Credentials, nil
+}
+
+// StaticCredentials returns a CredentialsProvider that always provides the
+// given credentials.
+func StaticCredentials(creds *Credentials) CredentialsProvider {
+	return staticProvider{creds: creds}
+}
+
+type staticProvider struct {
+	creds *Credentials
+}
+
+func (p staticProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	return p.creds, nil
+}
+
+// EnvVarsProvider is a CredentialsProvider that reads credentials from the
+// environment variables listed in CredentialsFromEnvVars every time it is
+// asked, so changes to the environment take effect immediately.
+type EnvVarsProvider struct{}
+
+// Credentials implements the CredentialsProvider interface.
+func (EnvVarsProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	return CredentialsFromEnvVars(), nil
+}
+
+// FileProvider is a CredentialsProvider that reads credentials from a json
+// file with properties clientId, accessToken, and optionally certificate and
+// authorizedScopes. The file is read again whenever its modification time
+// changes, so that an external process can rotate the credentials.
+type FileProvider struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	creds   *Credentials
+}
+
+// NewFileProvider returns a FileProvider for the credentials file at path.
+// The file is not read until credentials are first needed.
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{
+		path: path,
+	}
+}
+
+// Credentials implements the CredentialsProvider interface.
+func (p *FileProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.creds != nil && info.ModTime().Equal(p.modTime) {
+		return p.creds, nil
+	}
+	data, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	creds := new(Credentials)
+	err = json.Unmarshal(data, creds)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot parse credentials file %v: %v", p.path, err)
+	}
+	p.creds = creds
+	p.modTime = info.ModTime()
+	return creds, nil
+}
+
+// RenewingProvider is a CredentialsProvider for temporary credentials, that
+// calls Renew to obtain new credentials before the certificate of the
+// current credentials expires. Renew could for example call
+// CreateTemporaryCredentials, claim a task, or call tclogin.OidcCredentials.
+//
+// Credentials without a certificate are treated as never expiring. If Renew
+// fails, the current credentials are used until they have expired. Concurrent
+// callers share a single renewal, and while it is in progress, callers that
+// still have valid credentials are not made to wait for it.
+type RenewingProvider struct {
+	// Renew returns fresh temporary credentials. ctx may be nil.
+	Renew func(ctx context.Context) (*Credentials, error)
+	// RenewBefore is how long before the certificate expiry new credentials
+	// are obtained. If zero, credentials are renewed five minutes before they
+	// expire.
+	RenewBefore time.Duration
+	mu          sync.Mutex
+	creds       *Credentials
+	expiry      time.Time
+	// the renewal in progress, if any
+	renewal *renewal
+}
+
+// renewal is a call of RenewingProvider.Renew
+type renewal struct {
+	// closed when the renewal has completed
+	done chan struct{}
+	err  error
+}
+
+// NewTemporaryCredentialsProvider returns a RenewingProvider that derives
+// temporary credentials, valid for duration and restricted to scopes, from
+// the given permanent credentials, renewing them before they expire.
+func NewTemporaryCredentialsProvider(permaCreds *Credentials, duration time.Duration, scopes ...string) *RenewingProvider {
+	return &RenewingProvider{
+		Renew: func(ctx context.Context) (*Credentials, error) {
+			return permaCreds.CreateTemporaryCredentials(duration, scopes...)
+		},
+		RenewBefore: duration / 4,
+	}
+}
+
+// Credentials implements the CredentialsProvider interface.
+func (p *RenewingProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	renewBefore := p.RenewBefore
+	if renewBefore == 0 {
+		renewBefore = 5 * time.Minute
+	}
+	p.mu.Lock()
+	if p.creds != nil && (p.expiry.IsZero() || time.Now().Add(renewBefore).Before(p.expiry)) {
+		defer p.mu.Unlock()
+		return p.creds, nil
+	}
+	r := p.renewal
+	if r == nil {
+		r = &renewal{done: make(chan struct{})}
+		p.renewal = r
+		p.mu.Unlock()
+		p.renew(ctx, r)
+	} else {
+		if p.valid() {
+			defer p.mu.Unlock()
+			return p.creds, nil
+		}
+		p.mu.Unlock()
+		var cancelled <-chan struct{}
+		if ctx != nil {
+			cancelled = ctx.Done()
+		}
+		select {
+		case <-r.done:
+		case <-cancelled:
+			return nil, ctx.Err()
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r.err != nil && !p.valid() {
+		return nil, r.err
+	}
+	return p.creds, nil
+}
+
+// renew calls p.Renew, storing the new credentials if it succeeds, and
+// completes r. p.mu must not be held.
+func (p *RenewingProvider) renew(ctx context.Context, r *renewal) {
+	var expiry time.Time
+	creds, err := p.Renew(ctx)
+	if err == nil {
+		var cert *Certificate
+		cert, err = creds.Cert()
+		if err != nil {
+			err = fmt.Errorf("Cannot parse certificate of renewed credentials: %v", err)
+		} else if cert != nil {
+			expiry = time.Unix(0, cert.Expiry*1e6)
+		}
+	}
+	p.mu.Lock()
+	if err == nil {
+		p.creds = creds
+		p.expiry = expiry
+	}
+	r.err = err
+	p.renewal = nil
+	p.mu.Unlock()
+	close(r.done)
+}
+
+// valid returns true if p has credentials that have not yet expired. p.mu
+// must be held.
+func (p *RenewingProvider) valid() bool {
+	return p.creds != nil && (p.expiry.IsZero() || time.Now().Before(p.expiry))
+}
@@ -0,0 +1,287 @@
+package tcclient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// clientIDServer returns a test server that responds with the Hawk client id
+// that each request was signed with.
+func clientIDServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, attr := range wwwAuthenticateAttr.FindAllStringSubmatch(r.Header.Get("Authorization"), -1) {
+			if attr[1] == "id" {
+				w.Write([]byte(attr[2]))
+			}
+		}
+	}))
+}
+
+func TestFileProvider(t *testing.T) {
+	s := clientIDServer()
+	defer s.Close()
+	dir, err := ioutil.TempDir("", "credsprovider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "creds.json")
+	writeCreds := func(clientID string, modTime time.Time) {
+		err := ioutil.WriteFile(path, []byte(`{"clientId": "`+clientID+`", "accessToken": "no-secret"}`), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modTime, modTime)
+	}
+	client := Client{
+		CredentialsProvider: NewFileProvider(path),
+		BaseURL:             s.URL,
+		Authenticate:        true,
+	}
+	now := time.Now()
+	for i, clientID := range []string{"first", "second"} {
+		writeCreds(clientID, now.Add(time.Duration(i)*time.Minute))
+		cs, err := client.Request(nil, "GET", "/whatever", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cs.HTTPResponseBody != clientID {
+			t.Fatalf("Expected request to be signed by %q but was signed by %q", clientID, cs.HTTPResponseBody)
+		}
+	}
+	os.Remove(path)
+	_, err = client.Request(nil, "GET", "/whatever", nil)
+	if err == nil {
+		t.Fatal("Expected an error when the credentials file does not exist")
+	}
+}
+
+func TestRenewingProvider(t *testing.T) {
+	permaCreds := &Credentials{
+		ClientID:    "perma",
+		AccessToken: "no-secret",
+	}
+	renewals := 0
+	provider := &RenewingProvider{
+		Renew: func(ctx context.Context) (*Credentials, error) {
+			renewals++
+			return permaCreds.CreateTemporaryCredentials(10*time.Minute, "scope")
+		},
+		RenewBefore: 9 * time.Minute,
+	}
+	first, err := provider.Credentials(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, _ := provider.Credentials(nil)
+	if renewals != 1 || first != second {
+		t.Fatalf("Expected credentials to be reused until close to expiry, but got %v renewals", renewals)
+	}
+	provider.RenewBefore = 11 * time.Minute
+	third, _ := provider.Credentials(nil)
+	if renewals != 2 || third == first {
+		t.Fatalf("Expected credentials to be renewed before expiry, but got %v renewals", renewals)
+	}
+	if !strings.Contains(third.Certificate, `"scope"`) {
+		t.Fatalf("Expected temporary credentials, but got %v", third)
+	}
+}
+
+func TestRenewingProviderRenewalFailure(t *testing.T) {
+	permaCreds := &Credentials{
+		ClientID:    "perma",
+		AccessToken: "no-secret",
+	}
+	duration := 10 * time.Minute
+	provider := &RenewingProvider{
+		Renew: func(ctx context.Context) (*Credentials, error) {
+			return permaCreds.CreateTemporaryCredentials(duration, "scope")
+		},
+		RenewBefore: 9 * time.Minute,
+	}
+	first, err := provider.Credentials(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	renewErr := errors.New("auth service unavailable")
+	provider.Renew = func(ctx context.Context) (*Credentials, error) {
+		return nil, renewErr
+	}
+	// the credentials are due for renewal, but have not expired
+	provider.RenewBefore = 11 * time.Minute
+	second, err := provider.Credentials(nil)
+	if err != nil || second != first {
+		t.Fatalf("Expected current credentials to be used after failed renewal, but got %v, %v", second, err)
+	}
+
+	// once the credentials have expired, the renewal error is returned
+	provider.Renew = func(ctx context.Context) (*Credentials, error) {
+		return permaCreds.CreateTemporaryCredentials(-time.Minute, "scope")
+	}
+	if _, err := provider.Credentials(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	provider.Renew = func(ctx context.Context) (*Credentials, error) {
+		return nil, renewErr
+	}
+	if creds, err := provider.Credentials(nil); err != renewErr || creds != nil {
+		t.Fatalf("Expected renewal error once credentials have expired, but got %v, %v", creds, err)
+	}
+}
+
+func TestRenewingProviderConcurrentRenewal(t *testing.T) {
+	permaCreds := &Credentials{
+		ClientID:    "perma",
+		AccessToken: "no-secret",
+	}
+	var renewals int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	provider := &RenewingProvider{
+		Renew: func(ctx context.Context) (*Credentials, error) {
+			if atomic.AddInt32(&renewals, 1) > 1 {
+				<-started
+				<-release
+			}
+			return permaCreds.CreateTemporaryCredentials(10*time.Minute, "scope")
+		},
+		RenewBefore: 9 * time.Minute,
+	}
+	current, err := provider.Credentials(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// start a slow renewal
+	provider.RenewBefore = 11 * time.Minute
+	renewed := make(chan *Credentials)
+	go func() {
+		creds, _ := provider.Credentials(nil)
+		renewed <- creds
+	}()
+	close(started)
+	for atomic.LoadInt32(&renewals) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// callers with valid credentials are not blocked by the renewal
+	for i := 0; i < 10; i++ {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			creds, err := provider.Credentials(nil)
+			if err != nil || creds != current {
+				t.Errorf("Expected current credentials during renewal, but got %v, %v", creds, err)
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected caller with valid credentials not to wait for renewal")
+		}
+	}
+	close(release)
+	if creds := <-renewed; creds == nil || creds == current {
+		t.Fatalf("Expected renewed credentials, but got %v", creds)
+	}
+	if renewals != 2 {
+		t.Fatalf("Expected 2 renewals, but got %v", renewals)
+	}
+}
+
+func TestRenewingProviderSharedRenewal(t *testing.T) {
+	permaCreds := &Credentials{
+		ClientID:    "perma",
+		AccessToken: "no-secret",
+	}
+	var renewals int32
+	release := make(chan struct{})
+	provider := &RenewingProvider{
+		Renew: func(ctx context.Context) (*Credentials, error) {
+			atomic.AddInt32(&renewals, 1)
+			<-release
+			return permaCreds.CreateTemporaryCredentials(10*time.Minute, "scope")
+		},
+	}
+	results := make(chan *Credentials)
+	for i := 0; i < 5; i++ {
+		go func() {
+			creds, _ := provider.Credentials(nil)
+			results <- creds
+		}()
+	}
+	for atomic.LoadInt32(&renewals) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	// give the other callers a chance to start their own renewal, if they
+	// were going to
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	first := <-results
+	for i := 1; i < 5; i++ {
+		if creds := <-results; creds == nil || creds != first {
+			t.Fatalf("Expected all callers to get the same renewed credentials, but got %v and %v", first, creds)
+		}
+	}
+	if renewals != 1 {
+		t.Fatalf("Expected callers without credentials to share 1 renewal, but got %v", renewals)
+	}
+}
+
+func TestRenewingProviderCancelledWait(t *testing.T) {
+	var renewals int32
+	release := make(chan struct{})
+	defer close(release)
+	provider := &RenewingProvider{
+		Renew: func(ctx context.Context) (*Credentials, error) {
+			atomic.AddInt32(&renewals, 1)
+			<-release
+			return &Credentials{ClientID: "renewed"}, nil
+		},
+	}
+	go provider.Credentials(nil)
+	for atomic.LoadInt32(&renewals) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	// a caller without valid credentials waits for the renewal, unless its
+	// context is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.Credentials(ctx); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, but got %v", err)
+	}
+}
+
+func TestCredentialsProviderPrecedence(t *testing.T) {
+	s := clientIDServer()
+	defer s.Close()
+	client := Client{
+		Credentials:         &Credentials{ClientID: "ignored", AccessToken: "no-secret"},
+		CredentialsProvider: StaticCredentials(&Credentials{ClientID: "provided", AccessToken: "no-secret"}),
+		BaseURL:             s.URL,
+		Authenticate:        true,
+	}
+	cs, err := client.Request(nil, "GET", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cs.HTTPResponseBody != "provided" {
+		t.Fatalf("Expected request to be signed by provided credentials, but was signed by %q", cs.HTTPResponseBody)
+	}
+	u, err := client.SignedURL("/whatever", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(u.String(), "bewit=") {
+		t.Fatalf("Expected signed URL, but got %v", u)
+	}
+}
@@ -0,0 +1,181 @@
+package tcclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors that can be tested for with errors.Is against an error
+// returned by APICall (or any generated API method), e.g.
+//
+//  _, err := queue.Task(taskID)
+//  if errors.Is(err, tcclient.ErrNotFound) {
+//  	// task does not exist
+//  }
+//
+// An *APIError matches the sentinel that corresponds to its HTTP status code.
+// A *NetworkError, for a request that could not be completed due to a network
+// failure (after any retries have been exhausted), matches ErrNetwork.
+var (
+	ErrBadRequest           = errors.New("tcclient: bad request")
+	ErrAuthenticationFailed = errors.New("tcclient: authentication failed")
+	ErrInsufficientScopes   = errors.New("tcclient: insufficient scopes")
+	ErrNotFound             = errors.New("tcclient: resource not found")
+	ErrConflict             = errors.New("tcclient: request conflict")
+	ErrResourceExpired      = errors.New("tcclient: resource expired")
+	ErrServerError          = errors.New("tcclient: server error")
+	ErrNetwork              = errors.New("tcclient: network failure")
+)
+
+// statusSentinels maps HTTP status codes to the sentinel error they match.
+// All 5xx status codes match ErrServerError, so they are not listed here.
+var statusSentinels = map[int]error{
+	400: ErrBadRequest,
+	401: ErrAuthenticationFailed,
+	403: ErrInsufficientScopes,
+	404: ErrNotFound,
+	409: ErrConflict,
+	410: ErrResourceExpired,
+}
+
+// APIError represents a response from a Taskcluster service with an HTTP
+// status code outside of the 2xx range. It is wrapped by the *APICallException
+// returned by APICall, so it can be retrieved with errors.As:
+//
+//  var apiErr *tcclient.APIError
+//  if errors.As(err, &apiErr) && apiErr.Code == "InsufficientScopes" {
+//  	...
+//  }
+type APIError struct {
+	// HTTP status code of the response, e.g. 404
+	StatusCode int
+	// Taskcluster error code from the response body, e.g. "ResourceNotFound",
+	// "InsufficientScopes" or "InputValidationError". Empty if the response
+	// body was not a Taskcluster error document.
+	Code string
+	// Human readable error message from the response body
+	Message string
+	// The request ID reported by the service in the X-For-Request-Id (or
+	// X-Request-Id) response header, if any
+	RequestID string
+	// The error generated by the http retry logic, which Error() reproduces
+	// so that error messages are unchanged
+	cause error
+}
+
+// errorResponse is the json document returned by Taskcluster services in the
+// body of error responses.
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// newAPIError creates an *APIError from the failed call, wrapping cause.
+func newAPIError(callSummary *CallSummary, cause error) *APIError {
+	resp := callSummary.HTTPResponse
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-For-Request-Id"),
+		cause:      cause,
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = resp.Header.Get("X-Request-Id")
+	}
+	body := new(errorResponse)
+	if json.Unmarshal([]byte(callSummary.HTTPResponseBody), body) == nil {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Message
+	}
+	return apiErr
+}
+
+func (err *APIError) Error() string {
+	if err.cause != nil {
+		return err.cause.Error()
+	}
+	if err.Code != "" {
+		return fmt.Sprintf("HTTP response code %v (%v)\n%v", err.StatusCode, err.Code, err.Message)
+	}
+	return fmt.Sprintf("HTTP response code %v\n%v", err.StatusCode, err.Message)
+}
+
+// Unwrap returns the underlying httpbackoff.BadHttpResponseCode error, if
+// any.
+func (err *APIError) Unwrap() error {
+	return err.cause
+}
+
+// Is reports whether err matches the given sentinel error, based on the HTTP
+// status code of the response.
+func (err *APIError) Is(target error) bool {
+	if target == ErrServerError {
+		return err.StatusCode/100 == 5
+	}
+	sentinel, known := statusSentinels[err.StatusCode]
+	return known && sentinel == target
+}
+
+// NetworkError describes an API call that could not be completed due to a
+// network failure, such as a refused connection, after any retries have been
+// exhausted. It is wrapped by the *APICallException returned by APICall, and
+// matches ErrNetwork.
+type NetworkError struct {
+	// The error returned by the http client, usually a *url.Error
+	Err error
+}
+
+func (err *NetworkError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the error returned by the http client.
+func (err *NetworkError) Unwrap() error {
+	return err.Err
+}
+
+// Is reports whether target is ErrNetwork.
+func (err *NetworkError) Is(target error) bool {
+	return target == ErrNetwork
+}
+
+// newAPICallException returns an *APICallException for an API call that
+// failed with err. RootCause is the error that earlier releases reported: for
+// an *APIError, the httpbackoff.BadHttpResponseCode that it wraps, and for a
+// *NetworkError, the error returned by the http client. The *APIError or
+// *NetworkError itself is returned by Unwrap.
+func newAPICallException(callSummary *CallSummary, err error) *APICallException {
+	exception := &APICallException{
+		CallSummary: callSummary,
+		RootCause:   legacyError(err),
+	}
+	if exception.RootCause != err {
+		exception.err = err
+	}
+	return exception
+}
+
+// legacyError returns the error that earlier releases reported in place of
+// err: for an *APIError, the httpbackoff.BadHttpResponseCode that it wraps,
+// and for a *NetworkError, the error returned by the http client. Other
+// errors are returned unchanged.
+func legacyError(err error) error {
+	switch e := err.(type) {
+	case *APIError:
+		if e.cause != nil {
+			return e.cause
+		}
+	case *NetworkError:
+		return e.Err
+	}
+	return err
+}
+
+// Unwrap returns the *APIError or *NetworkError describing the failed API
+// call, if any, and otherwise the root cause.
+func (err *APICallException) Unwrap() error {
+	if err.err != nil {
+		return err.err
+	}
+	return err.RootCause
+}
@@ -0,0 +1,163 @@
+package tcclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/taskcluster/httpbackoff"
+)
+
+func TestAPIErrorFromResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-For-Request-Id", "4d61b66e-03d4-4e0c-8b8b-4d1f0a1e3f6b")
+		w.WriteHeader(404)
+		w.Write([]byte(`{"code": "ResourceNotFound", "message": "Task not found", "requestInfo": {"method": "task"}}`))
+	}))
+	defer s.Close()
+	client := Client{
+		BaseURL:      s.URL,
+		Authenticate: false,
+	}
+	_, _, err := client.APICall(nil, "GET", "/task/abc", new(struct{}), nil)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error to match ErrNotFound: %v", err)
+	}
+	for _, sentinel := range []error{ErrConflict, ErrServerError, ErrInsufficientScopes, ErrNetwork} {
+		if errors.Is(err, sentinel) {
+			t.Errorf("Did not expect error to match %v", sentinel)
+		}
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError but got %T", err)
+	}
+	if apiErr.StatusCode != 404 {
+		t.Errorf("Expected status code 404 but got %v", apiErr.StatusCode)
+	}
+	if apiErr.Code != "ResourceNotFound" {
+		t.Errorf("Expected code ResourceNotFound but got %q", apiErr.Code)
+	}
+	if apiErr.Message != "Task not found" {
+		t.Errorf("Expected message %q but got %q", "Task not found", apiErr.Message)
+	}
+	if apiErr.RequestID != "4d61b66e-03d4-4e0c-8b8b-4d1f0a1e3f6b" {
+		t.Errorf("Unexpected request ID %q", apiErr.RequestID)
+	}
+	// existing consumers may rely on the httpbackoff error type
+	var badResponse httpbackoff.BadHttpResponseCode
+	if !errors.As(err, &badResponse) || badResponse.HttpResponseCode != 404 {
+		t.Errorf("Expected httpbackoff.BadHttpResponseCode to be wrapped")
+	}
+	if _, ok := err.(*APICallException).RootCause.(httpbackoff.BadHttpResponseCode); !ok {
+		t.Errorf("Expected RootCause to be an httpbackoff.BadHttpResponseCode, but got %T", err.(*APICallException).RootCause)
+	}
+}
+
+func TestAPIErrorNonJSONBody(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(409)
+		w.Write([]byte("<html>conflict</html>"))
+	}))
+	defer s.Close()
+	client := Client{
+		BaseURL:      s.URL,
+		Authenticate: false,
+	}
+	_, _, err := client.APICall(nil, "PUT", "/whatever", new(struct{}), nil)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Expected error to match ErrConflict: %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError but got %T", err)
+	}
+	if apiErr.Code != "" || apiErr.Message != "" {
+		t.Errorf("Expected no code or message, but got %q / %q", apiErr.Code, apiErr.Message)
+	}
+}
+
+func TestRequestErrorType(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		w.Write([]byte(`{"code": "ResourceNotFound", "message": "Task not found"}`))
+	}))
+	defer s.Close()
+	client := Client{
+		BaseURL:      s.URL,
+		Authenticate: false,
+	}
+	// as in earlier releases, Request returns the httpbackoff error type, so
+	// that existing type assertions keep working
+	_, err := client.Request(nil, "GET", "/task/abc", nil)
+	if badResponse, ok := err.(httpbackoff.BadHttpResponseCode); !ok || badResponse.HttpResponseCode != 404 {
+		t.Fatalf("Expected an httpbackoff.BadHttpResponseCode with status code 404, but got %T: %v", err, err)
+	}
+	_, body, err := client.RequestStream(nil, "GET", "/task/abc", nil)
+	if _, ok := err.(httpbackoff.BadHttpResponseCode); !ok || body != nil {
+		t.Fatalf("Expected an httpbackoff.BadHttpResponseCode and no body from RequestStream, but got %T: %v", err, err)
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	testCases := map[int]error{
+		400: ErrBadRequest,
+		401: ErrAuthenticationFailed,
+		403: ErrInsufficientScopes,
+		404: ErrNotFound,
+		409: ErrConflict,
+		410: ErrResourceExpired,
+		500: ErrServerError,
+		503: ErrServerError,
+	}
+	for statusCode, sentinel := range testCases {
+		err := &APIError{StatusCode: statusCode}
+		if !errors.Is(err, sentinel) {
+			t.Errorf("Expected status code %v to match %v", statusCode, sentinel)
+		}
+	}
+	if errors.Is(&APIError{StatusCode: 418}, ErrBadRequest) {
+		t.Errorf("Did not expect status code 418 to match ErrBadRequest")
+	}
+}
+
+func TestNetworkError(t *testing.T) {
+	// a server that is no longer listening
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	s.Close()
+	client := Client{
+		BaseURL:      s.URL,
+		Authenticate: false,
+		RetryPolicy:  fastRetries,
+	}
+	_, _, err := client.APICall(nil, "GET", "/whatever", new(struct{}), nil)
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("Expected error to match ErrNetwork: %v", err)
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Fatalf("Did not expect network error to match ErrNotFound")
+	}
+	// existing consumers may rely on the http client error type
+	if _, ok := err.(*APICallException).RootCause.(*url.Error); !ok {
+		t.Errorf("Expected RootCause to be a *url.Error, but got %T", err.(*APICallException).RootCause)
+	}
+
+	var networkErr *NetworkError
+	if !errors.As(err, &networkErr) {
+		t.Fatalf("Expected a *NetworkError but got %T", err)
+	}
+	if _, ok := networkErr.Err.(*url.Error); !ok {
+		t.Errorf("Expected *NetworkError to wrap a *url.Error, but got %T", networkErr.Err)
+	}
+
+	// Request returns the http client error, as in earlier releases
+	_, err = client.Request(nil, "GET", "/whatever", nil)
+	if _, ok := err.(*url.Error); !ok {
+		t.Errorf("Expected error from Request to be a *url.Error, but got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,337 @@
+package fakequeue
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/tcqueue"
+)
+
+// artifactGracePeriod is how long after a run is resolved artifacts may
+// still be created for it, as in the real queue
+const artifactGracePeriod = 25 * time.Minute
+
+// artifact is an artifact of a run
+type artifact struct {
+	storageType     string
+	contentType     string
+	contentEncoding string
+	expires         tcclient.Time
+	// url of a reference artifact
+	url string
+	// reason and message of an error artifact
+	reason  string
+	message string
+	// number of parts of a multipart blob artifact, or 0
+	parts int
+	// uploaded content of an s3 or blob artifact, keyed by part number
+	// (0 unless the artifact is a multipart blob artifact)
+	uploaded map[int][]byte
+	// the content of an s3 or blob artifact, once it is available
+	content []byte
+	// true once the content is available
+	present bool
+}
+
+// artifactRequest holds the properties of the request bodies of all
+// storage types accepted by CreateArtifact
+type artifactRequest struct {
+	StorageType     string                  `json:"storageType"`
+	ContentType     string                  `json:"contentType"`
+	ContentEncoding string                  `json:"contentEncoding"`
+	Expires         tcclient.Time           `json:"expires"`
+	Parts           []tcqueue.MultipartPart `json:"parts"`
+	URL             string                  `json:"url"`
+	Reason          string                  `json:"reason"`
+	Message         string                  `json:"message"`
+}
+
+// content is returned by an endpoint to respond with raw content rather
+// than json
+type content struct {
+	contentType     string
+	contentEncoding string
+	etag            string
+	data            []byte
+}
+
+func (c *content) write(w http.ResponseWriter) {
+	if c.contentType != "" {
+		w.Header().Set("Content-Type", c.contentType)
+	}
+	if c.contentEncoding != "" {
+		w.Header().Set("Content-Encoding", c.contentEncoding)
+	}
+	if c.etag != "" {
+		w.Header().Set("ETag", c.etag)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(c.data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(c.data)
+}
+
+func (q *Queue) createArtifact(c *call) (interface{}, *apiError) {
+	t, run, err := q.getRun(c)
+	if err != nil {
+		return nil, err
+	}
+	if run.State != "running" && !(resolved(tcqueue.TaskStatusStructureState(run.State)) && c.now.Before(run.Resolved.Time().Add(artifactGracePeriod))) {
+		return nil, conflict("run %v of task %v is %v, so artifacts cannot be created for it", run.RunID, t.status.TaskID, run.State)
+	}
+	request := new(artifactRequest)
+	if err := c.decode(request); err != nil {
+		return nil, err
+	}
+	if request.Expires.After(t.status.Expires) {
+		return nil, inputError("artifact expires %v after its task, at %v", request.Expires, t.status.Expires)
+	}
+	name := c.params["name"]
+	if existing := t.artifacts[run.RunID][name]; existing != nil && existing.storageType != request.StorageType {
+		return nil, conflict("artifact %v already exists with storageType %v", name, existing.storageType)
+	}
+	a := &artifact{
+		storageType: request.StorageType,
+		contentType: request.ContentType,
+		expires:     request.Expires,
+		uploaded:    map[int][]byte{},
+	}
+	var response interface{}
+	contentURL := c.contentURL(t.status.TaskID, run.RunID, name)
+	switch request.StorageType {
+	case "s3":
+		response = &tcqueue.S3ArtifactResponse{
+			ContentType: request.ContentType,
+			Expires:     request.Expires,
+			PutURL:      contentURL,
+			StorageType: tcqueue.S3ArtifactResponseStorageTypeS3,
+		}
+	case "blob":
+		a.contentEncoding = request.ContentEncoding
+		a.parts = len(request.Parts)
+		headers := map[string]string{
+			"content-type": request.ContentType,
+		}
+		if request.ContentEncoding != "" {
+			headers["content-encoding"] = request.ContentEncoding
+		}
+		blob := &tcqueue.BlobArtifactResponse{
+			Expires:     request.Expires,
+			StorageType: tcqueue.BlobArtifactResponseStorageTypeBlob,
+		}
+		if a.parts == 0 {
+			blob.Requests = []tcqueue.HTTPRequest{{Headers: headers, Method: "PUT", URL: contentURL}}
+		}
+		for i := 1; i <= a.parts; i++ {
+			blob.Requests = append(blob.Requests, tcqueue.HTTPRequest{
+				Headers: headers,
+				Method:  "PUT",
+				URL:     contentURL + "?partNumber=" + strconv.Itoa(i),
+			})
+		}
+		response = blob
+	case "reference":
+		if request.URL == "" {
+			return nil, inputError("reference artifact %v has no url", name)
+		}
+		a.url = request.URL
+		response = &tcqueue.RedirectArtifactResponse{StorageType: tcqueue.RedirectArtifactResponseStorageTypeReference}
+	case "error":
+		switch request.Reason {
+		case "file-missing-on-worker", "invalid-resource-on-worker", "too-large-file-on-worker":
+		default:
+			return nil, inputError("invalid error artifact reason %q", request.Reason)
+		}
+		a.reason = request.Reason
+		a.message = request.Message
+		response = &tcqueue.ErrorArtifactResponse{StorageType: tcqueue.ErrorArtifactResponseStorageTypeError}
+	default:
+		return nil, inputError("fakequeue does not support storageType %q", request.StorageType)
+	}
+	if t.artifacts[run.RunID] == nil {
+		t.artifacts[run.RunID] = map[string]*artifact{}
+	}
+	t.artifacts[run.RunID][name] = a
+	return response, nil
+}
+
+func (q *Queue) completeArtifact(c *call) (interface{}, *apiError) {
+	a, err := q.getArtifactOfRun(c)
+	if err != nil {
+		return nil, err
+	}
+	if a.storageType != "blob" {
+		return nil, inputError("only blob artifacts can be completed, not %v artifacts", a.storageType)
+	}
+	payload := new(tcqueue.CompleteArtifactRequest)
+	if err := c.decode(payload); err != nil {
+		return nil, err
+	}
+	if a.parts == 0 {
+		if _, ok := a.uploaded[0]; !ok {
+			return nil, conflict("artifact %v has not been uploaded", c.params["name"])
+		}
+		a.content = a.uploaded[0]
+	} else {
+		a.content = []byte{}
+		for i := 1; i <= a.parts; i++ {
+			part, ok := a.uploaded[i]
+			if !ok {
+				return nil, conflict("part %v of artifact %v has not been uploaded", i, c.params["name"])
+			}
+			a.content = append(a.content, part...)
+		}
+	}
+	a.present = true
+	return nil, nil
+}
+
+// getArtifactOfRun returns the artifact with the taskId, runId and name path
+// parameters
+func (q *Queue) getArtifactOfRun(c *call) (*artifact, *apiError) {
+	t, run, err := q.getRun(c)
+	if err != nil {
+		return nil, err
+	}
+	a := t.artifacts[run.RunID][c.params["name"]]
+	if a == nil {
+		return nil, notFound("run %v of task %v has no artifact %v", run.RunID, t.status.TaskID, c.params["name"])
+	}
+	return a, nil
+}
+
+func (q *Queue) getArtifact(c *call) (interface{}, *apiError) {
+	t, run, err := q.getRun(c)
+	if err != nil {
+		return nil, err
+	}
+	return q.artifactResponse(c, t, run.RunID)
+}
+
+func (q *Queue) getLatestArtifact(c *call) (interface{}, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, err
+	}
+	run := t.lastRun()
+	if run == nil {
+		return nil, notFound("task %v has no runs", t.status.TaskID)
+	}
+	return q.artifactResponse(c, t, run.RunID)
+}
+
+// artifactResponse returns the response to a request for the artifact with
+// the name path parameter, of the given run of t
+func (q *Queue) artifactResponse(c *call, t *task, runID int64) (interface{}, *apiError) {
+	name := c.params["name"]
+	a := t.artifacts[runID][name]
+	if a == nil || c.now.After(a.expires.Time()) {
+		return nil, notFound("run %v of task %v has no artifact %v", runID, t.status.TaskID, name)
+	}
+	switch a.storageType {
+	case "reference":
+		return redirect(a.url), nil
+	case "error":
+		return nil, errorf(http.StatusFailedDependency, "ErrorArtifact", "%v: %v", a.reason, a.message)
+	}
+	return redirect(c.contentURL(t.status.TaskID, runID, name)), nil
+}
+
+func (q *Queue) listArtifacts(c *call) (interface{}, *apiError) {
+	t, run, err := q.getRun(c)
+	if err != nil {
+		return nil, err
+	}
+	return q.artifactList(c, t, run.RunID)
+}
+
+func (q *Queue) listLatestArtifacts(c *call) (interface{}, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, err
+	}
+	run := t.lastRun()
+	if run == nil {
+		return nil, notFound("task %v has no runs", t.status.TaskID)
+	}
+	return q.artifactList(c, t, run.RunID)
+}
+
+// artifactList returns a page of the artifacts of the given run of t,
+// sorted by name
+func (q *Queue) artifactList(c *call, t *task, runID int64) (interface{}, *apiError) {
+	names := []string{}
+	for name, a := range t.artifacts[runID] {
+		if !c.now.After(a.expires.Time()) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	offset, length, next, err := q.page(c, len(names))
+	if err != nil {
+		return nil, err
+	}
+	artifacts := []tcqueue.Artifact{}
+	for _, name := range names[offset : offset+length] {
+		a := t.artifacts[runID][name]
+		artifacts = append(artifacts, tcqueue.Artifact{
+			ContentType: a.contentType,
+			Expires:     a.expires,
+			Name:        name,
+			StorageType: tcqueue.ArtifactStorageType(a.storageType),
+		})
+	}
+	return &tcqueue.ListArtifactsResponse{
+		Artifacts:         artifacts,
+		ContinuationToken: next,
+	}, nil
+}
+
+// uploadContent stores the content of an s3 or blob artifact, standing in
+// for an upload to S3
+func (q *Queue) uploadContent(c *call) (interface{}, *apiError) {
+	a, err := q.getArtifactOfRun(c)
+	if err != nil {
+		return nil, err
+	}
+	part := 0
+	switch a.storageType {
+	case "s3":
+		a.content = c.body
+		a.present = true
+	case "blob":
+		if a.parts > 0 {
+			var convErr error
+			part, convErr = strconv.Atoi(c.req.URL.Query().Get("partNumber"))
+			if convErr != nil || part < 1 || part > a.parts {
+				return nil, inputError("invalid partNumber %q", c.req.URL.Query().Get("partNumber"))
+			}
+		}
+	default:
+		return nil, inputError("%v artifacts have no content to upload", a.storageType)
+	}
+	a.uploaded[part] = c.body
+	sum := md5.Sum(c.body)
+	return &content{etag: `"` + hex.EncodeToString(sum[:]) + `"`}, nil
+}
+
+// downloadContent serves the content of an s3 or blob artifact, standing in
+// for a download from S3
+func (q *Queue) downloadContent(c *call) (interface{}, *apiError) {
+	a, err := q.getArtifactOfRun(c)
+	if err != nil {
+		return nil, err
+	}
+	if !a.present {
+		return nil, notFound("the content of artifact %v has not been uploaded", c.params["name"])
+	}
+	return &content{
+		contentType:     a.contentType,
+		contentEncoding: a.contentEncoding,
+		data:            a.content,
+	}, nil
+}
@@ -0,0 +1,314 @@
+// Package fakequeue provides an in-memory fake of the Taskcluster queue
+// service, so that workers, schedulers and other code built on tcqueue can be
+// tested offline, without access to a live deployment.
+//
+// A *Queue is an http.Handler which serves the queue API under the path
+// /api/queue/v1, so it can be used with a real tcqueue client via
+// net/http/httptest:
+//
+//  fake := fakequeue.New()
+//  server := httptest.NewServer(fake)
+//  defer server.Close()
+//  queue := tcqueue.New(nil, server.URL)
+//  ... create, claim and resolve tasks with queue ...
+//
+// The fake models the task state machine of the real queue: task states
+// (unscheduled, pending, running, completed, failed and exception), task
+// dependencies with "all-completed" and "all-resolved" requires semantics,
+// runs with retries, reruns and cancellation, claims that expire unless they
+// are reclaimed before their takenUntil time, and task deadlines. It supports
+// ClaimWork, s3, blob, reference and error artifacts, and the pagination of
+// ListTaskGroup, ListDependentTasks and the artifact listings.
+//
+// Time is read from the Clock field, so tests can expire claims and
+// deadlines by advancing a fake clock. Requests are not authenticated, so
+// scopes are not checked, and ClaimWork returns immediately rather than
+// waiting for work to become available. Provisioner, worker type and worker
+// endpoints are not implemented.
+package fakequeue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apiPath is the path under which the queue API is served, matching
+	// the base URL of a client created with tcqueue.New(creds, rootURL)
+	// where rootURL is the URL of the server
+	apiPath = "/api/queue/v1"
+	// contentPath is the path under which artifact content is uploaded and
+	// downloaded, standing in for S3
+	contentPath = "/fakequeue/artifacts"
+)
+
+// Queue is an in-memory fake of the Taskcluster queue service. It is safe
+// for concurrent use. The exported fields should be set before the Queue
+// starts serving requests.
+type Queue struct {
+	// Clock returns the current time. It defaults to time.Now; tests can
+	// replace it to expire claims and task deadlines without waiting.
+	Clock func() time.Time
+	// How long a claim lasts before it must be reclaimed (default 20
+	// minutes, as in the real queue)
+	ClaimTimeout time.Duration
+	// Maximum number of items per page of listings, regardless of the
+	// limit requested (default 1000). Set it low to exercise the pagination
+	// of clients.
+	MaxPageSize int
+
+	mu    sync.Mutex
+	tasks map[string]*task
+	// task ids in order of creation
+	taskIDs []string
+	// incremented each time a task becomes pending, to claim tasks of equal
+	// priority in the order they became pending
+	pendingSeq int
+}
+
+// New returns a *Queue with no tasks.
+func New() *Queue {
+	return &Queue{
+		Clock:        time.Now,
+		ClaimTimeout: 20 * time.Minute,
+		MaxPageSize:  1000,
+		tasks:        map[string]*task{},
+	}
+}
+
+// apiError is an error response, in the format of Taskcluster services
+type apiError struct {
+	statusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (err *apiError) Error() string {
+	return err.Code + ": " + err.Message
+}
+
+func errorf(statusCode int, code string, format string, a ...interface{}) *apiError {
+	return &apiError{
+		statusCode: statusCode,
+		Code:       code,
+		Message:    fmt.Sprintf(format, a...),
+	}
+}
+
+func notFound(format string, a ...interface{}) *apiError {
+	return errorf(http.StatusNotFound, "ResourceNotFound", format, a...)
+}
+
+func conflict(format string, a ...interface{}) *apiError {
+	return errorf(http.StatusConflict, "RequestConflict", format, a...)
+}
+
+func inputError(format string, a ...interface{}) *apiError {
+	return errorf(http.StatusBadRequest, "InputError", format, a...)
+}
+
+// redirect is returned by an endpoint to respond with a 303 redirect
+type redirect string
+
+// call holds the details of a request to an endpoint
+type call struct {
+	req    *http.Request
+	params map[string]string
+	now    time.Time
+	// body of the request
+	body []byte
+}
+
+// decode unmarshals the json request body into payload
+func (c *call) decode(payload interface{}) *apiError {
+	if err := json.Unmarshal(c.body, payload); err != nil {
+		return inputError("invalid json request body: %v", err)
+	}
+	return nil
+}
+
+// contentURL returns the url that the content of the named artifact is
+// uploaded to and downloaded from
+func (c *call) contentURL(taskID string, runID int64, name string) string {
+	scheme := "http"
+	if c.req.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.req.Host + contentPath + "/" + url.PathEscape(taskID) + "/" + strconv.FormatInt(runID, 10) + "/" + url.PathEscape(name)
+}
+
+type endpoint struct {
+	method string
+	// path segments, where "<x>" matches any segment and stores it in
+	// call.params["x"]
+	route  []string
+	handle func(q *Queue, c *call) (interface{}, *apiError)
+}
+
+var endpoints = []endpoint{}
+
+func init() {
+	for _, e := range []struct {
+		method string
+		route  string
+		handle func(q *Queue, c *call) (interface{}, *apiError)
+	}{
+		{"GET", apiPath + "/ping", (*Queue).ping},
+		{"GET", apiPath + "/task/<taskId>", (*Queue).task},
+		{"GET", apiPath + "/task/<taskId>/status", (*Queue).status},
+		{"GET", apiPath + "/task-group/<taskGroupId>/list", (*Queue).listTaskGroup},
+		{"GET", apiPath + "/task/<taskId>/dependents", (*Queue).listDependentTasks},
+		{"PUT", apiPath + "/task/<taskId>", (*Queue).createTask},
+		{"POST", apiPath + "/task/<taskId>/define", (*Queue).defineTask},
+		{"POST", apiPath + "/task/<taskId>/schedule", (*Queue).scheduleTask},
+		{"POST", apiPath + "/task/<taskId>/rerun", (*Queue).rerunTask},
+		{"POST", apiPath + "/task/<taskId>/cancel", (*Queue).cancelTask},
+		{"POST", apiPath + "/claim-work/<provisionerId>/<workerType>", (*Queue).claimWork},
+		{"POST", apiPath + "/task/<taskId>/runs/<runId>/claim", (*Queue).claimTask},
+		{"POST", apiPath + "/task/<taskId>/runs/<runId>/reclaim", (*Queue).reclaimTask},
+		{"POST", apiPath + "/task/<taskId>/runs/<runId>/completed", (*Queue).reportCompleted},
+		{"POST", apiPath + "/task/<taskId>/runs/<runId>/failed", (*Queue).reportFailed},
+		{"POST", apiPath + "/task/<taskId>/runs/<runId>/exception", (*Queue).reportException},
+		{"POST", apiPath + "/task/<taskId>/runs/<runId>/artifacts/<name>", (*Queue).createArtifact},
+		{"PUT", apiPath + "/task/<taskId>/runs/<runId>/artifacts/<name>", (*Queue).completeArtifact},
+		{"GET", apiPath + "/task/<taskId>/runs/<runId>/artifacts/<name>", (*Queue).getArtifact},
+		{"GET", apiPath + "/task/<taskId>/artifacts/<name>", (*Queue).getLatestArtifact},
+		{"GET", apiPath + "/task/<taskId>/runs/<runId>/artifacts", (*Queue).listArtifacts},
+		{"GET", apiPath + "/task/<taskId>/artifacts", (*Queue).listLatestArtifacts},
+		{"GET", apiPath + "/pending/<provisionerId>/<workerType>", (*Queue).pendingTasks},
+		{"PUT", contentPath + "/<taskId>/<runId>/<name>", (*Queue).uploadContent},
+		{"GET", contentPath + "/<taskId>/<runId>/<name>", (*Queue).downloadContent},
+	} {
+		endpoints = append(endpoints, endpoint{
+			method: e.method,
+			route:  strings.Split(e.route, "/"),
+			handle: e.handle,
+		})
+	}
+}
+
+// match returns the endpoint that serves the given method and (escaped)
+// path, and the path parameters
+func match(method, path string) (*endpoint, map[string]string) {
+	segments := strings.Split(path, "/")
+	for i := range endpoints {
+		e := &endpoints[i]
+		if e.method != method || len(e.route) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for j, s := range e.route {
+			if strings.HasPrefix(s, "<") {
+				value, err := url.PathUnescape(segments[j])
+				if err != nil {
+					matched = false
+					break
+				}
+				params[s[1:len(s)-1]] = value
+			} else if s != segments[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return e, params
+		}
+	}
+	return nil, nil
+}
+
+// ServeHTTP serves the queue API.
+func (q *Queue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e, params := match(r.Method, r.URL.EscapedPath())
+	if e == nil {
+		writeJSON(w, http.StatusNotFound, notFound("fakequeue does not implement %v %v", r.Method, r.URL.Path))
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, inputError("could not read request body: %v", err))
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c := &call{
+		req:    r,
+		params: params,
+		now:    q.Clock(),
+		body:   body,
+	}
+	q.expire(c.now)
+	result, apiErr := e.handle(q, c)
+	switch {
+	case apiErr != nil:
+		writeJSON(w, apiErr.statusCode, apiErr)
+	case result == nil:
+		writeJSON(w, http.StatusOK, struct{}{})
+	default:
+		switch x := result.(type) {
+		case redirect:
+			http.Redirect(w, r, string(x), http.StatusSeeOther)
+		case *content:
+			x.write(w)
+		default:
+			writeJSON(w, http.StatusOK, x)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		statusCode = http.StatusInternalServerError
+		data, _ = json.Marshal(errorf(statusCode, "InternalServerError", "could not marshal response: %v", err))
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(data)
+}
+
+func (q *Queue) ping(c *call) (interface{}, *apiError) {
+	return nil, nil
+}
+
+// page returns the offset and length of the page of n items selected by the
+// continuationToken and limit query string parameters, and the continuation
+// token of the next page, if any
+func (q *Queue) page(c *call, n int) (offset, length int, next string, err *apiError) {
+	query := c.req.URL.Query()
+	if token := query.Get("continuationToken"); token != "" {
+		var convErr error
+		offset, convErr = strconv.Atoi(token)
+		if convErr != nil || offset < 0 || offset > n {
+			return 0, 0, "", inputError("invalid continuationToken %q", token)
+		}
+	}
+	length = q.MaxPageSize
+	if length < 1 {
+		length = 1000
+	}
+	if limit := query.Get("limit"); limit != "" {
+		l, convErr := strconv.Atoi(limit)
+		if convErr != nil || l < 1 {
+			return 0, 0, "", inputError("invalid limit %q", limit)
+		}
+		if l < length {
+			length = l
+		}
+	}
+	if offset+length < n {
+		next = strconv.Itoa(offset + length)
+	} else {
+		length = n - offset
+	}
+	return offset, length, next, nil
+}
@@ -0,0 +1,437 @@
+package fakequeue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/tcqueue"
+)
+
+// fakeClock is a clock that only moves when advanced
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// setup returns a fake queue with a fake clock, a client for it, and a
+// function to shut it down
+func setup() (*Queue, *fakeClock, *tcqueue.Queue, func()) {
+	clock := &fakeClock{now: time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)}
+	fake := New()
+	fake.Clock = clock.Now
+	server := httptest.NewServer(fake)
+	return fake, clock, tcqueue.New(nil, server.URL), server.Close
+}
+
+// taskDef returns a task definition with the given dependencies
+func taskDef(clock *fakeClock, requires tcqueue.Requires, dependencies ...string) *tcqueue.TaskDefinitionRequest {
+	return &tcqueue.TaskDefinitionRequest{
+		Created:       tcclient.Time(clock.Now()),
+		Deadline:      tcclient.Time(clock.Now().Add(time.Hour)),
+		Dependencies:  dependencies,
+		Metadata:      tcqueue.TaskMetadata{Name: "test", Owner: "test@example.com"},
+		Payload:       json.RawMessage(`{}`),
+		ProvisionerID: "test-provisioner",
+		Requires:      requires,
+		WorkerType:    "test-worker",
+	}
+}
+
+func createTask(t *testing.T, queue *tcqueue.Queue, taskID string, def *tcqueue.TaskDefinitionRequest) *tcqueue.TaskStatusStructure {
+	tsr, err := queue.CreateTask(taskID, def)
+	if err != nil {
+		t.Fatalf("Could not create task %v: %v", taskID, err)
+	}
+	return &tsr.Status
+}
+
+func claimWork(t *testing.T, queue *tcqueue.Queue, tasks int64) []tcqueue.TaskClaim {
+	resp, err := queue.ClaimWork("test-provisioner", "test-worker", &tcqueue.ClaimWorkRequest{
+		Tasks:       tasks,
+		WorkerGroup: "test-group",
+		WorkerID:    "test-worker-1",
+	})
+	if err != nil {
+		t.Fatalf("Could not claim work: %v", err)
+	}
+	return resp.Tasks
+}
+
+func state(t *testing.T, queue *tcqueue.Queue, taskID string) tcqueue.TaskStatusStructureState {
+	tsr, err := queue.Status(taskID)
+	if err != nil {
+		t.Fatalf("Could not get status of task %v: %v", taskID, err)
+	}
+	return tsr.Status.State
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	if s := createTask(t, queue, "build", taskDef(clock, "")); s.State != "pending" || len(s.Runs) != 1 || s.Runs[0].ReasonCreated != "scheduled" {
+		t.Fatalf("Expected new task to be pending, but got %#v", s)
+	}
+	if s := createTask(t, queue, "test", taskDef(clock, "", "build")); s.State != "unscheduled" {
+		t.Fatalf("Expected dependent task to be unscheduled, but got %v", s.State)
+	}
+	claims := claimWork(t, queue, 32)
+	if len(claims) != 1 || claims[0].Status.TaskID != "build" || claims[0].Status.State != "running" || claims[0].Credentials.ClientID == "" {
+		t.Fatalf("Expected to claim task build, but got %#v", claims)
+	}
+	if _, err := queue.ReportCompleted("build", "0"); err != nil {
+		t.Fatalf("Could not report task completed: %v", err)
+	}
+	// reporting the same resolution again is not an error
+	if _, err := queue.ReportCompleted("build", "0"); err != nil {
+		t.Fatalf("Could not report task completed again: %v", err)
+	}
+	if _, err := queue.ReportFailed("build", "0"); !errors.Is(err, tcclient.ErrConflict) {
+		t.Errorf("Expected a conflict reporting a completed run failed, but got %v", err)
+	}
+	if s := state(t, queue, "test"); s != "pending" {
+		t.Errorf("Expected dependent task to be pending once its dependency completed, but got %v", s)
+	}
+	if _, err := queue.ReclaimTask("build", "0"); !errors.Is(err, tcclient.ErrConflict) {
+		t.Errorf("Expected a conflict reclaiming a resolved run, but got %v", err)
+	}
+	if _, err := queue.Status("unknown"); !errors.Is(err, tcclient.ErrNotFound) {
+		t.Errorf("Expected unknown task not to be found, but got %v", err)
+	}
+}
+
+func TestCreateTaskIdempotent(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	def := taskDef(clock, "")
+	createTask(t, queue, "abc", def)
+	createTask(t, queue, "abc", def)
+	def.Priority = "high"
+	if _, err := queue.CreateTask("abc", def); !errors.Is(err, tcclient.ErrConflict) {
+		t.Errorf("Expected a conflict creating a task with a different definition, but got %v", err)
+	}
+	if _, err := queue.CreateTask("def", taskDef(clock, "", "missing")); !errors.Is(err, tcclient.ErrBadRequest) {
+		t.Errorf("Expected an error creating a task with a missing dependency, but got %v", err)
+	}
+	def = taskDef(clock, "")
+	def.Deadline = tcclient.Time(clock.Now().Add(-time.Minute))
+	if _, err := queue.CreateTask("ghi", def); !errors.Is(err, tcclient.ErrBadRequest) {
+		t.Errorf("Expected an error creating a task with a deadline in the past, but got %v", err)
+	}
+}
+
+func TestRequires(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	createTask(t, queue, "build", taskDef(clock, ""))
+	createTask(t, queue, "notify", taskDef(clock, "all-resolved", "build"))
+	createTask(t, queue, "deploy", taskDef(clock, "all-completed", "build"))
+	claimWork(t, queue, 1)
+	if _, err := queue.ReportFailed("build", "0"); err != nil {
+		t.Fatalf("Could not report task failed: %v", err)
+	}
+	if s := state(t, queue, "notify"); s != "pending" {
+		t.Errorf("Expected all-resolved dependent of a failed task to be pending, but got %v", s)
+	}
+	if s := state(t, queue, "deploy"); s != "unscheduled" {
+		t.Errorf("Expected all-completed dependent of a failed task to be unscheduled, but got %v", s)
+	}
+
+	// the deploy task is resolved once its deadline passes
+	clock.Advance(2 * time.Hour)
+	tsr, err := queue.Status("deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := tsr.Status; s.State != "exception" || len(s.Runs) != 1 || s.Runs[0].ReasonResolved != "deadline-exceeded" {
+		t.Errorf("Expected deploy task to have exceeded its deadline, but got %#v", s)
+	}
+}
+
+func TestClaimExpiry(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	def := taskDef(clock, "")
+	def.Retries = 1
+	createTask(t, queue, "abc", def)
+	claimWork(t, queue, 1)
+	clock.Advance(15 * time.Minute)
+	if _, err := queue.ReclaimTask("abc", "0"); err != nil {
+		t.Fatalf("Could not reclaim task: %v", err)
+	}
+	clock.Advance(21 * time.Minute)
+	tsr, err := queue.Status("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := tsr.Status
+	if s.State != "pending" || len(s.Runs) != 2 || s.Runs[0].ReasonResolved != "claim-expired" || s.Runs[1].ReasonCreated != "retry" || s.RetriesLeft != 0 {
+		t.Fatalf("Expected claim to have expired and task to be retried, but got %#v", s)
+	}
+	if _, err := queue.ReportCompleted("abc", "0"); !errors.Is(err, tcclient.ErrConflict) {
+		t.Errorf("Expected a conflict resolving a run whose claim expired, but got %v", err)
+	}
+
+	// no retries are left, so a further expired claim resolves the task
+	claimWork(t, queue, 1)
+	clock.Advance(21 * time.Minute)
+	if s := state(t, queue, "abc"); s != "exception" {
+		t.Errorf("Expected task to be resolved as exception, but got %v", s)
+	}
+}
+
+func TestExceptionRetriesAndRerun(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	createTask(t, queue, "abc", taskDef(clock, ""))
+	claimWork(t, queue, 1)
+	tsr, err := queue.ReportException("abc", "0", &tcqueue.TaskExceptionRequest{Reason: "worker-shutdown"})
+	if err != nil {
+		t.Fatalf("Could not report exception: %v", err)
+	}
+	if s := tsr.Status; s.State != "pending" || len(s.Runs) != 2 || s.Runs[1].ReasonCreated != "retry" {
+		t.Errorf("Expected worker-shutdown to be retried, but got %#v", s)
+	}
+	claimWork(t, queue, 1)
+	tsr, err = queue.ReportException("abc", "1", &tcqueue.TaskExceptionRequest{Reason: "malformed-payload"})
+	if err != nil {
+		t.Fatalf("Could not report exception: %v", err)
+	}
+	if s := tsr.Status; s.State != "exception" || len(s.Runs) != 2 {
+		t.Errorf("Expected malformed-payload not to be retried, but got %#v", s)
+	}
+	tsr, err = queue.RerunTask("abc")
+	if err != nil {
+		t.Fatalf("Could not rerun task: %v", err)
+	}
+	if s := tsr.Status; s.State != "pending" || len(s.Runs) != 3 || s.Runs[2].ReasonCreated != "rerun" {
+		t.Errorf("Expected task to be rerun, but got %#v", s)
+	}
+	tsr, err = queue.CancelTask("abc")
+	if err != nil {
+		t.Fatalf("Could not cancel task: %v", err)
+	}
+	if s := tsr.Status; s.State != "exception" || s.Runs[2].ReasonResolved != "canceled" {
+		t.Errorf("Expected task to be canceled, but got %#v", s)
+	}
+}
+
+func TestInvalidExceptionReason(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	createTask(t, queue, "abc", taskDef(clock, ""))
+	claimWork(t, queue, 1)
+	reason := tcqueue.TaskExceptionRequestReason("worker-shutdwon")
+	if reason.Valid() {
+		t.Errorf("Expected %q not to be a valid exception reason", reason)
+	}
+	// the request should fail when it is marshaled, before it is sent
+	_, err := queue.ReportException("abc", "0", &tcqueue.TaskExceptionRequest{Reason: reason})
+	apiErr, ok := err.(*tcclient.APICallException)
+	if !ok {
+		t.Fatalf("Expected an *APICallException, but got %v", err)
+	}
+	if _, ok := apiErr.RootCause.(*json.MarshalerError); !ok {
+		t.Errorf("Expected invalid exception reason to be rejected when marshaled, but got %v", apiErr.RootCause)
+	}
+	if s := state(t, queue, "abc"); s != tcqueue.TaskStatusStructureStateRunning {
+		t.Errorf("Expected task to still be running, but got %v", s)
+	}
+}
+
+func TestClaimWorkPriority(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	for i, priority := range []tcqueue.Priority{"low", "highest", "", "low"} {
+		def := taskDef(clock, "")
+		def.Priority = priority
+		createTask(t, queue, "task"+strconv.Itoa(i), def)
+	}
+	pending, err := queue.PendingTasks("test-provisioner", "test-worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.PendingTasks != 4 {
+		t.Errorf("Expected 4 pending tasks, but got %v", pending.PendingTasks)
+	}
+	claims := claimWork(t, queue, 3)
+	got := []string{}
+	for _, claim := range claims {
+		got = append(got, claim.Status.TaskID)
+	}
+	if len(got) != 3 || got[0] != "task1" || got[1] != "task0" || got[2] != "task3" {
+		t.Errorf("Expected tasks to be claimed in priority order, but got %v", got)
+	}
+}
+
+func TestListTaskGroupPagination(t *testing.T) {
+	fake, clock, queue, teardown := setup()
+	defer teardown()
+	fake.MaxPageSize = 2
+	for i := 0; i < 5; i++ {
+		def := taskDef(clock, "")
+		def.TaskGroupID = "group"
+		createTask(t, queue, "task"+strconv.Itoa(i), def)
+	}
+	page, err := queue.ListTaskGroup("group", "", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Tasks) != 2 || page.ContinuationToken == "" {
+		t.Errorf("Expected a page of 2 tasks and a continuation token, but got %v tasks and token %q", len(page.Tasks), page.ContinuationToken)
+	}
+	it := queue.ListTaskGroupAll(context.Background(), "group")
+	taskIDs := []string{}
+	for it.Next() {
+		taskIDs = append(taskIDs, it.Item().Status.TaskID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(taskIDs) != 5 || taskIDs[0] != "task0" || taskIDs[4] != "task4" || it.Pages() != 3 {
+		t.Errorf("Expected all 5 tasks in 3 pages, but got %v in %v pages", taskIDs, it.Pages())
+	}
+	createTask(t, queue, "dependent", taskDef(clock, "", "task0"))
+	dependents, err := queue.ListDependentTasks("task0", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dependents.Tasks) != 1 || dependents.Tasks[0].Status.TaskID != "dependent" {
+		t.Errorf("Expected one dependent task, but got %#v", dependents.Tasks)
+	}
+}
+
+func TestArtifacts(t *testing.T) {
+	_, clock, queue, teardown := setup()
+	defer teardown()
+	createTask(t, queue, "abc", taskDef(clock, ""))
+	claimWork(t, queue, 1)
+	expires := tcclient.Time(clock.Now().Add(time.Hour))
+
+	createArtifact := func(name string, request interface{}, response interface{}) {
+		data, err := json.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		par := tcqueue.PostArtifactRequest(data)
+		resp, err := queue.CreateArtifact("abc", "0", name, &par)
+		if err != nil {
+			t.Fatalf("Could not create artifact %v: %v", name, err)
+		}
+		if response != nil {
+			if err := json.Unmarshal(*resp, response); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	s3 := new(tcqueue.S3ArtifactResponse)
+	createArtifact("public/logs/live.log", &tcqueue.S3ArtifactRequest{ContentType: "text/plain", Expires: expires, StorageType: "s3"}, s3)
+	req, err := http.NewRequest("PUT", s3.PutURL, bytes.NewBufferString("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Could not upload artifact: %v", resp.Status)
+	}
+
+	blob := new(tcqueue.BlobArtifactResponse)
+	createArtifact("public/build.tar", &tcqueue.BlobArtifactRequest{ContentType: "application/x-tar", Expires: expires, Parts: make([]tcqueue.MultipartPart, 2), StorageType: "blob"}, blob)
+	etags := []string{}
+	for i, part := range []string{"part one, ", "part two"} {
+		req, err := http.NewRequest(string(blob.Requests[i].Method), blob.Requests[i].URL, bytes.NewBufferString(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		etags = append(etags, resp.Header.Get("ETag"))
+	}
+	if err := queue.CompleteArtifact("abc", "0", "public/build.tar", &tcqueue.CompleteArtifactRequest{Etags: etags}); err != nil {
+		t.Fatalf("Could not complete blob artifact: %v", err)
+	}
+
+	createArtifact("public/docs", &tcqueue.RedirectArtifactRequest{Expires: expires, StorageType: "reference", URL: "https://docs.example.com/"}, nil)
+	createArtifact("public/missing", &tcqueue.ErrorArtifactRequest{Expires: expires, Message: "not found", Reason: "file-missing-on-worker", StorageType: "error"}, nil)
+
+	// download via the redirect from the queue
+	for name, expected := range map[string]string{"public%2Flogs%2Flive.log": "hello world", "public%2Fbuild.tar": "part one, part two"} {
+		resp, err = http.Get(queue.BaseURL + "/task/abc/artifacts/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != expected {
+			t.Errorf("Unexpected download of artifact %v: %v %q", name, resp.Status, body)
+		}
+	}
+
+	noRedirects := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err = noRedirects.Get(queue.BaseURL + "/task/abc/runs/0/artifacts/public%2Fdocs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "https://docs.example.com/" {
+		t.Errorf("Expected redirect to reference artifact url, but got %v %v", resp.Status, resp.Header.Get("Location"))
+	}
+	if err := queue.GetArtifact("abc", "0", "public/missing"); err == nil {
+		t.Error("Expected an error getting an error artifact")
+	}
+
+	list, err := queue.ListArtifacts("abc", "0", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, a := range list.Artifacts {
+		names = append(names, a.Name)
+	}
+	if len(names) != 4 || names[0] != "public/build.tar" || names[1] != "public/docs" || names[2] != "public/logs/live.log" || names[3] != "public/missing" {
+		t.Errorf("Unexpected artifacts %v", names)
+	}
+
+	if _, err := queue.ReportCompleted("abc", "0"); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour - time.Minute)
+	data, _ := json.Marshal(&tcqueue.S3ArtifactRequest{ContentType: "text/plain", Expires: expires, StorageType: "s3"})
+	par := tcqueue.PostArtifactRequest(data)
+	if _, err := queue.CreateArtifact("abc", "0", "late.log", &par); !errors.Is(err, tcclient.ErrConflict) {
+		t.Errorf("Expected a conflict creating an artifact long after the run was resolved, but got %v", err)
+	}
+}
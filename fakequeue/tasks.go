@@ -0,0 +1,620 @@
+package fakequeue
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/tcqueue"
+)
+
+// priorities ranks the task priorities, highest first. The deprecated
+// "normal" priority is equivalent to "lowest".
+var priorities = map[tcqueue.Priority]int{
+	tcqueue.PriorityHighest:  0,
+	tcqueue.PriorityVeryHigh: 1,
+	tcqueue.PriorityHigh:     2,
+	tcqueue.PriorityMedium:   3,
+	tcqueue.PriorityLow:      4,
+	tcqueue.PriorityVeryLow:  5,
+	tcqueue.PriorityLowest:   6,
+	tcqueue.PriorityNormal:   6,
+}
+
+// maxRuns is the maximum number of runs a task may have, as in the real
+// queue
+const maxRuns = 50
+
+// task is a task known to the fake queue
+type task struct {
+	definition tcqueue.TaskDefinitionResponse
+	status     tcqueue.TaskStatusStructure
+	// true if the task was created with DefineTask, so is only scheduled
+	// by ScheduleTask, never because its dependencies are resolved
+	defined bool
+	// order in which the task became pending, see Queue.pendingSeq
+	pendingSeq int
+	// artifacts of each run, keyed by run id and artifact name
+	artifacts map[int64]map[string]*artifact
+}
+
+// resolved reports whether state is a resolved state
+func resolved(state tcqueue.TaskStatusStructureState) bool {
+	return state == "completed" || state == "failed" || state == "exception"
+}
+
+// lastRun returns the most recent run of t, or nil if t has no runs
+func (t *task) lastRun() *tcqueue.RunInformation {
+	if len(t.status.Runs) == 0 {
+		return nil
+	}
+	return &t.status.Runs[len(t.status.Runs)-1]
+}
+
+// updateState sets the state of t from the state of its most recent run
+func (t *task) updateState() {
+	if run := t.lastRun(); run != nil {
+		t.status.State = tcqueue.TaskStatusStructureState(run.State)
+	} else {
+		t.status.State = "unscheduled"
+	}
+}
+
+// getTask returns the task with the taskId path parameter
+func (q *Queue) getTask(c *call) (*task, *apiError) {
+	t := q.tasks[c.params["taskId"]]
+	if t == nil {
+		return nil, notFound("task %v not found", c.params["taskId"])
+	}
+	return t, nil
+}
+
+// getRun returns the task and run with the taskId and runId path parameters
+func (q *Queue) getRun(c *call) (*task, *tcqueue.RunInformation, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	runID, convErr := strconv.ParseInt(c.params["runId"], 10, 64)
+	if convErr != nil || runID < 0 || runID >= int64(len(t.status.Runs)) {
+		return nil, nil, notFound("task %v has no run %v", c.params["taskId"], c.params["runId"])
+	}
+	return t, &t.status.Runs[runID], nil
+}
+
+func (q *Queue) task(c *call) (interface{}, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, err
+	}
+	return &t.definition, nil
+}
+
+func (q *Queue) status(c *call) (interface{}, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, err
+	}
+	return &tcqueue.TaskStatusResponse{Status: t.status}, nil
+}
+
+func (q *Queue) listTaskGroup(c *call) (interface{}, *apiError) {
+	taskGroupID := c.params["taskGroupId"]
+	tasks := q.listTasks(func(t *task) bool {
+		return t.definition.TaskGroupID == taskGroupID
+	})
+	if len(tasks) == 0 {
+		return nil, notFound("task group %v not found", taskGroupID)
+	}
+	offset, length, next, err := q.page(c, len(tasks))
+	if err != nil {
+		return nil, err
+	}
+	return &tcqueue.ListTaskGroupResponse{
+		ContinuationToken: next,
+		TaskGroupID:       taskGroupID,
+		Tasks:             tasks[offset : offset+length],
+	}, nil
+}
+
+func (q *Queue) listDependentTasks(c *call) (interface{}, *apiError) {
+	taskID := c.params["taskId"]
+	if _, err := q.getTask(c); err != nil {
+		return nil, err
+	}
+	tasks := q.listTasks(func(t *task) bool {
+		return stringInSlice(taskID, t.definition.Dependencies)
+	})
+	offset, length, next, err := q.page(c, len(tasks))
+	if err != nil {
+		return nil, err
+	}
+	return &tcqueue.ListDependentTasksResponse{
+		ContinuationToken: next,
+		TaskID:            taskID,
+		Tasks:             tasks[offset : offset+length],
+	}, nil
+}
+
+// listTasks returns the tasks selected by filter, in order of creation
+func (q *Queue) listTasks(filter func(t *task) bool) []tcqueue.TaskDefinitionAndStatus {
+	tasks := []tcqueue.TaskDefinitionAndStatus{}
+	for _, taskID := range q.taskIDs {
+		if t := q.tasks[taskID]; filter(t) {
+			tasks = append(tasks, tcqueue.TaskDefinitionAndStatus{
+				Status: t.status,
+				Task:   t.definition,
+			})
+		}
+	}
+	return tasks
+}
+
+func (q *Queue) createTask(c *call) (interface{}, *apiError) {
+	return q.addTask(c, false)
+}
+
+func (q *Queue) defineTask(c *call) (interface{}, *apiError) {
+	return q.addTask(c, true)
+}
+
+// addTask creates the task with the taskId path parameter. As in the real
+// queue, creating a task that already exists with the same definition
+// succeeds, but with a different definition is a conflict.
+func (q *Queue) addTask(c *call, defined bool) (interface{}, *apiError) {
+	taskID := c.params["taskId"]
+	payload := new(tcqueue.TaskDefinitionRequest)
+	if err := c.decode(payload); err != nil {
+		return nil, err
+	}
+	definition, err := q.taskDefinition(c, taskID, payload)
+	if err != nil {
+		return nil, err
+	}
+	if existing := q.tasks[taskID]; existing != nil {
+		if existing.defined != defined || !reflect.DeepEqual(existing.definition, *definition) {
+			return nil, conflict("task %v already exists with a different definition", taskID)
+		}
+		return &tcqueue.TaskStatusResponse{Status: existing.status}, nil
+	}
+	for _, t := range q.tasks {
+		if t.definition.TaskGroupID == definition.TaskGroupID && t.definition.SchedulerID != definition.SchedulerID {
+			return nil, conflict("task group %v has schedulerId %v, not %v", definition.TaskGroupID, t.definition.SchedulerID, definition.SchedulerID)
+		}
+	}
+
+	t := &task{
+		definition: *definition,
+		status: tcqueue.TaskStatusStructure{
+			Deadline:      definition.Deadline,
+			Expires:       *definition.Expires,
+			ProvisionerID: definition.ProvisionerID,
+			RetriesLeft:   definition.Retries,
+			Runs:          []tcqueue.RunInformation{},
+			SchedulerID:   definition.SchedulerID,
+			State:         "unscheduled",
+			TaskGroupID:   definition.TaskGroupID,
+			TaskID:        taskID,
+			WorkerType:    definition.WorkerType,
+		},
+		defined:   defined,
+		artifacts: map[int64]map[string]*artifact{},
+	}
+	q.tasks[taskID] = t
+	q.taskIDs = append(q.taskIDs, taskID)
+	if !defined && q.dependenciesSatisfied(t) {
+		q.addRun(t, c.now, "scheduled")
+	}
+	return &tcqueue.TaskStatusResponse{Status: t.status}, nil
+}
+
+// taskDefinition validates payload, and returns the task definition it
+// describes, with defaults applied
+func (q *Queue) taskDefinition(c *call, taskID string, payload *tcqueue.TaskDefinitionRequest) (*tcqueue.TaskDefinitionResponse, *apiError) {
+	if payload.ProvisionerID == "" || payload.WorkerType == "" {
+		return nil, inputError("provisionerId and workerType are required")
+	}
+	deadline := payload.Deadline.Time()
+	if !deadline.After(c.now) {
+		return nil, inputError("deadline %v is in the past", payload.Deadline)
+	}
+	if deadline.After(payload.Created.Time().Add(5 * 24 * time.Hour)) {
+		return nil, inputError("deadline %v is more than 5 days after created %v", payload.Deadline, payload.Created)
+	}
+	definition := &tcqueue.TaskDefinitionResponse{
+		Created:       payload.Created,
+		Deadline:      payload.Deadline,
+		Dependencies:  payload.Dependencies,
+		Expires:       payload.Expires,
+		Extra:         payload.Extra,
+		Metadata:      payload.Metadata,
+		Payload:       payload.Payload,
+		Priority:      payload.Priority,
+		ProvisionerID: payload.ProvisionerID,
+		Requires:      payload.Requires,
+		Retries:       payload.Retries,
+		Routes:        payload.Routes,
+		SchedulerID:   payload.SchedulerID,
+		Scopes:        payload.Scopes,
+		Tags:          payload.Tags,
+		TaskGroupID:   payload.TaskGroupID,
+		WorkerType:    payload.WorkerType,
+	}
+	if definition.Expires == nil {
+		expires := tcclient.Time(deadline.AddDate(1, 0, 0))
+		definition.Expires = &expires
+	}
+	if definition.Expires.Before(definition.Deadline) {
+		return nil, inputError("expires %v is before deadline %v", definition.Expires, definition.Deadline)
+	}
+	if definition.Dependencies == nil {
+		definition.Dependencies = []string{}
+	}
+	for _, dependency := range definition.Dependencies {
+		if dependency != taskID && q.tasks[dependency] == nil {
+			return nil, inputError("dependency %v does not exist", dependency)
+		}
+	}
+	if len(definition.Extra) == 0 {
+		definition.Extra = json.RawMessage("{}")
+	}
+	if definition.Priority == "" {
+		definition.Priority = "lowest"
+	}
+	if _, ok := priorities[definition.Priority]; !ok {
+		return nil, inputError("invalid priority %q", definition.Priority)
+	}
+	switch definition.Requires {
+	case "":
+		definition.Requires = "all-completed"
+	case "all-completed", "all-resolved":
+	default:
+		return nil, inputError("invalid requires %q", definition.Requires)
+	}
+	// retries defaults to 5, but may explicitly be 0
+	fields := map[string]json.RawMessage{}
+	// the body has already been decoded, so this cannot fail
+	_ = json.Unmarshal(c.body, &fields)
+	if fields["retries"] == nil {
+		definition.Retries = 5
+	}
+	if definition.Retries < 0 || definition.Retries > 49 {
+		return nil, inputError("retries must be between 0 and 49, but is %v", definition.Retries)
+	}
+	if definition.Routes == nil {
+		definition.Routes = []string{}
+	}
+	if definition.SchedulerID == "" {
+		definition.SchedulerID = "-"
+	}
+	if definition.Scopes == nil {
+		definition.Scopes = []string{}
+	}
+	if definition.Tags == nil {
+		definition.Tags = map[string]string{}
+	}
+	if definition.TaskGroupID == "" {
+		definition.TaskGroupID = taskID
+	}
+	return definition, nil
+}
+
+// dependenciesSatisfied reports whether the dependencies of t are resolved
+// as its requires property demands. A task that depends on itself is only
+// scheduled by ScheduleTask.
+func (q *Queue) dependenciesSatisfied(t *task) bool {
+	for _, dependency := range t.definition.Dependencies {
+		state := q.tasks[dependency].status.State
+		if t.definition.Requires == "all-resolved" && !resolved(state) ||
+			t.definition.Requires == "all-completed" && state != "completed" {
+			return false
+		}
+	}
+	return true
+}
+
+// addRun adds a pending run to t
+func (q *Queue) addRun(t *task, now time.Time, reasonCreated tcqueue.ReasonCreated) {
+	t.status.Runs = append(t.status.Runs, tcqueue.RunInformation{
+		ReasonCreated: reasonCreated,
+		RunID:         int64(len(t.status.Runs)),
+		Scheduled:     tcclient.Time(now),
+		State:         "pending",
+	})
+	q.pendingSeq++
+	t.pendingSeq = q.pendingSeq
+	t.updateState()
+}
+
+// resolveRun resolves the most recent run of t, adding a new pending run if
+// retry is true and t has retries left, and schedules the dependents of t
+// if t is now resolved
+func (q *Queue) resolveRun(t *task, now time.Time, state tcqueue.RunInformationState, reasonResolved tcqueue.ReasonResolved, retry tcqueue.ReasonCreated) {
+	run := t.lastRun()
+	run.State = state
+	run.ReasonResolved = reasonResolved
+	run.Resolved = timePtr(now)
+	if retry != "" && t.status.RetriesLeft > 0 && len(t.status.Runs) < maxRuns {
+		t.status.RetriesLeft--
+		q.addRun(t, now, retry)
+		return
+	}
+	t.updateState()
+	q.scheduleDependents(t, now)
+}
+
+// scheduleDependents schedules the unscheduled dependents of t whose
+// dependencies are now satisfied
+func (q *Queue) scheduleDependents(t *task, now time.Time) {
+	for _, taskID := range q.taskIDs {
+		dependent := q.tasks[taskID]
+		if dependent.defined || dependent.status.State != "unscheduled" || !stringInSlice(t.status.TaskID, dependent.definition.Dependencies) {
+			continue
+		}
+		if q.dependenciesSatisfied(dependent) {
+			q.addRun(dependent, now, "scheduled")
+		}
+	}
+}
+
+// expire resolves tasks whose deadline has passed, and runs whose claim has
+// expired
+func (q *Queue) expire(now time.Time) {
+	for _, taskID := range q.taskIDs {
+		t := q.tasks[taskID]
+		if resolved(t.status.State) {
+			continue
+		}
+		if !now.Before(t.status.Deadline.Time()) {
+			deadline := t.status.Deadline.Time()
+			if t.status.State == "unscheduled" {
+				t.status.Runs = append(t.status.Runs, tcqueue.RunInformation{
+					ReasonCreated: "exception",
+					RunID:         int64(len(t.status.Runs)),
+					Scheduled:     tcclient.Time(deadline),
+					State:         "exception",
+				})
+			}
+			q.resolveRun(t, deadline, "exception", "deadline-exceeded", "")
+			continue
+		}
+		if run := t.lastRun(); run != nil && run.State == "running" && now.After(run.TakenUntil.Time()) {
+			q.resolveRun(t, run.TakenUntil.Time(), "exception", "claim-expired", "retry")
+		}
+	}
+}
+
+func (q *Queue) scheduleTask(c *call) (interface{}, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, err
+	}
+	if t.status.State == "unscheduled" {
+		q.addRun(t, c.now, "scheduled")
+	}
+	return &tcqueue.TaskStatusResponse{Status: t.status}, nil
+}
+
+func (q *Queue) rerunTask(c *call) (interface{}, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case t.status.State == "unscheduled":
+		return nil, conflict("task %v is unscheduled, so cannot be rerun", t.status.TaskID)
+	case resolved(t.status.State):
+		if len(t.status.Runs) >= maxRuns {
+			return nil, conflict("task %v already has %v runs", t.status.TaskID, maxRuns)
+		}
+		q.addRun(t, c.now, "rerun")
+	}
+	return &tcqueue.TaskStatusResponse{Status: t.status}, nil
+}
+
+func (q *Queue) cancelTask(c *call) (interface{}, *apiError) {
+	t, err := q.getTask(c)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case t.status.State == "unscheduled":
+		t.status.Runs = append(t.status.Runs, tcqueue.RunInformation{
+			ReasonCreated: "exception",
+			RunID:         int64(len(t.status.Runs)),
+			Scheduled:     tcclient.Time(c.now),
+			State:         "exception",
+		})
+		q.resolveRun(t, c.now, "exception", "canceled", "")
+	case !resolved(t.status.State):
+		q.resolveRun(t, c.now, "exception", "canceled", "")
+	}
+	return &tcqueue.TaskStatusResponse{Status: t.status}, nil
+}
+
+func (q *Queue) claimWork(c *call) (interface{}, *apiError) {
+	payload := new(tcqueue.ClaimWorkRequest)
+	if err := c.decode(payload); err != nil {
+		return nil, err
+	}
+	if payload.Tasks < 1 || payload.Tasks > 32 {
+		return nil, inputError("tasks must be between 1 and 32, but is %v", payload.Tasks)
+	}
+	pending := q.pendingTasksFor(c.params["provisionerId"], c.params["workerType"])
+	if int64(len(pending)) > payload.Tasks {
+		pending = pending[:payload.Tasks]
+	}
+	response := &tcqueue.ClaimWorkResponse{
+		Tasks: []tcqueue.TaskClaim{},
+	}
+	for _, t := range pending {
+		claim := q.claim(t, c.now, payload.WorkerGroup, payload.WorkerID)
+		response.Tasks = append(response.Tasks, tcqueue.TaskClaim{
+			Credentials: claim.Credentials,
+			RunID:       claim.RunID,
+			Status:      claim.Status,
+			TakenUntil:  claim.TakenUntil,
+			Task:        claim.Task,
+			WorkerGroup: claim.WorkerGroup,
+			WorkerID:    claim.WorkerID,
+		})
+	}
+	return response, nil
+}
+
+// pendingTasksFor returns the pending tasks of a worker type, in the order
+// in which they should be claimed
+func (q *Queue) pendingTasksFor(provisionerID, workerType string) []*task {
+	pending := []*task{}
+	for _, t := range q.tasks {
+		if t.status.State == "pending" && t.status.ProvisionerID == provisionerID && t.status.WorkerType == workerType {
+			pending = append(pending, t)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		pi, pj := priorities[pending[i].definition.Priority], priorities[pending[j].definition.Priority]
+		if pi != pj {
+			return pi < pj
+		}
+		return pending[i].pendingSeq < pending[j].pendingSeq
+	})
+	return pending
+}
+
+// claim claims the pending most recent run of t for a worker
+func (q *Queue) claim(t *task, now time.Time, workerGroup, workerID string) *tcqueue.TaskClaimResponse {
+	run := t.lastRun()
+	run.State = "running"
+	run.Started = timePtr(now)
+	run.TakenUntil = timePtr(now.Add(q.ClaimTimeout))
+	run.WorkerGroup = workerGroup
+	run.WorkerID = workerID
+	t.updateState()
+	return &tcqueue.TaskClaimResponse{
+		Credentials: taskCredentials(t, run),
+		RunID:       run.RunID,
+		Status:      t.status,
+		TakenUntil:  *run.TakenUntil,
+		Task:        t.definition,
+		WorkerGroup: workerGroup,
+		WorkerID:    workerID,
+	}
+}
+
+// taskCredentials returns credentials for the worker that has claimed run,
+// named like those that the real queue issues. They are not valid
+// credentials, since the fake does not authenticate requests.
+func taskCredentials(t *task, run *tcqueue.RunInformation) tcqueue.TaskCredentials {
+	token := make([]byte, 33)
+	_, _ = rand.Read(token)
+	return tcqueue.TaskCredentials{
+		AccessToken: base64.RawURLEncoding.EncodeToString(token),
+		ClientID:    fmt.Sprintf("task-client/%v/%v/on/%v/%v/until/%v", t.status.TaskID, run.RunID, run.WorkerGroup, run.WorkerID, run.TakenUntil.Time().Unix()),
+	}
+}
+
+func (q *Queue) claimTask(c *call) (interface{}, *apiError) {
+	t, run, err := q.getRun(c)
+	if err != nil {
+		return nil, err
+	}
+	payload := new(tcqueue.TaskClaimRequest)
+	if err := c.decode(payload); err != nil {
+		return nil, err
+	}
+	if run != t.lastRun() || run.State != "pending" {
+		return nil, conflict("run %v of task %v is %v, so cannot be claimed", run.RunID, t.status.TaskID, run.State)
+	}
+	return q.claim(t, c.now, payload.WorkerGroup, payload.WorkerID), nil
+}
+
+func (q *Queue) reclaimTask(c *call) (interface{}, *apiError) {
+	t, run, err := q.getRun(c)
+	if err != nil {
+		return nil, err
+	}
+	if run.State != "running" {
+		return nil, conflict("run %v of task %v is %v, so cannot be reclaimed", run.RunID, t.status.TaskID, run.State)
+	}
+	run.TakenUntil = timePtr(c.now.Add(q.ClaimTimeout))
+	return &tcqueue.TaskReclaimResponse{
+		Credentials: taskCredentials(t, run),
+		RunID:       run.RunID,
+		Status:      t.status,
+		TakenUntil:  *run.TakenUntil,
+		WorkerGroup: run.WorkerGroup,
+		WorkerID:    run.WorkerID,
+	}, nil
+}
+
+func (q *Queue) reportCompleted(c *call) (interface{}, *apiError) {
+	return q.report(c, "completed", "completed", "")
+}
+
+func (q *Queue) reportFailed(c *call) (interface{}, *apiError) {
+	return q.report(c, "failed", "failed", "")
+}
+
+func (q *Queue) reportException(c *call) (interface{}, *apiError) {
+	payload := new(tcqueue.TaskExceptionRequest)
+	if err := c.decode(payload); err != nil {
+		return nil, err
+	}
+	reason := tcqueue.ReasonResolved(payload.Reason)
+	switch payload.Reason {
+	case tcqueue.TaskExceptionRequestReasonWorkerShutdown:
+		return q.report(c, "exception", reason, "retry")
+	case tcqueue.TaskExceptionRequestReasonIntermittentTask:
+		return q.report(c, "exception", reason, "task-retry")
+	case tcqueue.TaskExceptionRequestReasonMalformedPayload, tcqueue.TaskExceptionRequestReasonResourceUnavailable, tcqueue.TaskExceptionRequestReasonInternalError, tcqueue.TaskExceptionRequestReasonSuperseded:
+		return q.report(c, "exception", reason, "")
+	}
+	return nil, inputError("invalid exception reason %q", payload.Reason)
+}
+
+// report resolves the running run with the taskId and runId path parameters.
+// Reporting the same resolution again succeeds, as in the real queue.
+func (q *Queue) report(c *call, state tcqueue.RunInformationState, reasonResolved tcqueue.ReasonResolved, retry tcqueue.ReasonCreated) (interface{}, *apiError) {
+	t, run, err := q.getRun(c)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case run.State == state && run.ReasonResolved == reasonResolved:
+	case run.State != "running":
+		return nil, conflict("run %v of task %v is %v, so cannot be resolved as %v", run.RunID, t.status.TaskID, run.State, reasonResolved)
+	default:
+		q.resolveRun(t, c.now, state, reasonResolved, retry)
+	}
+	return &tcqueue.TaskStatusResponse{Status: t.status}, nil
+}
+
+func (q *Queue) pendingTasks(c *call) (interface{}, *apiError) {
+	provisionerID, workerType := c.params["provisionerId"], c.params["workerType"]
+	return &tcqueue.CountPendingTasksResponse{
+		PendingTasks:  int64(len(q.pendingTasksFor(provisionerID, workerType))),
+		ProvisionerID: provisionerID,
+		WorkerType:    workerType,
+	}, nil
+}
+
+func stringInSlice(s string, slice []string) bool {
+	for _, x := range slice {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// timePtr returns a pointer to t as a tcclient.Time, for optional properties
+func timePtr(t time.Time) *tcclient.Time {
+	ct := tcclient.Time(t)
+	return &ct
+}
@@ -0,0 +1,396 @@
+// Package hawkauth provides http.Handler middleware for Go HTTP servers that
+// accept Taskcluster credentials. Requests signed with a Hawk Authorization
+// header, or carrying a bewit in their query string (see SignedURL methods of
+// the generated clients), are authenticated by the Taskcluster auth service
+// (see tcauth.AuthenticateHawk), and the scopes of the caller are checked
+// against a required scope expression.
+//
+// For example:
+//
+//  authenticator := hawkauth.New(tcauth.New(nil, rootURL))
+//  http.Handle("/secret/", authenticator.Require(
+//  	scopes.RequiredScope("my-service:get-secret:<name>"),
+//  	func(r *http.Request) scopes.Params {
+//  		return scopes.Params{"name": strings.TrimPrefix(r.URL.Path, "/secret/")}
+//  	},
+//  )(secretHandler))
+//
+// Within secretHandler, hawkauth.IdentityFromContext(r.Context()) returns
+// the clientId and scopes of the caller.
+//
+// Payload hashes are not validated.
+package hawkauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taskcluster/taskcluster-client-go/scopes"
+	"github.com/taskcluster/taskcluster-client-go/tcauth"
+)
+
+// DefaultCacheTTL is the default time for which authentication results are
+// cached.
+const DefaultCacheTTL = 30 * time.Second
+
+// DefaultMaxCacheEntries is the default maximum number of cached
+// authentication results.
+const DefaultMaxCacheEntries = 10000
+
+// HawkAuthenticator authenticates Hawk signed requests. *tcauth.Auth
+// implements it.
+type HawkAuthenticator interface {
+	AuthenticateHawkWithContext(ctx context.Context, payload *tcauth.HawkSignatureAuthenticationRequest) (*tcauth.HawkSignatureAuthenticationResponse, error)
+}
+
+// ParamsFunc returns the parameters of a request, for expanding a required
+// scope expression.
+type ParamsFunc func(r *http.Request) scopes.Params
+
+// Identity describes the authenticated caller of a request.
+type Identity struct {
+	// The clientId that made the request. This can be used for logging and
+	// auditing, but must not be used for access control; that is what
+	// scopes are for.
+	ClientID string
+	// Scopes of the client, after role expansion
+	Scopes []string
+	// Expiry of the credentials used to make the request
+	Expires time.Time
+}
+
+// Error describes why a request was rejected. Its fields correspond to
+// those of error responses from Taskcluster services.
+type Error struct {
+	// HTTP status code of the response: 401 if authentication failed, 403
+	// if the caller has insufficient scopes, or 500 if the request could
+	// not be authenticated due to an internal error
+	StatusCode int `json:"-"`
+	// One of "AuthenticationFailed", "InsufficientScopes" or
+	// "InternalServerError"
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Scopes which the caller would need in order to be authorized, if
+	// Code is "InsufficientScopes"
+	Missing []string `json:"missing,omitempty"`
+}
+
+func (err *Error) Error() string {
+	return err.Code + ": " + err.Message
+}
+
+// Authenticator authenticates requests with the Taskcluster auth service,
+// caching the results for a short time.
+type Authenticator struct {
+	// Auth authenticates requests, typically a *tcauth.Auth client (which
+	// needs no credentials)
+	Auth HawkAuthenticator
+	// CacheTTL is the maximum time for which an authentication result is
+	// cached (successful results are never cached beyond the expiry of the
+	// credentials). If zero, DefaultCacheTTL is used; if negative, results
+	// are not cached.
+	CacheTTL time.Duration
+	// MaxCacheEntries limits the size of the cache. If zero,
+	// DefaultMaxCacheEntries is used.
+	MaxCacheEntries int
+	// If true, the X-Forwarded-Host, X-Forwarded-Port, X-Forwarded-Proto
+	// and X-Forwarded-For headers set by a reverse proxy are used to
+	// determine the host and port that the request was signed for, and the
+	// source IP of the request. Only set this if the server is behind a
+	// reverse proxy that sets these headers.
+	TrustForwardedHeaders bool
+	// ErrorHandler writes the response for rejected requests. If nil,
+	// WriteError is used.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err *Error)
+
+	mu    sync.Mutex
+	cache map[tcauth.HawkSignatureAuthenticationRequest]cacheEntry
+	// now returns the current time; overridden in tests
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	identity *Identity
+	err      *Error
+	expires  time.Time
+}
+
+// New returns an *Authenticator which authenticates requests with auth.
+func New(auth HawkAuthenticator) *Authenticator {
+	return &Authenticator{
+		Auth: auth,
+	}
+}
+
+type contextKey struct{}
+
+// IdentityFromContext returns the identity of the caller stored in ctx by
+// Authenticator.Require. The second return value is false if the request
+// was not authenticated (i.e. it carried no credentials).
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(*Identity)
+	return identity, ok
+}
+
+// WriteError writes err as a json response body with the status code
+// err.StatusCode, in the same form as Taskcluster services.
+func WriteError(w http.ResponseWriter, r *http.Request, err *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err.StatusCode == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Hawk")
+	}
+	w.WriteHeader(err.StatusCode)
+	_ = json.NewEncoder(w).Encode(err)
+}
+
+// Require returns middleware which authenticates requests, and rejects
+// those whose scopes do not satisfy required, expanded with the parameters
+// returned by params (which may be nil if required has no parameters).
+// Requests without credentials are treated as having no scopes, so they are
+// accepted if required is nil or requires no scopes. Accepted requests are
+// passed to the wrapped handler, with the identity of the caller stored in
+// the request context (see IdentityFromContext).
+func (a *Authenticator) Require(required scopes.Template, params ParamsFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, err := a.authenticate(r)
+			if err == nil {
+				err = a.authorize(r, identity, required, params)
+			}
+			if err != nil {
+				errorHandler := a.ErrorHandler
+				if errorHandler == nil {
+					errorHandler = WriteError
+				}
+				errorHandler(w, r, err)
+				return
+			}
+			if identity != nil {
+				r = r.WithContext(context.WithValue(r.Context(), contextKey{}, identity))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (a *Authenticator) authorize(r *http.Request, identity *Identity, required scopes.Template, params ParamsFunc) *Error {
+	var p scopes.Params
+	if params != nil {
+		p = params(r)
+	}
+	var have []string
+	if identity != nil {
+		have = identity.Scopes
+	}
+	result, err := scopes.Evaluate(required, p, have)
+	if err != nil {
+		return &Error{
+			StatusCode: http.StatusInternalServerError,
+			Code:       "InternalServerError",
+			Message:    "Could not evaluate required scopes: " + err.Error(),
+		}
+	}
+	if !result.Authorized {
+		return &Error{
+			StatusCode: http.StatusForbidden,
+			Code:       "InsufficientScopes",
+			Message:    "Client lacks the scopes required for this request: " + strings.Join(result.Missing, ", "),
+			Missing:    result.Missing,
+		}
+	}
+	return nil
+}
+
+// Authenticate authenticates r, returning the identity of the caller, or
+// nil if the request carries neither a Hawk Authorization header nor a
+// bewit. If authentication fails, the error is an *Error.
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	identity, err := a.authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (*Identity, *Error) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" && r.URL.Query().Get("bewit") == "" {
+		return nil, nil
+	}
+	req := a.hawkRequest(r, authorization)
+	now := a.currentTime()
+	if entry, found := a.cached(req, now); found {
+		return entry.identity, entry.err
+	}
+	response, err := a.Auth.AuthenticateHawkWithContext(r.Context(), req)
+	if err != nil {
+		return nil, &Error{
+			StatusCode: http.StatusInternalServerError,
+			Code:       "InternalServerError",
+			Message:    "Could not authenticate request: " + err.Error(),
+		}
+	}
+	entry, err := parseResponse(response)
+	if err != nil {
+		return nil, &Error{
+			StatusCode: http.StatusInternalServerError,
+			Code:       "InternalServerError",
+			Message:    "Could not interpret auth service response: " + err.Error(),
+		}
+	}
+	a.store(req, entry, now)
+	return entry.identity, entry.err
+}
+
+func parseResponse(response *tcauth.HawkSignatureAuthenticationResponse) (cacheEntry, error) {
+	var status struct {
+		Status string `json:"status"`
+	}
+	err := json.Unmarshal(*response, &status)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	switch status.Status {
+	case "auth-success":
+		var success tcauth.AuthenticationSuccessfulResponse
+		err = json.Unmarshal(*response, &success)
+		if err != nil {
+			return cacheEntry{}, err
+		}
+		return cacheEntry{
+			identity: &Identity{
+				ClientID: success.ClientID,
+				Scopes:   success.Scopes,
+				Expires:  time.Time(success.Expires),
+			},
+			expires: time.Time(success.Expires),
+		}, nil
+	case "auth-failed":
+		var failure tcauth.AuthenticationFailedResponse
+		err = json.Unmarshal(*response, &failure)
+		if err != nil {
+			return cacheEntry{}, err
+		}
+		return cacheEntry{
+			err: &Error{
+				StatusCode: http.StatusUnauthorized,
+				Code:       "AuthenticationFailed",
+				Message:    failure.Message,
+			},
+		}, nil
+	default:
+		return cacheEntry{}, fmt.Errorf("unrecognised status %q", status.Status)
+	}
+}
+
+// hawkRequest returns the request to send to the auth service to
+// authenticate r
+func (a *Authenticator) hawkRequest(r *http.Request, authorization string) *tcauth.HawkSignatureAuthenticationRequest {
+	host := r.Host
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	port := ""
+	sourceIP, _, _ := net.SplitHostPort(r.RemoteAddr)
+	if a.TrustForwardedHeaders {
+		if h := r.Header.Get("X-Forwarded-Host"); h != "" {
+			host = h
+		}
+		if s := r.Header.Get("X-Forwarded-Proto"); s != "" {
+			scheme = s
+		}
+		port = r.Header.Get("X-Forwarded-Port")
+		if f := r.Header.Get("X-Forwarded-For"); f != "" {
+			sourceIP = strings.TrimSpace(strings.Split(f, ",")[0])
+		}
+	}
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host = h
+		if port == "" {
+			port = p
+		}
+	}
+	if port == "" {
+		port = "80"
+		if scheme == "https" {
+			port = "443"
+		}
+	}
+	portNumber, _ := strconv.ParseInt(port, 10, 64)
+	if net.ParseIP(sourceIP) == nil {
+		sourceIP = ""
+	}
+	return &tcauth.HawkSignatureAuthenticationRequest{
+		Authorization: authorization,
+		Host:          host,
+		Method:        tcauth.Method(strings.ToLower(r.Method)),
+		Port:          portNumber,
+		Resource:      r.URL.RequestURI(),
+		SourceIP:      sourceIP,
+	}
+}
+
+func (a *Authenticator) currentTime() time.Time {
+	if a.now != nil {
+		return a.now()
+	}
+	return time.Now()
+}
+
+func (a *Authenticator) cached(req *tcauth.HawkSignatureAuthenticationRequest, now time.Time) (cacheEntry, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// source ip is only used for audit logging, so does not affect the result
+	key := *req
+	key.SourceIP = ""
+	entry, found := a.cache[key]
+	if !found || !now.Before(entry.expires) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (a *Authenticator) store(req *tcauth.HawkSignatureAuthenticationRequest, entry cacheEntry, now time.Time) {
+	ttl := a.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	if ttl < 0 {
+		return
+	}
+	expires := now.Add(ttl)
+	if entry.identity == nil || entry.expires.IsZero() || expires.Before(entry.expires) {
+		entry.expires = expires
+	}
+	maxEntries := a.MaxCacheEntries
+	if maxEntries == 0 {
+		maxEntries = DefaultMaxCacheEntries
+	}
+	key := *req
+	key.SourceIP = ""
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cache == nil {
+		a.cache = map[tcauth.HawkSignatureAuthenticationRequest]cacheEntry{}
+	}
+	if len(a.cache) >= maxEntries {
+		for k, e := range a.cache {
+			if !now.Before(e.expires) {
+				delete(a.cache, k)
+			}
+		}
+		// still full of unexpired entries, so start afresh
+		if len(a.cache) >= maxEntries {
+			a.cache = map[tcauth.HawkSignatureAuthenticationRequest]cacheEntry{}
+		}
+	}
+	a.cache[key] = entry
+}
@@ -0,0 +1,228 @@
+package hawkauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taskcluster/taskcluster-client-go/scopes"
+	"github.com/taskcluster/taskcluster-client-go/tcauth"
+)
+
+// fakeAuth is a HawkAuthenticator which accepts the Authorization header
+// "Hawk good", and bewit "good", and records the requests it receives
+type fakeAuth struct {
+	requests []*tcauth.HawkSignatureAuthenticationRequest
+	expires  time.Time
+	err      error
+}
+
+func (f *fakeAuth) AuthenticateHawkWithContext(ctx context.Context, payload *tcauth.HawkSignatureAuthenticationRequest) (*tcauth.HawkSignatureAuthenticationResponse, error) {
+	f.requests = append(f.requests, payload)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var response interface{}
+	if payload.Authorization == "Hawk good" || strings.Contains(payload.Resource, "bewit=good") {
+		response = map[string]interface{}{
+			"status":   "auth-success",
+			"clientId": "tester",
+			"scopes":   []string{"my-service:get-secret:public/*"},
+			"expires":  f.expires.UTC().Format(time.RFC3339Nano),
+			"scheme":   "hawk",
+		}
+	} else {
+		response = map[string]interface{}{
+			"status":  "auth-failed",
+			"message": "Bad mac",
+		}
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	r := tcauth.HawkSignatureAuthenticationResponse(data)
+	return &r, nil
+}
+
+func secretHandler(t *testing.T, a *Authenticator) http.Handler {
+	return a.Require(
+		scopes.RequiredScope("my-service:get-secret:<name>"),
+		func(r *http.Request) scopes.Params {
+			return scopes.Params{"name": strings.TrimPrefix(r.URL.Path, "/secret/")}
+		},
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := IdentityFromContext(r.Context())
+		if !ok || identity.ClientID != "tester" {
+			t.Errorf("Expected identity of tester in context, but got %#v", identity)
+		}
+		w.Write([]byte("secret"))
+	}))
+}
+
+func serve(handler http.Handler, method, target, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+func errorCode(t *testing.T, res *httptest.ResponseRecorder) string {
+	var body Error
+	err := json.Unmarshal(res.Body.Bytes(), &body)
+	if err != nil {
+		t.Fatalf("Could not parse error response %q: %v", res.Body.String(), err)
+	}
+	return body.Code
+}
+
+func TestRequire(t *testing.T) {
+	auth := &fakeAuth{expires: time.Now().Add(time.Hour)}
+	handler := secretHandler(t, New(auth))
+
+	res := serve(handler, "GET", "http://example.com/secret/public/x", "Hawk good")
+	if res.Code != 200 || res.Body.String() != "secret" {
+		t.Errorf("Expected 200 response, but got %v %q", res.Code, res.Body.String())
+	}
+	expected := &tcauth.HawkSignatureAuthenticationRequest{
+		Authorization: "Hawk good",
+		Host:          "example.com",
+		Method:        "get",
+		Port:          80,
+		Resource:      "/secret/public/x",
+		SourceIP:      "192.0.2.1",
+	}
+	if len(auth.requests) != 1 || !reflect.DeepEqual(auth.requests[0], expected) {
+		t.Errorf("Expected auth request %#v, but got %#v", expected, auth.requests)
+	}
+
+	res = serve(handler, "GET", "http://example.com/secret/private/x", "Hawk good")
+	if res.Code != 403 || errorCode(t, res) != "InsufficientScopes" {
+		t.Errorf("Expected 403 InsufficientScopes response, but got %v %q", res.Code, res.Body.String())
+	}
+
+	res = serve(handler, "GET", "http://example.com/secret/public/x", "Hawk bad")
+	if res.Code != 401 || errorCode(t, res) != "AuthenticationFailed" || res.Header().Get("WWW-Authenticate") != "Hawk" {
+		t.Errorf("Expected 401 AuthenticationFailed response, but got %v %q", res.Code, res.Body.String())
+	}
+
+	res = serve(handler, "GET", "http://example.com/secret/public/x", "")
+	if res.Code != 403 || errorCode(t, res) != "InsufficientScopes" {
+		t.Errorf("Expected 403 response for request without credentials, but got %v %q", res.Code, res.Body.String())
+	}
+
+	res = serve(handler, "GET", "https://example.com:8443/secret/public/x?a=1&bewit=good", "")
+	if res.Code != 200 {
+		t.Errorf("Expected 200 response for bewit, but got %v %q", res.Code, res.Body.String())
+	}
+	last := auth.requests[len(auth.requests)-1]
+	if last.Authorization != "" || last.Port != 8443 || last.Resource != "/secret/public/x?a=1&bewit=good" {
+		t.Errorf("Unexpected auth request for bewit: %#v", last)
+	}
+}
+
+func TestRequireNoScopes(t *testing.T) {
+	auth := &fakeAuth{}
+	called := false
+	handler := New(auth).Require(nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if _, ok := IdentityFromContext(r.Context()); ok {
+			t.Error("Expected no identity for unauthenticated request")
+		}
+	}))
+	res := serve(handler, "GET", "http://example.com/", "")
+	if res.Code != 200 || !called || len(auth.requests) != 0 {
+		t.Errorf("Expected unauthenticated request to be accepted without calling auth service, but got %v", res.Code)
+	}
+}
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	auth := &fakeAuth{expires: now.Add(time.Minute)}
+	a := New(auth)
+	a.CacheTTL = 10 * time.Second
+	a.now = func() time.Time { return now }
+	handler := secretHandler(t, a)
+
+	for i := 0; i < 3; i++ {
+		serve(handler, "GET", "http://example.com/secret/public/x", "Hawk good")
+		serve(handler, "GET", "http://example.com/secret/public/x", "Hawk bad")
+	}
+	if len(auth.requests) != 2 {
+		t.Errorf("Expected results to be cached, but auth service was called %v times", len(auth.requests))
+	}
+
+	now = now.Add(11 * time.Second)
+	serve(handler, "GET", "http://example.com/secret/public/x", "Hawk good")
+	if len(auth.requests) != 3 {
+		t.Errorf("Expected cache entry to expire after CacheTTL, but auth service was called %v times", len(auth.requests))
+	}
+
+	// never cached beyond expiry of credentials
+	auth.expires = now.Add(time.Second)
+	serve(handler, "GET", "http://example.com/secret/public/y", "Hawk good")
+	now = now.Add(2 * time.Second)
+	serve(handler, "GET", "http://example.com/secret/public/y", "Hawk good")
+	if len(auth.requests) != 5 {
+		t.Errorf("Expected cache entry to expire with credentials, but auth service was called %v times", len(auth.requests))
+	}
+
+	// disabled
+	a.CacheTTL = -1
+	serve(handler, "GET", "http://example.com/secret/public/z", "Hawk good")
+	serve(handler, "GET", "http://example.com/secret/public/z", "Hawk good")
+	if len(auth.requests) != 7 {
+		t.Errorf("Expected no caching, but auth service was called %v times", len(auth.requests))
+	}
+}
+
+func TestAuthServiceError(t *testing.T) {
+	auth := &fakeAuth{err: errors.New("connection refused")}
+	a := New(auth)
+	res := serve(secretHandler(t, a), "GET", "http://example.com/secret/public/x", "Hawk good")
+	if res.Code != 500 || errorCode(t, res) != "InternalServerError" {
+		t.Errorf("Expected 500 response, but got %v %q", res.Code, res.Body.String())
+	}
+	// errors are not cached
+	auth.err = nil
+	auth.expires = time.Now().Add(time.Hour)
+	res = serve(secretHandler(t, a), "GET", "http://example.com/secret/public/x", "Hawk good")
+	if res.Code != 200 {
+		t.Errorf("Expected 200 response, but got %v %q", res.Code, res.Body.String())
+	}
+}
+
+func TestForwardedHeaders(t *testing.T) {
+	auth := &fakeAuth{expires: time.Now().Add(time.Hour)}
+	a := New(auth)
+	a.TrustForwardedHeaders = true
+	req := httptest.NewRequest("POST", "http://internal:8080/secret/public/x", nil)
+	req.Header.Set("Authorization", "Hawk good")
+	req.Header.Set("X-Forwarded-Host", "service.example.com")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	_, err := a.Authenticate(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := &tcauth.HawkSignatureAuthenticationRequest{
+		Authorization: "Hawk good",
+		Host:          "service.example.com",
+		Method:        "post",
+		Port:          443,
+		Resource:      "/secret/public/x",
+		SourceIP:      "203.0.113.7",
+	}
+	if !reflect.DeepEqual(auth.requests[0], expected) {
+		t.Errorf("Expected auth request %#v, but got %#v", expected, auth.requests[0])
+	}
+}
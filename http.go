@@ -2,13 +2,16 @@ package tcclient
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	// "net/http/httputil"
@@ -42,22 +45,37 @@ type CallSummary struct {
 	HTTPResponseBody string
 	// Keep a record of how many http requests were attempted
 	Attempts int
+	// Redactor masks secrets when the CallSummary is formatted with String,
+	// and in the response body included in the message of a failed call's
+	// error. If nil, DefaultRedactor is used.
+	Redactor *Redactor
 }
 
+// redactor returns cs.Redactor, or DefaultRedactor if it is not set.
+func (cs *CallSummary) redactor() *Redactor {
+	if cs.Redactor == nil {
+		return DefaultRedactor
+	}
+	return cs.Redactor
+}
+
+// String returns a human readable description of the API call, with secrets
+// masked by cs.Redactor.
 func (cs *CallSummary) String() string {
+	redactor := cs.redactor()
 	s := "\nCALL SUMMARY\n============\n"
 	if req := cs.HTTPRequest; req != nil {
 		s += fmt.Sprintf("Method: %v\n", req.Method)
 		if req.URL != nil {
-			s += fmt.Sprintf("URL: %v\n", req.URL)
+			s += fmt.Sprintf("URL: %v\n", redactor.URL(req.URL))
 		}
-		s += fmt.Sprintf("Request Headers:\n%#v\n", req.Header)
+		s += fmt.Sprintf("Request Headers:\n%#v\n", redactor.Header(req.Header))
 	}
-	s += fmt.Sprintf("Request Body:\n%v\n", cs.HTTPRequestBody)
+	s += fmt.Sprintf("Request Body:\n%v\n", redactor.Body(cs.HTTPRequestBody))
 	if resp := cs.HTTPResponse; resp != nil {
-		s += fmt.Sprintf("Response Headers:\n%#v\n", cs.HTTPResponse.Header)
+		s += fmt.Sprintf("Response Headers:\n%#v\n", redactor.Header(cs.HTTPResponse.Header))
 	}
-	s += fmt.Sprintf("Response Body:\n%v\n", cs.HTTPResponseBody)
+	s += fmt.Sprintf("Response Body:\n%v\n", redactor.Body(cs.HTTPResponseBody))
 	s += fmt.Sprintf("Attempts: %v", cs.Attempts)
 	return s
 }
@@ -105,61 +123,177 @@ func setURL(client *Client, route string, query url.Values) (u *url.URL, err err
 // performing any json marshaling/unmarshaling of requests/responses. It is
 // useful if you wish to handle raw payloads and/or raw http response bodies,
 // rather than calling APICall which translates []byte to/from go types.
+//
+// As in earlier releases, if the service responds with an HTTP status code
+// outside of the 2xx range, the error is an httpbackoff.BadHttpResponseCode,
+// and if the request could not be completed due to a network failure, it is
+// the error returned by the http client. Use APICall for errors that match
+// sentinel errors such as ErrNotFound, and that provide an *APIError via
+// errors.As.
 func (client *Client) Request(rawPayload []byte, method, route string, query url.Values) (*CallSummary, error) {
-	callSummary := new(CallSummary)
-	callSummary.HTTPRequestBody = string(rawPayload)
+	return client.RequestWithContext(client.Context, rawPayload, method, route, query)
+}
 
-	// function to perform http request - we call this using backoff library to
-	// have exponential backoff in case of intermittent failures (e.g. network
-	// blips or HTTP 5xx errors)
-	httpCall := func() (*http.Response, error, error) {
+// RequestWithContext is the same as Request, except that the http request(s)
+// are bound to the given context rather than to client.Context. This allows a
+// single client to be shared between callers that require different deadlines
+// or cancellation. If ctx is nil, the requests are not bound to any context.
+//
+// The response body is always returned in CallSummary.HTTPResponseBody, even
+// if client.DisableBodyCapture is set. Use RequestStream to avoid buffering it.
+func (client *Client) RequestWithContext(ctx context.Context, rawPayload []byte, method, route string, query url.Values) (*CallSummary, error) {
+	callSummary, err := client.request(ctx, rawPayload, method, route, query)
+	return callSummary, legacyError(err)
+}
+
+// request is the implementation of RequestWithContext, which reports failed
+// responses as an *APIError and network failures as a *NetworkError.
+func (client *Client) request(ctx context.Context, rawPayload []byte, method, route string, query url.Values) (*CallSummary, error) {
+	callSummary, body, err := client.requestStream(ctx, rawPayload, method, route, query)
+	if err != nil {
+		return callSummary, err
+	}
+	defer body.Close()
+	// read response into memory, so that we can return the body
+	data, err := ioutil.ReadAll(body)
+	callSummary.HTTPResponseBody = string(data)
+	return callSummary, err
+}
+
+// RequestStream is like Request, except that the body of a successful
+// response is not read into memory, but returned as an io.ReadCloser, which
+// the caller must close. Failed requests are retried according to the
+// client's RetryPolicy up until the response headers have been received;
+// errors that occur while reading the returned body are not retried.
+//
+// Errors are reported as by Request. If an error is returned, the returned io.ReadCloser is nil.
+func (client *Client) RequestStream(rawPayload []byte, method, route string, query url.Values) (*CallSummary, io.ReadCloser, error) {
+	return client.RequestStreamWithContext(client.Context, rawPayload, method, route, query)
+}
+
+// RequestStreamWithContext is the same as RequestStream, except that the http
+// request(s) are bound to the given context rather than to client.Context. If
+// ctx is nil, the requests are not bound to any context.
+func (client *Client) RequestStreamWithContext(ctx context.Context, rawPayload []byte, method, route string, query url.Values) (*CallSummary, io.ReadCloser, error) {
+	callSummary, body, err := client.requestStream(ctx, rawPayload, method, route, query)
+	return callSummary, body, legacyError(err)
+}
+
+// requestStream is the implementation of RequestStreamWithContext, which
+// reports failed responses as an *APIError and network failures as a
+// *NetworkError.
+func (client *Client) requestStream(ctx context.Context, rawPayload []byte, method, route string, query url.Values) (*CallSummary, io.ReadCloser, error) {
+	start := time.Now()
+	callSummary := &CallSummary{
+		Redactor: client.Redactor,
+	}
+	if !client.DisableBodyCapture {
+		callSummary.HTTPRequestBody = string(rawPayload)
+	}
+
+	// function to send a single signed http request, returning the http
+	// response, the Hawk auth used to sign it (if any), a temporary error
+	// and a permanent error
+	send := func() (*http.Response, *hawk.Auth, error, error) {
+		release := func() {}
+		if client.RateLimiter != nil {
+			var err error
+			release, err = client.RateLimiter.Acquire(ctx)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
 		var ioReader io.Reader
 		ioReader = bytes.NewReader(rawPayload)
 		u, err := setURL(client, route, query)
 		if err != nil {
-			return nil, nil, fmt.Errorf("apiCall url cannot be parsed:\n%v\n", err)
+			release()
+			return nil, nil, nil, fmt.Errorf("apiCall url cannot be parsed:\n%v\n", err)
 		}
 		callSummary.HTTPRequest, err = http.NewRequest(method, u.String(), ioReader)
 		if err != nil {
-			return nil, nil, fmt.Errorf("Internal error: apiCall url cannot be parsed although thought to be valid: '%v', is the BaseURL (%v) set correctly?\n%v\n", u.String(), client.BaseURL, err)
+			release()
+			return nil, nil, nil, fmt.Errorf("Internal error: apiCall url cannot be parsed although thought to be valid: '%v', is the BaseURL (%v) set correctly?\n%v\n", u.String(), client.BaseURL, err)
 		}
 		if len(rawPayload) > 0 {
 			callSummary.HTTPRequest.Header.Set("Content-Type", "application/json")
 		}
 		// Refresh Authorization header with each call...
 		// Only authenticate if client library user wishes to.
+		var reqAuth *hawk.Auth
 		if client.Authenticate {
-			err = client.Credentials.SignRequest(callSummary.HTTPRequest)
+			var creds *Credentials
+			creds, err = client.credentials(ctx)
+			if err == nil {
+				reqAuth, err = creds.signRequest(callSummary.HTTPRequest)
+			}
 			if err != nil {
-				return nil, nil, err
+				release()
+				return nil, nil, nil, err
 			}
 		}
 		// Set context if one is given
-		if client.Context != nil {
-			callSummary.HTTPRequest = callSummary.HTTPRequest.WithContext(client.Context)
+		if ctx != nil {
+			callSummary.HTTPRequest = callSummary.HTTPRequest.WithContext(ctx)
 		}
-		var resp *http.Response
-		if client.HTTPClient != nil {
-			resp, err = client.HTTPClient.Do(callSummary.HTTPRequest)
+		resp, err := client.roundTrip(callSummary.HTTPRequest)
+		// the request is in flight until its response body is closed
+		if resp != nil && resp.Body != nil {
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
 		} else {
-			resp, err = defaultHTTPClient.Do(callSummary.HTTPRequest)
+			release()
 		}
 		// return cancelled error, if context was cancelled
-		if client.Context != nil && client.Context.Err() != nil {
-			return nil, nil, client.Context.Err()
+		if ctx != nil && ctx.Err() != nil {
+			if resp != nil && resp.Body != nil {
+				resp.Body.Close()
+			}
+			return nil, nil, nil, ctx.Err()
 		}
 		// b, e := httputil.DumpResponse(resp, true)
 		// if e == nil {
 		// 	fmt.Println(string(b))
 		// }
-		return resp, err, nil
+		return resp, reqAuth, err, nil
+	}
+
+	// function to perform http request - we call this according to the
+	// client's retry policy, to have exponential backoff in case of
+	// intermittent failures (e.g. network blips or HTTP 5xx errors)
+	httpCall := func() (*http.Response, error, error) {
+		resp, reqAuth, tempError, permError := send()
+		// If Hawk authentication failed because the local clock is out of
+		// sync with the service, correct the clock offset used for signing,
+		// and try again straight away.
+		if tempError == nil && permError == nil && reqAuth != nil && updateClockOffset(reqAuth, resp) {
+			resp.Body.Close()
+			resp, reqAuth, tempError, permError = send()
+		}
+		if tempError != nil || permError != nil {
+			return resp, tempError, permError
+		}
+		if client.VerifyResponses && resp.StatusCode/100 == 2 {
+			if reqAuth == nil {
+				resp.Body.Close()
+				return resp, nil, &ResponseVerificationError{Reason: "responses can only be verified if Authenticate is true"}
+			}
+			if err := verifyResponse(reqAuth, resp); err != nil {
+				resp.Body.Close()
+				return resp, nil, err
+			}
+		}
+		return resp, nil, nil
 	}
 
-	// Make HTTP API calls using an exponential backoff algorithm...
+	// Make HTTP API calls, retrying according to the client's retry policy...
 	var err error
-	callSummary.HTTPResponse, callSummary.Attempts, err = httpbackoff.Retry(httpCall)
+	callSummary.HTTPResponse, callSummary.Attempts, err = client.retry(ctx, callSummary, httpCall)
+	if err == nil {
+		client.recordMetrics(ctx, callSummary, start, nil)
+		return callSummary, callSummary.HTTPResponse.Body, nil
+	}
 
-	// read response into memory, so that we can return the body
+	// the body of a failed response has already been buffered by retry
 	if callSummary.HTTPResponse != nil {
 		body, err2 := ioutil.ReadAll(callSummary.HTTPResponse.Body)
 		if err2 == nil {
@@ -167,12 +301,96 @@ func (client *Client) Request(rawPayload []byte, method, route string, query url
 		}
 	}
 
-	return callSummary, err
+	// expose structured details of http error responses
+	if badResponse, ok := err.(httpbackoff.BadHttpResponseCode); ok && callSummary.HTTPResponse != nil {
+		err = newAPIError(callSummary, badResponse)
+	}
 
+	client.recordMetrics(ctx, callSummary, start, err)
+	return callSummary, nil, err
+}
+
+// retry calls httpCall until it succeeds, returns a permanent error, or the
+// client's RetryPolicy (or DefaultRetryPolicy, if not set) decides not to try
+// again. httpCall returns the http response, a temporary error (such as a
+// network failure) that may be retried, and a permanent error that may not. A
+// response with a non-2xx http status code is reported as an
+// httpbackoff.BadHttpResponseCode error, for consistency with earlier
+// releases that used the httpbackoff package for retries, and a failure to
+// send the request or read the response as a *NetworkError.
+func (client *Client) retry(ctx context.Context, callSummary *CallSummary, httpCall func() (*http.Response, error, error)) (*http.Response, int, error) {
+	policy := client.RetryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy
+	}
+	start := time.Now()
+	attempts := 0
+	for {
+		resp, tempError, permError := httpCall()
+		attempts++
+		if permError != nil {
+			return resp, attempts, permError
+		}
+		var err error
+		if tempError != nil {
+			err = &NetworkError{Err: tempError}
+		} else {
+			if resp.StatusCode/100 == 2 {
+				return resp, attempts, nil
+			}
+			// read the response body, so that it is still available to the
+			// caller after the connection has been released
+			body, readError := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			if readError != nil {
+				err = &NetworkError{Err: readError}
+			} else {
+				qualifier := "(Permanent)"
+				if resp.StatusCode/100 == 5 {
+					qualifier = "(Intermittent)"
+				}
+				// the message ends up in the error messages of failed calls,
+				// so mask any secrets in the body
+				err = httpbackoff.BadHttpResponseCode{
+					HttpResponseCode: resp.StatusCode,
+					Message:          qualifier + " HTTP response code " + strconv.Itoa(resp.StatusCode) + "\n" + callSummary.redactor().Body(string(body)),
+				}
+			}
+		}
+		var failedResp *http.Response
+		if tempError == nil {
+			failedResp = resp
+		}
+		delay, retry := policy.RetryDelay(attempts, time.Since(start), callSummary.HTTPRequest, failedResp, tempError)
+		if !retry {
+			return resp, attempts, err
+		}
+		log.Printf("Error: %s", err)
+		timer := time.NewTimer(delay)
+		if ctx == nil {
+			<-timer.C
+			continue
+		}
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, attempts, ctx.Err()
+		}
+	}
 }
 
 // SignRequest will add an Authorization header
 func (c *Credentials) SignRequest(req *http.Request) (err error) {
+	_, err = c.signRequest(req)
+	return
+}
+
+// signRequest adds an Authorization header to req, and returns the Hawk auth
+// used, which is needed to verify the Server-Authorization header of the
+// response.
+func (c *Credentials) signRequest(req *http.Request) (*hawk.Auth, error) {
 	// s, err := c.SignHeader(req.Method, req.URL.String(), hash)
 	// req.Header.Set("Authorization", s)
 	// return err
@@ -182,18 +400,26 @@ func (c *Credentials) SignRequest(req *http.Request) (err error) {
 		Key:  c.AccessToken,
 		Hash: sha256.New,
 	}
-	reqAuth := hawk.NewRequestAuth(req, credentials, 0)
+	reqAuth := hawk.NewRequestAuth(req, credentials, ClockOffset())
+	var err error
 	reqAuth.Ext, err = getExtHeader(c)
 	if err != nil {
-		return fmt.Errorf("Internal error: was not able to generate hawk ext header from provided credentials:\n%s\n%s", c, err)
+		return nil, fmt.Errorf("Internal error: was not able to generate hawk ext header from provided credentials:\n%s\n%s", c, err)
 	}
 	req.Header.Set("Authorization", reqAuth.RequestHeader())
-	return nil
+	return reqAuth, nil
 }
 
+// APICallException is the error returned by APICall when an API call fails.
+// If the service responded with an HTTP status code outside of the 2xx range,
+// RootCause is an httpbackoff.BadHttpResponseCode, as in earlier releases, and
+// the structured details of the response are available as an *APIError via
+// errors.As.
 type APICallException struct {
 	CallSummary *CallSummary
 	RootCause   error
+	// the *APIError or *NetworkError that RootCause belongs to, if any
+	err error
 }
 
 func (err *APICallException) Error() string {
@@ -204,6 +430,14 @@ func (err *APICallException) Error() string {
 // calls for this library.  Each auto-generated REST API method simply is a
 // wrapper around this method, calling it with specific specific arguments.
 func (client *Client) APICall(payload interface{}, method, route string, result interface{}, query url.Values) (interface{}, *CallSummary, error) {
+	return client.APICallWithContext(client.Context, payload, method, route, result, query)
+}
+
+// APICallWithContext is the same as APICall, except that the http request(s)
+// are bound to the given context rather than to client.Context. Each
+// auto-generated XxxWithContext method is a wrapper around this method. If ctx
+// is nil, the requests are not bound to any context.
+func (client *Client) APICallWithContext(ctx context.Context, payload interface{}, method, route string, result interface{}, query url.Values) (interface{}, *CallSummary, error) {
 	rawPayload := []byte{}
 	var err error
 	if reflect.ValueOf(payload).IsValid() && !reflect.ValueOf(payload).IsNil() {
@@ -211,6 +445,7 @@ func (client *Client) APICall(payload interface{}, method, route string, result
 		if err != nil {
 			cs := &CallSummary{
 				HTTPRequestObject: payload,
+				Redactor:          client.Redactor,
 			}
 			return result,
 				cs,
@@ -220,13 +455,27 @@ func (client *Client) APICall(payload interface{}, method, route string, result
 				}
 		}
 	}
-	callSummary, err := client.Request(rawPayload, method, route, query)
+	if client.DisableBodyCapture {
+		return client.apiCallStream(ctx, payload, rawPayload, method, route, result, query)
+	}
+	callSummary, err := client.request(ctx, rawPayload, method, route, query)
 	callSummary.HTTPRequestObject = payload
 	if err != nil {
 		// If context failed during this request, then we should just return that error
-		if client.Context != nil && client.Context.Err() != nil {
-			return result, callSummary, client.Context.Err()
+		if ctx != nil && ctx.Err() != nil {
+			return result, callSummary, ctx.Err()
 		}
+		return result,
+			callSummary,
+			newAPICallException(callSummary, err)
+	}
+	// if result is passed in as nil, it means the API defines no response body
+	// json
+	if reflect.ValueOf(result).IsValid() && !reflect.ValueOf(result).IsNil() {
+		err = json.Unmarshal([]byte(callSummary.HTTPResponseBody), &result)
+	}
+
+	if err != nil {
 		return result,
 			callSummary,
 			&APICallException{
@@ -234,10 +483,34 @@ func (client *Client) APICall(payload interface{}, method, route string, result
 				RootCause:   err,
 			}
 	}
+	return result, callSummary, nil
+}
+
+// apiCallStream is the implementation of APICallWithContext for clients that
+// have DisableBodyCapture set, which decodes the json response directly from
+// the http response body rather than from CallSummary.HTTPResponseBody.
+func (client *Client) apiCallStream(ctx context.Context, payload interface{}, rawPayload []byte, method, route string, result interface{}, query url.Values) (interface{}, *CallSummary, error) {
+	callSummary, body, err := client.requestStream(ctx, rawPayload, method, route, query)
+	callSummary.HTTPRequestObject = payload
+	if err != nil {
+		// If context failed during this request, then we should just return that error
+		if ctx != nil && ctx.Err() != nil {
+			return result, callSummary, ctx.Err()
+		}
+		return result,
+			callSummary,
+			newAPICallException(callSummary, err)
+	}
+	defer body.Close()
 	// if result is passed in as nil, it means the API defines no response body
 	// json
 	if reflect.ValueOf(result).IsValid() && !reflect.ValueOf(result).IsNil() {
-		err = json.Unmarshal([]byte(callSummary.HTTPResponseBody), &result)
+		err = json.NewDecoder(body).Decode(&result)
+	}
+	// drain any remaining data, so that the connection can be reused, and
+	// the response body can be verified (see Client.VerifyResponses)
+	if _, drainErr := io.Copy(ioutil.Discard, body); err == nil {
+		err = drainErr
 	}
 
 	if err != nil {
@@ -256,30 +529,11 @@ func (client *Client) APICall(payload interface{}, method, route string, result
 // query string parameters, if any, and duration is the amount of time that the
 // signed URL should remain valid for.
 func (client *Client) SignedURL(route string, query url.Values, duration time.Duration) (u *url.URL, err error) {
-	u, err = setURL(client, route, query)
+	signer, err := client.NewURLSigner(duration)
 	if err != nil {
 		return
 	}
-	credentials := &hawk.Credentials{
-		ID:   client.Credentials.ClientID,
-		Key:  client.Credentials.AccessToken,
-		Hash: sha256.New,
-	}
-	reqAuth, err := hawk.NewURLAuth(u.String(), credentials, duration)
-	if err != nil {
-		return
-	}
-	reqAuth.Ext, err = getExtHeader(client.Credentials)
-	if err != nil {
-		return
-	}
-	bewitSignature := reqAuth.Bewit()
-	if query == nil {
-		query = url.Values{}
-	}
-	query.Set("bewit", bewitSignature)
-	u.RawQuery = query.Encode()
-	return
+	return signer.Sign(route, query)
 }
 
 // getExtHeader generates the hawk ext header based on the authorizedScopes and
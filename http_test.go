@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -210,6 +211,117 @@ func TestRequestWithContext(t *testing.T) {
 	}
 }
 
+// TestAPICallWithContext checks that the context passed to APICallWithContext
+// is used in preference to client.Context.
+func TestAPICallWithContext(t *testing.T) {
+	clientCtx, clientCancel := context.WithCancel(context.Background())
+	defer clientCancel()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			// respond only once the client has given up
+			<-r.Context().Done()
+			return
+		}
+		// cancel the client context while the call is in flight
+		clientCancel()
+		w.WriteHeader(200)
+		w.Write([]byte(`{"value": "hello world"}`))
+	}))
+	defer s.Close()
+	c := Client{
+		BaseURL:      s.URL,
+		Authenticate: false,
+		Context:      clientCtx,
+	}
+
+	var result struct {
+		Value string `json:"value"`
+	}
+
+	// Cancelling client.Context should not affect a call with its own context
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, _, err := c.APICallWithContext(ctx, nil, "GET", "/whatever", &result, nil)
+	if err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if result.Value != "hello world" {
+		t.Fatalf("Expected value %q but got %q", "hello world", result.Value)
+	}
+
+	// Deadline of the given context should abort the call
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = c.APICallWithContext(ctx, nil, "GET", "/hang", &result, nil)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected deadline exceeded error but got %T %v", err, err)
+	}
+}
+
+func TestRequestStream(t *testing.T) {
+	s, requests := failingServer(1, 500, nil)
+	defer s.Close()
+	c := Client{
+		BaseURL:            s.URL,
+		RetryPolicy:        fastRetries,
+		DisableBodyCapture: true,
+	}
+	cs, body, err := c.RequestStream([]byte(`{"big": "payload"}`), "PUT", "/whatever", nil)
+	if err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	defer body.Close()
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatal("Unexpected error reading body: ", err)
+	}
+	if string(data) != "{}" {
+		t.Fatalf("Expected response body %q but got %q", "{}", data)
+	}
+	if *requests != 2 || cs.Attempts != 2 {
+		t.Fatalf("Expected request to be retried once, but got %v attempts", cs.Attempts)
+	}
+	if cs.HTTPRequestBody != "" || cs.HTTPResponseBody != "" {
+		t.Fatalf("Expected bodies not to be captured, but got %q and %q", cs.HTTPRequestBody, cs.HTTPResponseBody)
+	}
+}
+
+func TestAPICallDisableBodyCapture(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(404)
+			w.Write([]byte(`{"code": "ResourceNotFound", "message": "not here"}`))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{"value": "hello world"}`))
+	}))
+	defer s.Close()
+	c := Client{
+		BaseURL:            s.URL,
+		DisableBodyCapture: true,
+	}
+	var result struct {
+		Value string `json:"value"`
+	}
+	_, cs, err := c.APICall(&result, "POST", "/whatever", &result, nil)
+	if err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if result.Value != "hello world" {
+		t.Fatalf("Expected value %q but got %q", "hello world", result.Value)
+	}
+	if cs.HTTPRequestBody != "" || cs.HTTPResponseBody != "" {
+		t.Fatalf("Expected bodies not to be captured, but got %q and %q", cs.HTTPRequestBody, cs.HTTPResponseBody)
+	}
+	// error responses are still available, for APIError
+	_, _, err = c.APICall(nil, "GET", "/missing", &result, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "ResourceNotFound" {
+		t.Fatalf("Expected ResourceNotFound APIError but got %v", err)
+	}
+}
+
 // Make sure Content-Type is only set if there is a payload
 func TestContentTypeHeader(t *testing.T) {
 	// This mock service just returns the value of the Content-Type request
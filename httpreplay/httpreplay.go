@@ -0,0 +1,258 @@
+// Package httpreplay provides implementations of tcclient.ReducedHTTPClient
+// that record http interactions with Taskcluster services to a fixture file,
+// and replay them later, so that code built on the client can be tested
+// deterministically, without access to a live deployment.
+//
+// To record, wrap a real http client in a Recorder, and call Save when done:
+//
+//  recorder := httpreplay.NewRecorder("testdata/fixture.json", nil)
+//  queue := tcqueue.New(creds, rootURL)
+//  queue.HTTPClient = recorder
+//  ... make API calls ...
+//  err := recorder.Save()
+//
+// To replay, load the fixture with NewReplayer:
+//
+//  replayer, err := httpreplay.NewReplayer("testdata/fixture.json")
+//  queue := tcqueue.New(creds, rootURL)
+//  queue.HTTPClient = replayer
+//  ... make the same API calls ...
+//
+// Request headers are not recorded, so neither Hawk Authorization headers nor
+// credentials end up in fixture files, and replayed requests are matched
+// regardless of the Hawk nonce and timestamp. Response bodies are recorded
+// verbatim, so take care not to commit fixtures that contain secrets.
+package httpreplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+)
+
+// Interaction is a recorded http request together with the response it
+// received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded http request.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded http response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Matcher reports whether the recorded request matches the actual request
+// made during replay.
+type Matcher func(recorded, actual Request) bool
+
+// DefaultMatcher matches requests with the same method, body and URL, where
+// URLs are compared ignoring the bewit query string parameter (which contains
+// a Hawk timestamp and nonce) and the order of query string parameters.
+func DefaultMatcher(recorded, actual Request) bool {
+	return recorded.Method == actual.Method &&
+		recorded.Body == actual.Body &&
+		normalizeURL(recorded.URL) == normalizeURL(actual.URL)
+}
+
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	query.Del("bewit")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Recorder is a tcclient.ReducedHTTPClient that passes requests on to
+// another ReducedHTTPClient, and records the interactions.
+type Recorder struct {
+	fixture      string
+	httpClient   tcclient.ReducedHTTPClient
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder returns a Recorder that records interactions made via
+// httpClient, to be saved to the file fixture. If httpClient is nil,
+// http.DefaultClient is used.
+func NewRecorder(fixture string, httpClient tcclient.ReducedHTTPClient) *Recorder {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Recorder{
+		fixture:    fixture,
+		httpClient: httpClient,
+	}
+}
+
+// Do implements the tcclient.ReducedHTTPClient interface.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: request,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       string(body),
+		},
+	})
+	return resp, nil
+}
+
+// Interactions returns the interactions recorded so far.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction{}, r.interactions...)
+}
+
+// Save writes the interactions recorded so far to the fixture file.
+func (r *Recorder) Save() error {
+	data, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.fixture, append(data, '\n'), 0644)
+}
+
+// Replayer is a tcclient.ReducedHTTPClient that responds to requests with
+// previously recorded responses, without making any network calls.
+type Replayer struct {
+	// Matcher decides which recorded interaction corresponds to a request.
+	// If nil, DefaultMatcher is used.
+	Matcher      Matcher
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer returns a Replayer for the interactions stored in the file
+// fixture by Recorder.Save.
+func NewReplayer(fixture string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(fixture)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	err = json.Unmarshal(data, &interactions)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot parse http replay fixture %v: %v", fixture, err)
+	}
+	return NewReplayerFromInteractions(interactions), nil
+}
+
+// NewReplayerFromInteractions returns a Replayer for the given interactions.
+func NewReplayerFromInteractions(interactions []Interaction) *Replayer {
+	return &Replayer{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}
+}
+
+// Do implements the tcclient.ReducedHTTPClient interface. Each recorded
+// interaction is replayed at most once, in the order recorded, so that e.g.
+// a failed request followed by a successful retry replays faithfully. An
+// error is returned if no unused recorded interaction matches the request.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	request, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	matcher := r.Matcher
+	if matcher == nil {
+		matcher = DefaultMatcher
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || !matcher(interaction.Request, request) {
+			continue
+		}
+		r.used[i] = true
+		recorded := interaction.Response
+		header := http.Header{}
+		for k, v := range recorded.Header {
+			header[k] = append([]string{}, v...)
+		}
+		return &http.Response{
+			Status:        strconv.Itoa(recorded.StatusCode) + " " + http.StatusText(recorded.StatusCode),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewBufferString(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("No recorded http interaction matches request %v %v", request.Method, request.URL)
+}
+
+// Unused returns the recorded interactions that have not been replayed, which
+// is useful for asserting that code under test made all expected requests.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	unused := []Interaction{}
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// readRequest returns a Request describing req, restoring req.Body so that it
+// can still be sent.
+func readRequest(req *http.Request) (Request, error) {
+	request := Request{
+		Method: req.Method,
+		URL:    req.URL.String(),
+	}
+	if req.Body == nil {
+		return request, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return request, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	request.Body = string(body)
+	return request, nil
+}
@@ -0,0 +1,102 @@
+package httpreplay
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Hawk ") {
+			w.WriteHeader(401)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"path": "` + r.URL.Path + `", "echo": ` + string(body) + `}`))
+	}))
+	dir, err := ioutil.TempDir("", "httpreplay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+
+	client := tcclient.Client{
+		Credentials: &tcclient.Credentials{
+			ClientID:    "tester",
+			AccessToken: "no-secret",
+		},
+		BaseURL:      s.URL,
+		Authenticate: true,
+	}
+	type result struct {
+		Path string            `json:"path"`
+		Echo map[string]string `json:"echo"`
+	}
+	call := func() (*result, error) {
+		r := new(result)
+		_, _, err := client.APICall(map[string]string{"hello": "world"}, "POST", "/things/abc", r, nil)
+		return r, err
+	}
+
+	recorder := NewRecorder(fixture, nil)
+	client.HTTPClient = recorder
+	recorded, err := call()
+	if err != nil {
+		t.Fatalf("Unexpected error while recording: %v", err)
+	}
+	err = recorder.Save()
+	if err != nil {
+		t.Fatalf("Could not save fixture: %v", err)
+	}
+	s.Close()
+
+	data, _ := ioutil.ReadFile(fixture)
+	if strings.Contains(string(data), "Hawk") || strings.Contains(string(data), "no-secret") {
+		t.Fatalf("Expected fixture not to contain credentials:\n%s", data)
+	}
+
+	replayer, err := NewReplayer(fixture)
+	if err != nil {
+		t.Fatalf("Could not load fixture: %v", err)
+	}
+	client.HTTPClient = replayer
+	replayed, err := call()
+	if err != nil {
+		t.Fatalf("Unexpected error while replaying: %v", err)
+	}
+	if replayed.Path != "/things/abc" || replayed.Echo["hello"] != "world" || replayed.Path != recorded.Path {
+		t.Fatalf("Replayed result %#v does not match recorded result %#v", replayed, recorded)
+	}
+	if len(replayer.Unused()) != 0 {
+		t.Fatalf("Expected all interactions to be used")
+	}
+
+	// each interaction is only replayed once
+	client.RetryPolicy = tcclient.NoRetries
+	_, err = call()
+	if err == nil || !strings.Contains(err.Error(), "No recorded http interaction matches") {
+		t.Fatalf("Expected no matching interaction, but got %v", err)
+	}
+}
+
+func TestDefaultMatcher(t *testing.T) {
+	recorded := Request{Method: "GET", URL: "https://tc.example.com/api/queue/v1/task/abc/artifacts/x?b=2&a=1&bewit=old"}
+	if !DefaultMatcher(recorded, Request{Method: "GET", URL: "https://tc.example.com/api/queue/v1/task/abc/artifacts/x?a=1&bewit=new&b=2"}) {
+		t.Error("Expected requests differing only by bewit and parameter order to match")
+	}
+	if DefaultMatcher(recorded, Request{Method: "HEAD", URL: recorded.URL}) {
+		t.Error("Expected requests with different methods not to match")
+	}
+	if DefaultMatcher(recorded, Request{Method: "GET", URL: recorded.URL, Body: "{}"}) {
+		t.Error("Expected requests with different bodies not to match")
+	}
+}
@@ -78,11 +78,12 @@ func TestDefineTask(t *testing.T) {
 	created := time.Now()
 	deadline := created.AddDate(0, 0, 1)
 	expires := deadline
+	tcExpires := tcclient.Time(expires)
 
 	td := &tcqueue.TaskDefinitionRequest{
 		Created:  tcclient.Time(created),
 		Deadline: tcclient.Time(deadline),
-		Expires:  tcclient.Time(expires),
+		Expires:  &tcExpires,
 		Extra:    json.RawMessage(`{"index":{"rank":12345}}`),
 		Metadata: tcqueue.TaskMetadata{
 			Description: "Stuff",
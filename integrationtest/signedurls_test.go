@@ -135,13 +135,14 @@ func createAndTestExampleSignedURL(myQueue *tcqueue.Queue, t *testing.T) {
 
 func createSampleTaskAndArtifact(myQueue *tcqueue.Queue, t *testing.T) (taskID string, artifactName string, artifactContent []byte) {
 	now := time.Now()
+	expires := tcclient.Time(now.Add(60 * 24 * time.Hour))
 	taskID = slugid.Nice()
 	artifactName = "private/" + t.Name() + ".bin"
 	artifactContent = []byte("!@##%@$%@#$<sd fsdhf")
 	tdr := tcqueue.TaskDefinitionRequest{
 		Created:  tcclient.Time(now),
 		Deadline: tcclient.Time(now.Add(1 * time.Hour)),
-		Expires:  tcclient.Time(now.Add(60 * 24 * time.Hour)),
+		Expires:  &expires,
 		Metadata: tcqueue.TaskMetadata{
 			Description: "Task created by integration test " + t.Name(),
 			Name:        t.Name(),
@@ -0,0 +1,95 @@
+package tcclient
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Error classes reported in CallMetrics.ErrorClass
+const (
+	ErrorClassNetwork          = "network"
+	ErrorClassCanceled         = "canceled"
+	ErrorClassDeadlineExceeded = "deadline_exceeded"
+	ErrorClassClientError      = "client_error"
+	ErrorClassServerError      = "server_error"
+	ErrorClassOther            = "other"
+)
+
+// CallMetrics describes a completed API call (including any retries), for
+// reporting to a MetricsSink.
+type CallMetrics struct {
+	// The API endpoint that was called, if known (see WithEndpoint). Raw calls
+	// to Client.Request have an empty Endpoint.
+	Endpoint Endpoint
+	// HTTP method of the request
+	Method string
+	// Time from the start of the first attempt until the response headers of
+	// the final attempt were received (or the call failed)
+	Duration time.Duration
+	// Number of http requests attempted, as in CallSummary.Attempts
+	Attempts int
+	// HTTP status code of the final response, or 0 if no response was received
+	StatusCode int
+	// Empty if the call succeeded, otherwise one of the ErrorClassXxx
+	// constants
+	ErrorClass string
+}
+
+// MetricsSink receives metrics about the API calls made by a Client. Since a
+// MetricsSink is typically shared between many clients and goroutines,
+// implementations must be safe for concurrent use, and should return quickly.
+//
+// See package github.com/taskcluster/taskcluster-client-go/metrics for an
+// implementation that exports metrics in the Prometheus text format.
+type MetricsSink interface {
+	RecordCall(metrics CallMetrics)
+}
+
+// recordMetrics reports the completed call to client.Metrics, if set.
+func (client *Client) recordMetrics(ctx context.Context, callSummary *CallSummary, start time.Time, err error) {
+	if client.Metrics == nil {
+		return
+	}
+	metrics := CallMetrics{
+		Duration:   time.Since(start),
+		Attempts:   callSummary.Attempts,
+		ErrorClass: errorClass(err),
+	}
+	metrics.Endpoint, _ = EndpointFromContext(ctx)
+	if callSummary.HTTPRequest != nil {
+		metrics.Method = callSummary.HTTPRequest.Method
+	}
+	if callSummary.HTTPResponse != nil {
+		metrics.StatusCode = callSummary.HTTPResponse.StatusCode
+	}
+	client.Metrics.RecordCall(metrics)
+}
+
+// errorClass returns the ErrorClassXxx constant that describes err, or the
+// empty string if err is nil.
+func errorClass(err error) string {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case *APIError:
+		if e.StatusCode/100 == 5 {
+			return ErrorClassServerError
+		}
+		return ErrorClassClientError
+	case *NetworkError:
+		return ErrorClassNetwork
+	case *url.Error:
+		return errorClass(e.Err)
+	case net.Error:
+		return ErrorClassNetwork
+	}
+	switch err {
+	case context.Canceled:
+		return ErrorClassCanceled
+	case context.DeadlineExceeded:
+		return ErrorClassDeadlineExceeded
+	}
+	return ErrorClassOther
+}
@@ -0,0 +1,190 @@
+// Package metrics provides a tcclient.MetricsSink that aggregates metrics
+// about Taskcluster API calls in memory, and exports them in the Prometheus
+// text exposition format, without depending on any Prometheus libraries.
+//
+// Example usage:
+//
+//  collector := metrics.NewCollector(nil)
+//  queue := tcqueue.NewFromEnv()
+//  queue.Metrics = collector
+//  http.Handle("/metrics", collector)
+//
+// The following metrics are exported, labelled by service and endpoint:
+//
+//  taskcluster_client_call_duration_seconds  histogram of call latencies, including retries
+//  taskcluster_client_calls_total            calls, also labelled by final status_code and error_class
+//  taskcluster_client_attempts_total         http requests attempted, including retries
+//  taskcluster_client_retries_total          http requests attempted beyond the first of each call
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histogram
+// buckets used when none are given to NewCollector.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// Collector is a tcclient.MetricsSink that is safe for concurrent use, so a
+// single Collector can be shared by all clients in a process. It implements
+// http.Handler, serving the collected metrics in the Prometheus text format.
+type Collector struct {
+	buckets   []float64
+	mu        sync.Mutex
+	endpoints map[tcclient.Endpoint]*endpointStats
+}
+
+type outcome struct {
+	statusCode int
+	errorClass string
+}
+
+type endpointStats struct {
+	// bucketCounts[i] is the number of calls that took at most buckets[i]
+	// seconds (but longer than buckets[i-1])
+	bucketCounts []uint64
+	sum          float64
+	count        uint64
+	attempts     uint64
+	outcomes     map[outcome]uint64
+}
+
+// NewCollector returns a Collector with the given latency histogram buckets,
+// which are upper bounds in seconds. If buckets is nil, DefaultBuckets is
+// used.
+func NewCollector(buckets []float64) *Collector {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	return &Collector{
+		buckets:   sorted,
+		endpoints: map[tcclient.Endpoint]*endpointStats{},
+	}
+}
+
+// RecordCall implements the tcclient.MetricsSink interface.
+func (c *Collector) RecordCall(m tcclient.CallMetrics) {
+	seconds := m.Duration.Seconds()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.endpoints[m.Endpoint]
+	if stats == nil {
+		stats = &endpointStats{
+			bucketCounts: make([]uint64, len(c.buckets)),
+			outcomes:     map[outcome]uint64{},
+		}
+		c.endpoints[m.Endpoint] = stats
+	}
+	for i, upperBound := range c.buckets {
+		if seconds <= upperBound {
+			stats.bucketCounts[i]++
+			break
+		}
+	}
+	stats.sum += seconds
+	stats.count++
+	stats.attempts += uint64(m.Attempts)
+	stats.outcomes[outcome{statusCode: m.StatusCode, errorClass: m.ErrorClass}]++
+}
+
+// WritePrometheus writes the collected metrics to w in the Prometheus text
+// exposition format (version 0.0.4).
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	endpoints := make([]tcclient.Endpoint, 0, len(c.endpoints))
+	for endpoint := range c.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Service != endpoints[j].Service {
+			return endpoints[i].Service < endpoints[j].Service
+		}
+		return endpoints[i].Name < endpoints[j].Name
+	})
+
+	out := bufio.NewWriter(w)
+	header(out, "taskcluster_client_call_duration_seconds", "histogram", "Duration of Taskcluster API calls, including retries.")
+	for _, endpoint := range endpoints {
+		stats := c.endpoints[endpoint]
+		labels := endpointLabels(endpoint)
+		var cumulative uint64
+		for i, upperBound := range c.buckets {
+			cumulative += stats.bucketCounts[i]
+			fmt.Fprintf(out, "taskcluster_client_call_duration_seconds_bucket{%v,le=%q} %v\n", labels, formatFloat(upperBound), cumulative)
+		}
+		fmt.Fprintf(out, "taskcluster_client_call_duration_seconds_bucket{%v,le=\"+Inf\"} %v\n", labels, stats.count)
+		fmt.Fprintf(out, "taskcluster_client_call_duration_seconds_sum{%v} %v\n", labels, formatFloat(stats.sum))
+		fmt.Fprintf(out, "taskcluster_client_call_duration_seconds_count{%v} %v\n", labels, stats.count)
+	}
+	header(out, "taskcluster_client_calls_total", "counter", "Taskcluster API calls, by final HTTP status code and error class.")
+	for _, endpoint := range endpoints {
+		stats := c.endpoints[endpoint]
+		outcomes := make([]outcome, 0, len(stats.outcomes))
+		for o := range stats.outcomes {
+			outcomes = append(outcomes, o)
+		}
+		sort.Slice(outcomes, func(i, j int) bool {
+			if outcomes[i].statusCode != outcomes[j].statusCode {
+				return outcomes[i].statusCode < outcomes[j].statusCode
+			}
+			return outcomes[i].errorClass < outcomes[j].errorClass
+		})
+		for _, o := range outcomes {
+			statusCode := ""
+			if o.statusCode != 0 {
+				statusCode = strconv.Itoa(o.statusCode)
+			}
+			fmt.Fprintf(out, "taskcluster_client_calls_total{%v,status_code=\"%v\",error_class=\"%v\"} %v\n", endpointLabels(endpoint), statusCode, escape(o.errorClass), stats.outcomes[o])
+		}
+	}
+	header(out, "taskcluster_client_attempts_total", "counter", "HTTP requests attempted for Taskcluster API calls, including retries.")
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(out, "taskcluster_client_attempts_total{%v} %v\n", endpointLabels(endpoint), c.endpoints[endpoint].attempts)
+	}
+	header(out, "taskcluster_client_retries_total", "counter", "HTTP requests attempted for Taskcluster API calls, beyond the first attempt of each call.")
+	for _, endpoint := range endpoints {
+		stats := c.endpoints[endpoint]
+		retries := uint64(0)
+		if stats.attempts > stats.count {
+			retries = stats.attempts - stats.count
+		}
+		fmt.Fprintf(out, "taskcluster_client_retries_total{%v} %v\n", endpointLabels(endpoint), retries)
+	}
+	return out.Flush()
+}
+
+// ServeHTTP implements the http.Handler interface, serving the collected
+// metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WritePrometheus(w)
+}
+
+func header(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, metricType)
+}
+
+func endpointLabels(endpoint tcclient.Endpoint) string {
+	return "service=\"" + escape(endpoint.Service) + "\",endpoint=\"" + escape(endpoint.Name) + "\""
+}
+
+// escape escapes a label value as required by the Prometheus text format.
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+)
+
+func TestCollector(t *testing.T) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(404)
+			return
+		}
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer s.Close()
+	collector := NewCollector([]float64{0.5, 60})
+	client := tcclient.Client{
+		BaseURL: s.URL,
+		RetryPolicy: &tcclient.ExponentialBackoff{
+			InitialInterval: time.Millisecond,
+			MaxAttempts:     3,
+		},
+		Metrics: collector,
+	}
+	ctx := tcclient.WithEndpoint(nil, "queue", "task")
+	for i := 0; i < 2; i++ {
+		_, _, err := client.APICallWithContext(ctx, nil, "GET", "/task/abc", new(struct{}), nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	_, _, err := client.APICallWithContext(tcclient.WithEndpoint(nil, "queue", "status"), nil, "GET", "/missing", new(struct{}), nil)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	buf := new(bytes.Buffer)
+	err = collector.WritePrometheus(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := buf.String()
+	for _, line := range []string{
+		`# TYPE taskcluster_client_call_duration_seconds histogram`,
+		`taskcluster_client_call_duration_seconds_bucket{service="queue",endpoint="task",le="0.5"} 2`,
+		`taskcluster_client_call_duration_seconds_bucket{service="queue",endpoint="task",le="+Inf"} 2`,
+		`taskcluster_client_call_duration_seconds_count{service="queue",endpoint="task"} 2`,
+		`taskcluster_client_calls_total{service="queue",endpoint="task",status_code="200",error_class=""} 2`,
+		`taskcluster_client_calls_total{service="queue",endpoint="status",status_code="404",error_class="client_error"} 1`,
+		`taskcluster_client_attempts_total{service="queue",endpoint="task"} 3`,
+		`taskcluster_client_retries_total{service="queue",endpoint="task"} 1`,
+		`taskcluster_client_retries_total{service="queue",endpoint="status"} 0`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain %q, but got:\n%v", line, output)
+		}
+	}
+
+	// metrics are also served over http
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Body.String() != output {
+		t.Errorf("Expected served metrics to match written metrics:\n%v", rec.Body.String())
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if actual := escape("a\"b\\c\nd"); actual != `a\"b\\c\nd` {
+		t.Fatalf("Unexpected escaped label value %q", actual)
+	}
+}
@@ -0,0 +1,69 @@
+package tcclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type metricsRecorder []CallMetrics
+
+func (r *metricsRecorder) RecordCall(m CallMetrics) {
+	*r = append(*r, m)
+}
+
+func TestMetricsErrorClasses(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conflict":
+			w.WriteHeader(409)
+		case "/broken":
+			w.WriteHeader(500)
+		}
+	}))
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+	defer s.Close()
+	recorder := new(metricsRecorder)
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: NoRetries,
+		Metrics:     recorder,
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	testCases := []struct {
+		ctx        context.Context
+		baseURL    string
+		route      string
+		statusCode int
+		errorClass string
+	}{
+		{WithEndpoint(nil, "queue", "task"), s.URL, "/ok", 200, ""},
+		{nil, s.URL, "/conflict", 409, ErrorClassClientError},
+		{nil, s.URL, "/broken", 500, ErrorClassServerError},
+		{nil, closed.URL, "/ok", 0, ErrorClassNetwork},
+		{cancelled, s.URL, "/ok", 0, ErrorClassCanceled},
+	}
+	for _, tc := range testCases {
+		client.BaseURL = tc.baseURL
+		client.RequestWithContext(tc.ctx, nil, "GET", tc.route, nil)
+	}
+	if len(*recorder) != len(testCases) {
+		t.Fatalf("Expected %v calls to be recorded, but got %v", len(testCases), len(*recorder))
+	}
+	for i, tc := range testCases {
+		m := (*recorder)[i]
+		if m.StatusCode != tc.statusCode || m.ErrorClass != tc.errorClass || m.Attempts != 1 || m.Method != "GET" {
+			t.Errorf("Call to %v: unexpected metrics %#v", tc.route, m)
+		}
+	}
+	if (*recorder)[0].Endpoint != (Endpoint{Service: "queue", Name: "task"}) {
+		t.Errorf("Expected endpoint to be recorded, but got %#v", (*recorder)[0].Endpoint)
+	}
+	if errorClass(errors.New("bad json")) != ErrorClassOther {
+		t.Errorf("Expected other errors to be classified as %q", ErrorClassOther)
+	}
+}
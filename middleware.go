@@ -0,0 +1,71 @@
+package tcclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoundTripFunc performs a single http request attempt.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware intercepts every http request attempt made by a Client. It
+// receives the outgoing (already signed) request and the next RoundTripFunc
+// in the chain, and returns the http response or error that the Client will
+// see. A Middleware may inspect or alter the request (e.g. add headers) before
+// calling next, inspect the response or error returned by next (e.g. for
+// logging or metrics), or not call next at all (e.g. for fault injection).
+//
+// Note, altering the method or URL of the request will invalidate its Hawk
+// signature.
+//
+// The Taskcluster API endpoint that the request is for can be retrieved with
+// EndpointFromContext(req.Context()).
+type Middleware func(req *http.Request, next RoundTripFunc) (*http.Response, error)
+
+// Endpoint identifies a Taskcluster API endpoint.
+type Endpoint struct {
+	// Name of the service, e.g. "queue"
+	Service string
+	// Name of the API method, as listed in the service's API reference, e.g.
+	// "createTask"
+	Name string
+}
+
+type endpointKey struct{}
+
+// WithEndpoint returns a copy of ctx that records the API endpoint being
+// called, so that it is available to middleware. The generated API methods
+// call this for you. A nil ctx is treated as context.Background().
+func WithEndpoint(ctx context.Context, service, name string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, endpointKey{}, Endpoint{Service: service, Name: name})
+}
+
+// EndpointFromContext returns the API endpoint recorded in ctx by
+// WithEndpoint, if any.
+func EndpointFromContext(ctx context.Context) (endpoint Endpoint, ok bool) {
+	if ctx == nil {
+		return
+	}
+	endpoint, ok = ctx.Value(endpointKey{}).(Endpoint)
+	return
+}
+
+// roundTrip sends req via the client's middleware chain, and finally via the
+// client's HTTPClient (or the default http client, if not set).
+func (client *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	next := RoundTripFunc(httpClient.Do)
+	for i := len(client.Middleware) - 1; i >= 0; i-- {
+		middleware, inner := client.Middleware[i], next
+		next = func(req *http.Request) (*http.Response, error) {
+			return middleware(req, inner)
+		}
+	}
+	return next(req)
+}
@@ -0,0 +1,98 @@
+package tcclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen", r.Header.Get("X-Injected"))
+		w.Write([]byte(`{}`))
+	}))
+	defer s.Close()
+	var calls []string
+	tracer := func(name string) Middleware {
+		return func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+			calls = append(calls, name+" request")
+			req.Header.Set("X-Injected", req.Header.Get("X-Injected")+name)
+			resp, err := next(req)
+			calls = append(calls, name+" response")
+			return resp, err
+		}
+	}
+	client := Client{
+		BaseURL:    s.URL,
+		Middleware: []Middleware{tracer("a"), tracer("b")},
+	}
+	cs, err := client.Request(nil, "GET", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"a request", "b request", "b response", "a response"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected middleware calls %v but got %v", expected, calls)
+	}
+	if seen := cs.HTTPResponse.Header.Get("X-Seen"); seen != "ab" {
+		t.Errorf("Expected server to see injected header %q but got %q", "ab", seen)
+	}
+}
+
+func TestMiddlewareEndpoint(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer s.Close()
+	var endpoint Endpoint
+	var found bool
+	client := Client{
+		BaseURL: s.URL,
+		Middleware: []Middleware{
+			func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+				endpoint, found = EndpointFromContext(req.Context())
+				return next(req)
+			},
+		},
+	}
+	ctx := WithEndpoint(nil, "queue", "task")
+	_, _, err := client.APICallWithContext(ctx, nil, "GET", "/task/abc", new(struct{}), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !found || endpoint != (Endpoint{Service: "queue", Name: "task"}) {
+		t.Errorf("Expected endpoint queue/task but got %#v (found: %v)", endpoint, found)
+	}
+	if _, found := EndpointFromContext(nil); found {
+		t.Error("Did not expect an endpoint in a nil context")
+	}
+}
+
+func TestMiddlewareFaultInjection(t *testing.T) {
+	s, requests := failingServer(0, 200, nil)
+	defer s.Close()
+	injected := errors.New("injected fault")
+	attempts := 0
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: fastRetries,
+		Middleware: []Middleware{
+			func(req *http.Request, next RoundTripFunc) (*http.Response, error) {
+				attempts++
+				if attempts < 3 {
+					return nil, injected
+				}
+				return next(req)
+			},
+		},
+	}
+	cs, err := client.Request(nil, "GET", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cs.Attempts != 3 || *requests != 1 {
+		t.Fatalf("Expected 3 attempts and 1 request, but got %v attempts and %v requests", cs.Attempts, *requests)
+	}
+}
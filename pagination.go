@@ -0,0 +1,137 @@
+package tcclient
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// ErrRepeatedContinuationToken is returned by Pager.Err if a service returns
+// a continuation token that it has already returned for an earlier page of
+// the same listing. Following it would never terminate, or would return
+// duplicate results.
+var ErrRepeatedContinuationToken = errors.New("tcclient: service returned a continuation token it had already returned")
+
+// PageFetcher fetches a single page of results from a paginated API method.
+// continuationToken is the empty string for the first page, and limit is the
+// empty string if the service default page size should be used. It returns
+// the number of items on the page and the continuation token of the next
+// page, or the empty string if this was the last page.
+type PageFetcher func(ctx context.Context, continuationToken, limit string) (n int, nextContinuationToken string, err error)
+
+// Pager walks through the items of a paginated API method, fetching each
+// page when the items of the previous page have been consumed. It is
+// embedded in the XXXIterator types of the generated packages, which add an
+// Item method to return the current item. A typical loop looks like:
+//
+//  it := myQueue.ListTaskGroupAll(ctx, taskGroupID)
+//  for it.Next() {
+//  	task := it.Item()
+//  	...
+//  }
+//  if err := it.Err(); err != nil {
+//  	...
+//  }
+//
+// A page without items is not taken to be the last page: pages are fetched
+// until the service no longer returns a continuation token. If ctx is
+// cancelled, Next returns false immediately, and Err returns ctx.Err(), so
+// a partial result is never mistaken for a complete one.
+type Pager struct {
+	// Maximum number of items to request per page (0 means the service
+	// default). It may be changed between calls to Next, and applies to
+	// all subsequent page requests.
+	PageSize int
+
+	ctx   context.Context
+	fetch PageFetcher
+	// continuation token of the next page
+	token string
+	// continuation tokens returned so far
+	seen map[string]bool
+	// true once the last page has been fetched
+	done  bool
+	n     int
+	index int
+	pages int
+	err   error
+}
+
+// NewPager returns a *Pager which fetches pages with fetch, bound to ctx.
+func NewPager(ctx context.Context, fetch PageFetcher) *Pager {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Pager{
+		ctx:   ctx,
+		fetch: fetch,
+		seen:  map[string]bool{},
+		index: -1,
+	}
+}
+
+// Next advances to the next item, fetching the next page if required. It
+// returns false when there are no more items, or when an error occurred, in
+// which case Err returns it.
+func (p *Pager) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	if err := p.ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+	p.index++
+	for p.index >= p.n {
+		if p.done {
+			return false
+		}
+		if !p.fetchPage() {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchPage fetches the next page, returning false if an error occurred
+func (p *Pager) fetchPage() bool {
+	limit := ""
+	if p.PageSize > 0 {
+		limit = strconv.Itoa(p.PageSize)
+	}
+	n, next, err := p.fetch(p.ctx, p.token, limit)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	if next != "" {
+		if p.seen[next] {
+			p.err = ErrRepeatedContinuationToken
+			return false
+		}
+		p.seen[next] = true
+	}
+	p.pages++
+	p.token = next
+	p.done = next == ""
+	p.n = n
+	p.index = 0
+	return true
+}
+
+// Index returns the index of the current item within the current page.
+func (p *Pager) Index() int {
+	return p.index
+}
+
+// Pages returns the number of pages fetched so far.
+func (p *Pager) Pages() int {
+	return p.pages
+}
+
+// Err returns the error that ended the iteration, if any. It should be
+// checked once Next has returned false, since otherwise a failed or
+// cancelled listing is indistinguishable from a complete one.
+func (p *Pager) Err() error {
+	return p.err
+}
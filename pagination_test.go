@@ -0,0 +1,124 @@
+package tcclient
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeListing serves pages of items in the style of a paginated
+// Taskcluster API method
+type fakeListing struct {
+	pages  [][]string
+	tokens []string
+	limits []string
+	// items of the page most recently fetched
+	items []string
+}
+
+func (l *fakeListing) fetch(ctx context.Context, continuationToken, limit string) (int, string, error) {
+	l.tokens = append(l.tokens, continuationToken)
+	l.limits = append(l.limits, limit)
+	i := 0
+	if continuationToken != "" {
+		var err error
+		i, err = strconv.Atoi(strings.TrimPrefix(continuationToken, "page"))
+		if err != nil || i >= len(l.pages) {
+			return 0, "", errors.New("unknown continuation token " + continuationToken)
+		}
+	}
+	l.items = l.pages[i]
+	next := ""
+	if i+1 < len(l.pages) {
+		next = "page" + strconv.Itoa(i+1)
+	}
+	return len(l.items), next, nil
+}
+
+func TestPager(t *testing.T) {
+	listing := &fakeListing{
+		pages: [][]string{{"a", "b"}, {}, {"c"}, {}},
+	}
+	p := NewPager(context.Background(), listing.fetch)
+	p.PageSize = 2
+	got := []string{}
+	for p.Next() {
+		got = append(got, listing.items[p.Index()])
+	}
+	if err := p.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Expected items a, b, c but got %v", got)
+	}
+	if !reflect.DeepEqual(listing.tokens, []string{"", "page1", "page2", "page3"}) {
+		t.Errorf("Unexpected continuation tokens %q", listing.tokens)
+	}
+	if !reflect.DeepEqual(listing.limits, []string{"2", "2", "2", "2"}) {
+		t.Errorf("Unexpected limits %q", listing.limits)
+	}
+	if p.Pages() != 4 {
+		t.Errorf("Expected 4 pages but got %v", p.Pages())
+	}
+	if p.Next() {
+		t.Error("Expected Next to keep returning false after the last item")
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	calls := 0
+	fetchErr := errors.New("service unavailable")
+	p := NewPager(context.Background(), func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		calls++
+		if calls == 2 {
+			return 0, "", fetchErr
+		}
+		return 1, "more", nil
+	})
+	items := 0
+	for p.Next() {
+		items++
+	}
+	if items != 1 || p.Err() != fetchErr {
+		t.Errorf("Expected one item and error %v, but got %v items and error %v", fetchErr, items, p.Err())
+	}
+}
+
+func TestPagerRepeatedContinuationToken(t *testing.T) {
+	p := NewPager(context.Background(), func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		return 1, "x", nil
+	})
+	items := 0
+	for p.Next() {
+		items++
+	}
+	if items != 1 || p.Err() != ErrRepeatedContinuationToken {
+		t.Errorf("Expected one item and ErrRepeatedContinuationToken, but got %v items and error %v", items, p.Err())
+	}
+}
+
+func TestPagerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fetches := 0
+	p := NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		fetches++
+		return 3, "more", nil
+	})
+	items := 0
+	for p.Next() {
+		items++
+		if items == 2 {
+			cancel()
+		}
+	}
+	if items != 2 || fetches != 1 {
+		t.Errorf("Expected iteration to stop after 2 items and 1 fetch, but got %v items and %v fetches", items, fetches)
+	}
+	if p.Err() != context.Canceled {
+		t.Errorf("Expected context.Canceled but got %v", p.Err())
+	}
+}
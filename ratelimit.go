@@ -0,0 +1,133 @@
+package tcclient
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate at which http requests are made, using a token
+// bucket, and/or the number of http requests that are in flight at any one
+// time. Every attempt (including retries) of every API call made by a Client
+// with a RateLimiter must first acquire a token and an in-flight slot.
+//
+// Since the Client struct is copied by the generated API methods, the
+// RateLimiter is referenced by pointer, so that all copies share the same
+// limits. To limit calls per service, share one RateLimiter between all
+// clients of that service, e.g.
+//
+//  queueLimiter := tcclient.NewRateLimiter(50, 10, 20)
+//  queue1.RateLimiter = queueLimiter
+//  queue2.RateLimiter = queueLimiter
+//
+// A RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	requestsPerSecond float64
+	burst             float64
+	mu                sync.Mutex
+	tokens            float64
+	last              time.Time
+	// inFlight has one entry per request in flight; nil if unlimited
+	inFlight chan struct{}
+}
+
+// NewRateLimiter returns a RateLimiter that allows requestsPerSecond http
+// requests per second on average, with bursts of up to burst requests, and at
+// most maxInFlight concurrent requests. If requestsPerSecond is zero or
+// negative, the request rate is not limited; if maxInFlight is zero or
+// negative, the number of concurrent requests is not limited. A burst of less
+// than one is treated as one.
+func NewRateLimiter(requestsPerSecond float64, burst, maxInFlight int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &RateLimiter{
+		requestsPerSecond: requestsPerSecond,
+		burst:             float64(burst),
+		tokens:            float64(burst),
+		last:              time.Now(),
+	}
+	if maxInFlight > 0 {
+		l.inFlight = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+// Acquire blocks until a request may be made, or ctx is done, in which case
+// ctx.Err() is returned. A nil ctx never expires. If err is nil, the caller
+// must call release once the request is complete, to free its in-flight slot.
+func (l *RateLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	release = func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-done:
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-l.inFlight })
+		}
+	}
+	wait := l.reserve()
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-done:
+			timer.Stop()
+			l.unreserve()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// reserve takes a token from the bucket, and returns how long the caller
+// must wait before the token is available.
+func (l *RateLimiter) reserve() time.Duration {
+	if l.requestsPerSecond <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.requestsPerSecond
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.requestsPerSecond * float64(time.Second))
+}
+
+// unreserve returns a token that was reserved but not used.
+func (l *RateLimiter) unreserve() {
+	if l.requestsPerSecond <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// releaseOnClose calls release when the wrapped response body is closed, so
+// that the in-flight slot is held until the response has been consumed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,99 @@
+package tcclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRate(t *testing.T) {
+	s, requests := failingServer(0, 200, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RateLimiter: NewRateLimiter(20, 2, 0),
+	}
+	// copies of the client, as made by the generated API methods, share the
+	// limiter
+	clientCopy := client
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		c := client
+		if i%2 == 1 {
+			c = clientCopy
+		}
+		_, err := c.Request(nil, "GET", "/whatever", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// burst of 2, then 4 requests at 20 per second
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Fatalf("Expected 6 requests to take at least 200ms, but took %v", elapsed)
+	}
+	if *requests != 6 {
+		t.Fatalf("Expected 6 requests, but got %v", *requests)
+	}
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RateLimiter: NewRateLimiter(0, 0, 3),
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := client.APICall(nil, "GET", "/whatever", new(struct{}), nil)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInFlight > 3 {
+		t.Fatalf("Expected at most 3 requests in flight, but got %v", maxInFlight)
+	}
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	s, requests := failingServer(0, 200, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RateLimiter: NewRateLimiter(0.001, 1, 0),
+	}
+	_, err := client.Request(nil, "GET", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.RequestWithContext(ctx, nil, "GET", "/whatever", nil)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected deadline exceeded error while waiting for rate limiter, but got %v", err)
+	}
+	if *requests != 1 {
+		t.Fatalf("Expected 1 request, but got %v", *requests)
+	}
+}
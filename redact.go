@@ -0,0 +1,146 @@
+package tcclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/taskcluster/jsonschema2go/text"
+)
+
+// Redactor masks secrets in the output of CallSummary.String (and therefore
+// also in APICallException error messages), so that failed API calls can be
+// logged safely. Values are masked with text.StarOut, in the same way as
+// Credentials.String masks access tokens.
+type Redactor struct {
+	// Names of http request and response headers whose values should be
+	// masked, e.g. "Authorization"
+	Headers []string
+	// Names of URL query string parameters whose values should be masked,
+	// e.g. "bewit"
+	QueryParameters []string
+	// Names of json object properties, at any depth of a json request or
+	// response body, whose values should be masked, e.g. "accessToken". If
+	// the value is not a string, it is replaced in its entirety.
+	JSONFields []string
+}
+
+// DefaultRedactor is used by clients that do not specify a Redactor. It masks
+// Hawk authentication headers, bewit signatures, and the access tokens,
+// certificates and secret values returned by Taskcluster services.
+var DefaultRedactor = &Redactor{
+	Headers:         []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+	QueryParameters: []string{"bewit"},
+	JSONFields:      []string{"accessToken", "certificate", "secret"},
+}
+
+// NoRedaction is a Redactor that does not mask anything. Beware that with it,
+// credentials and secrets may appear in logs.
+var NoRedaction = &Redactor{}
+
+// Header returns a copy of header with the values of r.Headers masked.
+func (r *Redactor) Header(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+	redacted := make(http.Header, len(header))
+	for k, v := range header {
+		redacted[k] = v
+	}
+	for _, name := range r.Headers {
+		name = http.CanonicalHeaderKey(name)
+		values, present := redacted[name]
+		if !present {
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, value := range values {
+			masked[i] = text.StarOut(value)
+		}
+		redacted[name] = masked
+	}
+	return redacted
+}
+
+// URL returns a copy of u with the values of r.QueryParameters masked.
+func (r *Redactor) URL(u *url.URL) *url.URL {
+	if u == nil {
+		return nil
+	}
+	redacted := *u
+	query := redacted.Query()
+	changed := false
+	for _, name := range r.QueryParameters {
+		values, present := query[name]
+		if !present {
+			continue
+		}
+		for i, value := range values {
+			values[i] = text.StarOut(value)
+		}
+		changed = true
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return &redacted
+}
+
+// Body returns body with the values of r.JSONFields masked. Bodies that are
+// not valid json, or that contain none of r.JSONFields, are returned
+// unchanged.
+func (r *Redactor) Body(body string) string {
+	if len(r.JSONFields) == 0 || body == "" {
+		return body
+	}
+	var doc interface{}
+	if json.Unmarshal([]byte(body), &doc) != nil {
+		return body
+	}
+	if !r.redactJSON(doc) {
+		return body
+	}
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return string(redacted)
+}
+
+// redactJSON masks r.JSONFields in the given unmarshaled json value, in place,
+// and reports whether anything was masked.
+func (r *Redactor) redactJSON(value interface{}) (changed bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if r.jsonField(key) {
+				if s, isString := child.(string); isString {
+					v[key] = text.StarOut(s)
+				} else {
+					v[key] = "*****"
+				}
+				changed = true
+				continue
+			}
+			if r.redactJSON(child) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if r.redactJSON(child) {
+				changed = true
+			}
+		}
+	}
+	return
+}
+
+func (r *Redactor) jsonField(name string) bool {
+	for _, field := range r.JSONFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
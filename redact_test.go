@@ -0,0 +1,106 @@
+package tcclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCallSummaryRedaction(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		w.Write([]byte(`{"secret": {"password": "hunter2-response"}, "credentials": {"clientId": "someone", "accessToken": "response-token-abcd"}}`))
+	}))
+	defer s.Close()
+	client := Client{
+		Credentials: &Credentials{
+			ClientID:    "tester",
+			AccessToken: "not-a-real-token",
+		},
+		BaseURL:      s.URL,
+		Authenticate: true,
+		RetryPolicy:  NoRetries,
+	}
+	_, _, err := client.APICall(
+		map[string]string{"accessToken": "request-token-wxyz"},
+		"PUT", "/whatever?bewit=secret-bewit-value", new(struct{}), nil,
+	)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	msg := err.(*APICallException).CallSummary.String()
+	for _, secret := range []string{"hunter2-response", "response-token", "request-token", "Hawk id=", "secret-bewit"} {
+		if strings.Contains(msg, secret) {
+			t.Errorf("Expected %q to be redacted from call summary:\n%v", secret, msg)
+		}
+	}
+	// the error message also includes the response body
+	for _, secret := range []string{"hunter2-response", "response-token", "request-token", "Hawk id=", "secret-bewit"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("Expected %q to be redacted from error message:\n%v", secret, err)
+		}
+	}
+	for _, visible := range []string{"someone", "abcd", "wxyz"} {
+		if !strings.Contains(msg, visible) {
+			t.Errorf("Expected %q to be visible in call summary:\n%v", visible, msg)
+		}
+	}
+}
+
+func TestErrorMessageRedaction(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		w.Write([]byte(`{"code": "InputError", "message": "bad input", "secret": {"password": "hunter2-response"}}`))
+	}))
+	defer s.Close()
+	client := Client{
+		BaseURL:      s.URL,
+		Authenticate: false,
+	}
+	_, _, err := client.APICall(nil, "GET", "/whatever", new(struct{}), nil)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if strings.Contains(err.Error(), "hunter2-response") {
+		t.Errorf("Expected secret to be redacted from error message:\n%v", err)
+	}
+	if !strings.Contains(err.Error(), "bad input") {
+		t.Errorf("Expected error message to include the response body:\n%v", err)
+	}
+	_, err = client.Request(nil, "GET", "/whatever", nil)
+	if err == nil || strings.Contains(err.Error(), "hunter2-response") {
+		t.Errorf("Expected secret to be redacted from error message:\n%v", err)
+	}
+
+	client.Redactor = NoRedaction
+	_, _, err = client.APICall(nil, "GET", "/whatever", new(struct{}), nil)
+	if err == nil || !strings.Contains(err.Error(), "hunter2-response") {
+		t.Errorf("Expected secret to be visible without redaction:\n%v", err)
+	}
+}
+
+func TestNoRedaction(t *testing.T) {
+	cs := &CallSummary{
+		HTTPRequestBody: `{"accessToken": "request-token-wxyz"}`,
+		Redactor:        NoRedaction,
+	}
+	if !strings.Contains(cs.String(), "request-token-wxyz") {
+		t.Fatalf("Expected access token to be visible:\n%v", cs)
+	}
+}
+
+func TestRedactorBody(t *testing.T) {
+	r := &Redactor{JSONFields: []string{"password"}}
+	testCases := map[string]string{
+		`not json password`:                     `not json password`,
+		`{"user": "me"}`:                        `{"user": "me"}`,
+		`[{"password": "abcdefgh"}]`:            `[{"password":"****efgh"}]`,
+		`{"nested": {"password": {"x": "yz"}}}`: `{"nested":{"password":"*****"}}`,
+	}
+	for body, expected := range testCases {
+		if actual := r.Body(body); actual != expected {
+			t.Errorf("Expected %q to be redacted to %q but got %q", body, expected, actual)
+		}
+	}
+}
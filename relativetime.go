@@ -0,0 +1,148 @@
+package tcclient
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// relativeTime matches the relative time expressions understood by the
+// Taskcluster clients in other languages, e.g. "2 days 3 hours" or
+// "-1 year", see https://github.com/taskcluster/taskcluster-client#relative-date-time-utilities
+var relativeTime = regexp.MustCompile(`^(?:\s*([-+]))?` +
+	`(?:\s*(\d+)\s*y(?:ears?|r)?)?` +
+	`(?:\s*(\d+)\s*mo(?:nths?|n)?)?` +
+	`(?:\s*(\d+)\s*w(?:eeks?|k)?)?` +
+	`(?:\s*(\d+)\s*d(?:ays?)?)?` +
+	`(?:\s*(\d+)\s*h(?:ours?|r)?)?` +
+	`(?:\s*(\d+)\s*m(?:in(?:utes?)?)?)?` +
+	`(?:\s*(\d+)\s*s(?:ec(?:onds?)?)?)?` +
+	`\s*$`)
+
+// Offset is a parsed relative time expression. As in the Taskcluster clients
+// in other languages, a year is 365 days and a month is 30 days, whatever
+// time they are added to, so that an expression means the same in every
+// language.
+type Offset struct {
+	Years    int
+	Months   int
+	Duration time.Duration
+}
+
+// ParseOffset parses a relative time expression, such as "2 days 3 hours",
+// "1 year", "-30 minutes" or "1mo 2w". Units are years (y, yr, year,
+// years), months (mo, mon, month, months), weeks (w, wk, week, weeks), days (d,
+// day, days), hours (h, hr, hour, hours), minutes (m, min, minute, minutes)
+// and seconds (s, sec, second, seconds), and must be given in that order.
+// A leading "-" or "+" applies to the whole expression. It is an error if the
+// offset is too large to be represented as a time.Duration (about 292
+// years).
+func ParseOffset(offset string) (Offset, error) {
+	match := relativeTime.FindStringSubmatch(offset)
+	if match == nil {
+		return Offset{}, fmt.Errorf("tcclient: invalid relative time expression %q", offset)
+	}
+	sign := 1
+	if match[1] == "-" {
+		sign = -1
+	}
+	number := func(i int) (int, error) {
+		if match[i] == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(match[i])
+		if err != nil {
+			return 0, fmt.Errorf("tcclient: invalid number in relative time expression %q: %v", offset, err)
+		}
+		return sign * n, nil
+	}
+	units := []struct {
+		group int
+		unit  time.Duration
+	}{
+		{2, 365 * 24 * time.Hour},
+		{3, 30 * 24 * time.Hour},
+		{4, 7 * 24 * time.Hour},
+		{5, 24 * time.Hour},
+		{6, time.Hour},
+		{7, time.Minute},
+		{8, time.Second},
+	}
+	var o Offset
+	// the whole offset must fit in a time.Duration, so that From cannot
+	// overflow either
+	var total time.Duration
+	for _, u := range units {
+		n, err := number(u.group)
+		if err != nil {
+			return Offset{}, err
+		}
+		limit := int64(math.MaxInt64 / u.unit)
+		if int64(n) > limit || int64(n) < -limit {
+			return Offset{}, fmt.Errorf("tcclient: relative time expression %q is out of range", offset)
+		}
+		d := time.Duration(n) * u.unit
+		if (d > 0 && total > math.MaxInt64-d) || (d < 0 && total < math.MinInt64-d) {
+			return Offset{}, fmt.Errorf("tcclient: relative time expression %q is out of range", offset)
+		}
+		total += d
+		switch u.group {
+		case 2:
+			o.Years = n
+		case 3:
+			o.Months = n
+		default:
+			o.Duration += d
+		}
+	}
+	return o, nil
+}
+
+// From returns t offset by o.
+func (o Offset) From(t time.Time) Time {
+	days := 365*o.Years + 30*o.Months
+	return Time(t.Add(time.Duration(days)*24*time.Hour + o.Duration))
+}
+
+// Clock returns the current time. Tests can use a fixed Clock to make
+// relative times deterministic.
+type Clock func() time.Time
+
+// SystemClock returns the local time, adjusted by ClockOffset() to
+// approximate the clock of the Taskcluster services.
+func SystemClock() time.Time {
+	return time.Now().Add(ClockOffset())
+}
+
+// FromNow returns the time offset from the current time of clock by the
+// relative time expression offset (see ParseOffset).
+func (clock Clock) FromNow(offset string) (Time, error) {
+	o, err := ParseOffset(offset)
+	if err != nil {
+		return Time{}, err
+	}
+	return o.From(clock()), nil
+}
+
+// FromNow returns the time offset from now (according to SystemClock) by
+// the relative time expression offset, e.g. FromNow("2 days 3 hours"). See
+// ParseOffset for the syntax of offset.
+func FromNow(offset string) (Time, error) {
+	return Clock(SystemClock).FromNow(offset)
+}
+
+// MustFromNow is like FromNow but panics if offset cannot be parsed. It is
+// intended for relative times which are constants, e.g. when building task
+// definitions:
+//
+//  Deadline: tcclient.MustFromNow("1 day"),
+//  Expires:  tcclient.MustFromNow("1 year"),
+func MustFromNow(offset string) Time {
+	t, err := FromNow(offset)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
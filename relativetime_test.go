@@ -0,0 +1,92 @@
+package tcclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromNow(t *testing.T) {
+	now := time.Date(2019, time.January, 31, 12, 0, 0, 0, time.UTC)
+	clock := Clock(func() time.Time { return now })
+	testCases := []struct {
+		offset   string
+		expected time.Time
+	}{
+		{"", now},
+		{"0 seconds", now},
+		{"1 day", now.Add(24 * time.Hour)},
+		{"2 days 3 hours", now.Add(51 * time.Hour)},
+		{"  -1 year", now.Add(-365 * 24 * time.Hour)},
+		{"+1d", now.Add(24 * time.Hour)},
+		{"1mo", now.Add(30 * 24 * time.Hour)},
+		{"- 2 weeks", now.Add(-14 * 24 * time.Hour)},
+		{"1wk", now.Add(7 * 24 * time.Hour)},
+		{"1 yr 2 mo 3 w 4 d 5 h 6 min 7 s", now.Add((365+60+25)*24*time.Hour + 5*time.Hour + 6*time.Minute + 7*time.Second)},
+		{"-2 hours 30 minutes", now.Add(-150 * time.Minute)},
+		{"45m", now.Add(45 * time.Minute)},
+		{"3 months", now.Add(90 * 24 * time.Hour)},
+		// as in the reference clients, a year is 365 days and a month is 30
+		// days, e.g. fromNow('1 year', new Date('2019-01-31T12:00:00Z')) is
+		// 2020-01-31T12:00:00.000Z but fromNow('1 mon', ...) is
+		// 2019-03-02T12:00:00.000Z
+		{"1 year", time.Date(2020, time.January, 31, 12, 0, 0, 0, time.UTC)},
+		{"1 mon", time.Date(2019, time.March, 2, 12, 0, 0, 0, time.UTC)},
+		{"2mon", now.Add(60 * 24 * time.Hour)},
+		{"10 sec", now.Add(10 * time.Second)},
+		{"1 hr 1 second", now.Add(time.Hour + time.Second)},
+		{"290 years", now.Add(290 * 365 * 24 * time.Hour)},
+	}
+	for _, tc := range testCases {
+		got, err := clock.FromNow(tc.offset)
+		if err != nil {
+			t.Errorf("FromNow(%q): unexpected error %v", tc.offset, err)
+			continue
+		}
+		if !got.Time().Equal(tc.expected) {
+			t.Errorf("FromNow(%q) = %v, expected %v", tc.offset, got, Time(tc.expected))
+		}
+	}
+	// a year is 365 days even in a leap year, e.g. fromNow('1 year', new
+	// Date('2020-01-01T00:00:00Z')) is 2020-12-31T00:00:00.000Z
+	leapClock := Clock(func() time.Time { return time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC) })
+	if got, err := leapClock.FromNow("1 year"); err != nil || !got.Time().Equal(time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("FromNow(%q) in a leap year = %v, %v, expected 2020-12-31", "1 year", got, err)
+	}
+	for _, offset := range []string{"tomorrow", "1 minute 2 hours", "1 day -2 hours", "1.5 days", "2 fortnights", "99999999999999999999 days", "300 years", "-300 years", "250 years 600 months", "100000 weeks", "3000000 hours", "292 years 1000 days"} {
+		if _, err := clock.FromNow(offset); err == nil {
+			t.Errorf("FromNow(%q): expected an error", offset)
+		}
+	}
+}
+
+func TestMustFromNow(t *testing.T) {
+	before := time.Now()
+	deadline := MustFromNow("1 day")
+	if d := deadline.Time().Sub(before); d < 24*time.Hour-time.Second || d > 24*time.Hour+time.Second {
+		t.Errorf("Expected deadline in around 1 day, but got %v", deadline)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected MustFromNow to panic for an invalid expression")
+		}
+	}()
+	MustFromNow("soon")
+}
+
+func TestTimeHelpers(t *testing.T) {
+	created := Time(time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC))
+	deadline := created.Add(24 * time.Hour)
+	expires := created.AddDate(1, 0, 0)
+	if !created.Before(deadline) || !expires.After(deadline) || deadline.Before(created) {
+		t.Error("Unexpected ordering of times")
+	}
+	if deadline.Sub(created) != 24*time.Hour {
+		t.Errorf("Expected 24h between created and deadline, but got %v", deadline.Sub(created))
+	}
+	if !expires.Equal(Time(time.Date(2020, time.June, 1, 2, 0, 0, 0, time.FixedZone("CEST", 2*60*60)))) {
+		t.Errorf("Expected expires to equal the same instant in another zone, but got %v", expires)
+	}
+	if created.IsZero() || !(Time{}).IsZero() {
+		t.Error("Unexpected result from IsZero")
+	}
+}
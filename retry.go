@@ -0,0 +1,130 @@
+package tcclient
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy determines whether, and after what delay, a failed http request
+// made by Client.Request should be retried. A request has failed if a network
+// error occurred, or if the http response status code is outside of the 2xx
+// range.
+//
+// A RetryPolicy may be shared between many clients and goroutines, so
+// implementations must be safe for concurrent use.
+type RetryPolicy interface {
+	// RetryDelay is called after each failed attempt. attempts is the number
+	// of attempts made so far (1 after the first failure), and elapsed is the
+	// time since the first attempt started. Exactly one of resp and err is
+	// non-nil. If retry is false, no further attempts are made; otherwise the
+	// request is retried after the returned delay.
+	RetryDelay(attempts int, elapsed time.Duration, req *http.Request, resp *http.Response, err error) (delay time.Duration, retry bool)
+}
+
+// ExponentialBackoff is a RetryPolicy that retries network failures and
+// selected http status codes with exponentially increasing delays between
+// attempts. If the service sends a Retry-After response header, the delay is
+// at least as long as the header requests.
+type ExponentialBackoff struct {
+	// Delay after the first failed attempt, before randomization
+	InitialInterval time.Duration
+	// Factor by which the delay increases after each further failed attempt
+	Multiplier float64
+	// The delay is randomized to lie within RandomizationFactor * delay of
+	// the calculated value (0 means no jitter)
+	RandomizationFactor float64
+	// Upper bound for the calculated delay, before randomization
+	MaxInterval time.Duration
+	// No further attempts are made once this much time has elapsed since
+	// the first attempt started (0 means no limit)
+	MaxElapsedTime time.Duration
+	// Maximum number of attempts, including the first (0 means no limit)
+	MaxAttempts int
+	// HTTP status codes that should be retried. If nil, all 5xx status codes
+	// are retried.
+	RetryStatusCodes []int
+	// If true, requests that use non-idempotent http methods (POST and PATCH)
+	// are never retried.
+	IdempotentOnly bool
+}
+
+// DefaultRetryPolicy is used by clients that do not specify a RetryPolicy.
+// It matches the settings that github.com/taskcluster/httpbackoff uses:
+// network failures and 5xx responses are retried for up to 15 minutes,
+// starting with a delay of around 500ms, growing to at most around one minute.
+var DefaultRetryPolicy RetryPolicy = &ExponentialBackoff{
+	InitialInterval:     500 * time.Millisecond,
+	Multiplier:          1.5,
+	RandomizationFactor: 0.5,
+	MaxInterval:         time.Minute,
+	MaxElapsedTime:      15 * time.Minute,
+}
+
+// NoRetries is a RetryPolicy that never retries failed requests, which is
+// useful for latency sensitive callers that prefer to fail fast.
+var NoRetries RetryPolicy = noRetries{}
+
+type noRetries struct{}
+
+func (noRetries) RetryDelay(attempts int, elapsed time.Duration, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	return 0, false
+}
+
+// RetryDelay implements the RetryPolicy interface.
+func (b *ExponentialBackoff) RetryDelay(attempts int, elapsed time.Duration, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if b.MaxAttempts > 0 && attempts >= b.MaxAttempts {
+		return 0, false
+	}
+	if b.MaxElapsedTime > 0 && elapsed > b.MaxElapsedTime {
+		return 0, false
+	}
+	if b.IdempotentOnly && req != nil && (req.Method == "POST" || req.Method == "PATCH") {
+		return 0, false
+	}
+	if resp != nil && !b.retryStatusCode(resp.StatusCode) {
+		return 0, false
+	}
+	interval := float64(b.InitialInterval) * math.Pow(b.Multiplier, float64(attempts-1))
+	if b.MaxInterval > 0 && interval > float64(b.MaxInterval) {
+		interval = float64(b.MaxInterval)
+	}
+	delta := b.RandomizationFactor * interval
+	delay := time.Duration(interval - delta + rand.Float64()*(2*delta))
+	if resp != nil {
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > delay {
+			delay = retryAfter
+		}
+	}
+	return delay, true
+}
+
+func (b *ExponentialBackoff) retryStatusCode(statusCode int) bool {
+	if b.RetryStatusCodes == nil {
+		return statusCode/100 == 5
+	}
+	for _, code := range b.RetryStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRetryAfter returns the delay requested by a Retry-After http response
+// header, which may either be a number of seconds or an http date. Zero is
+// returned if the header is empty or cannot be parsed.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package tcclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/taskcluster/httpbackoff"
+)
+
+// fastRetries retries quickly, so that tests do not take long
+var fastRetries = &ExponentialBackoff{
+	InitialInterval: time.Millisecond,
+	Multiplier:      1.5,
+	MaxInterval:     10 * time.Millisecond,
+	MaxAttempts:     5,
+}
+
+// failingServer returns a test server that responds to the first `failures`
+// requests with the given http status code, and then with 200 OK.
+func failingServer(failures int32, statusCode int, header http.Header) (*httptest.Server, *int32) {
+	var requests int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(statusCode)
+			w.Write([]byte(`{"code": "InternalServerError", "message": "oops"}`))
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte(`{}`))
+	}))
+	return s, &requests
+}
+
+func TestRetryIntermittentFailures(t *testing.T) {
+	s, requests := failingServer(2, 500, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: fastRetries,
+	}
+	cs, err := client.Request(nil, "GET", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cs.Attempts != 3 || *requests != 3 {
+		t.Fatalf("Expected 3 attempts, but got %v (%v requests)", cs.Attempts, *requests)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	s, requests := failingServer(10, 503, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: fastRetries,
+	}
+	cs, err := client.Request(nil, "GET", "/whatever", nil)
+	if badResponse, ok := err.(httpbackoff.BadHttpResponseCode); !ok || badResponse.HttpResponseCode != 503 {
+		t.Fatalf("Expected server error, but got %v", err)
+	}
+	if cs.Attempts != 5 || *requests != 5 {
+		t.Fatalf("Expected 5 attempts, but got %v (%v requests)", cs.Attempts, *requests)
+	}
+	if cs.HTTPResponseBody == "" {
+		t.Fatal("Expected response body of final attempt to be available")
+	}
+}
+
+func TestNoRetries(t *testing.T) {
+	s, requests := failingServer(1, 500, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: NoRetries,
+	}
+	cs, err := client.Request(nil, "GET", "/whatever", nil)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if cs.Attempts != 1 || *requests != 1 {
+		t.Fatalf("Expected 1 attempt, but got %v (%v requests)", cs.Attempts, *requests)
+	}
+}
+
+func TestNoRetryOfClientErrors(t *testing.T) {
+	s, requests := failingServer(1, 400, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: fastRetries,
+	}
+	_, err := client.Request(nil, "GET", "/whatever", nil)
+	if badResponse, ok := err.(httpbackoff.BadHttpResponseCode); !ok || badResponse.HttpResponseCode != 400 {
+		t.Fatalf("Expected bad request error, but got %v", err)
+	}
+	if *requests != 1 {
+		t.Fatalf("Expected 1 request, but got %v", *requests)
+	}
+}
+
+func TestRetryStatusCodes(t *testing.T) {
+	s, requests := failingServer(2, 429, nil)
+	defer s.Close()
+	policy := *fastRetries
+	policy.RetryStatusCodes = []int{429}
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: &policy,
+	}
+	_, err := client.Request(nil, "GET", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *requests != 3 {
+		t.Fatalf("Expected 3 requests, but got %v", *requests)
+	}
+}
+
+func TestRetryIdempotentOnly(t *testing.T) {
+	s, requests := failingServer(1, 500, nil)
+	defer s.Close()
+	policy := *fastRetries
+	policy.IdempotentOnly = true
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: &policy,
+	}
+	_, err := client.Request([]byte("{}"), "POST", "/whatever", nil)
+	if err == nil {
+		t.Fatal("Expected POST request not to be retried")
+	}
+	_, err = client.Request([]byte("{}"), "PUT", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Expected PUT request to be retried, but got %v", err)
+	}
+	if *requests != 2 {
+		t.Fatalf("Expected 2 requests, but got %v", *requests)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	s, _ := failingServer(1, 503, http.Header{"Retry-After": []string{"1"}})
+	defer s.Close()
+	client := Client{
+		BaseURL:     s.URL,
+		RetryPolicy: fastRetries,
+	}
+	start := time.Now()
+	_, err := client.Request(nil, "GET", "/whatever", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Expected Retry-After header to delay retry by 1s, but request completed in %v", elapsed)
+	}
+}
+
+func TestRetryCancelledDuringDelay(t *testing.T) {
+	s, requests := failingServer(10, 500, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL: s.URL,
+		RetryPolicy: &ExponentialBackoff{
+			InitialInterval: time.Hour,
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := client.RequestWithContext(ctx, nil, "GET", "/whatever", nil)
+	if err != context.Canceled {
+		t.Fatalf("Expected canceled error, but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Cancellation did not interrupt retry delay (took %v)", elapsed)
+	}
+	if *requests != 1 {
+		t.Fatalf("Expected 1 request, but got %v", *requests)
+	}
+}
+
+func TestExponentialBackoffDelays(t *testing.T) {
+	b := &ExponentialBackoff{
+		InitialInterval: time.Second,
+		Multiplier:      2,
+		MaxInterval:     5 * time.Second,
+		MaxElapsedTime:  time.Minute,
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, exp := range expected {
+		delay, retry := b.RetryDelay(i+1, 0, nil, nil, errors.New("network blip"))
+		if !retry || delay != exp {
+			t.Errorf("Attempt %v: expected delay %v, but got %v (retry: %v)", i+1, exp, delay, retry)
+		}
+	}
+	if _, retry := b.RetryDelay(1, 2*time.Minute, nil, nil, errors.New("network blip")); retry {
+		t.Error("Expected no retry after MaxElapsedTime")
+	}
+}
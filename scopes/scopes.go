@@ -0,0 +1,168 @@
+// Package scopes implements Taskcluster scope satisfaction and scope set
+// operations offline, following the same rules as the Taskcluster auth
+// service (see https://github.com/taskcluster/taskcluster-lib-scopes).
+//
+// A scope is satisfied by a scope that is identical to it, or by a scope
+// ending in "*" whose prefix (without the "*") is a prefix of it. For
+// example, "queue:create-task:*" satisfies "queue:create-task:highest:proj/x",
+// and "queue:*" satisfies "queue:create-task:*", but "queue:create-task"
+// does not satisfy "queue:create-task:*".
+//
+// The functions in this package do not expand roles ("assume:..." scopes);
+// use the auth service's expandScopes endpoint to do that first, if needed.
+package scopes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Validate returns an error if scope contains characters other than
+// printable ASCII (0x20 to 0x7e), which the auth service rejects.
+func Validate(scope string) error {
+	for i := 0; i < len(scope); i++ {
+		if scope[i] < 0x20 || scope[i] > 0x7e {
+			return fmt.Errorf("Scope %q contains invalid character at position %v; only printable ASCII is allowed", scope, i)
+		}
+	}
+	return nil
+}
+
+// Satisfies reports whether the scope pattern have satisfies the scope want.
+func Satisfies(have, want string) bool {
+	if have == want {
+		return true
+	}
+	return strings.HasSuffix(have, "*") && strings.HasPrefix(want, have[:len(have)-1])
+}
+
+// Satisfied reports whether scope want is satisfied by any of the scopes in
+// have.
+func Satisfied(have []string, want string) bool {
+	for _, h := range have {
+		if Satisfies(h, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// SatisfiesAll reports whether every scope in want is satisfied by the
+// scopes in have, i.e. whether want is a subset of have.
+func SatisfiesAll(have, want []string) bool {
+	for _, w := range want {
+		if !Satisfied(have, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// SatisfiesAny reports whether any one of the scope sets in alternatives is
+// satisfied by the scopes in have. This is how the auth service evaluates
+// required scopes expressed in disjunctive normal form ([][]string).
+func SatisfiesAny(have []string, alternatives [][]string) bool {
+	for _, want := range alternatives {
+		if SatisfiesAll(have, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSubset reports whether the scopes in a are all satisfied by the scopes
+// in b.
+func IsSubset(a, b []string) bool {
+	return SatisfiesAll(b, a)
+}
+
+// Missing returns the scopes in want that are not satisfied by the scopes in
+// have, in the order given.
+func Missing(have, want []string) []string {
+	missing := []string{}
+	for _, w := range want {
+		if !Satisfied(have, w) {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
+
+// Compare orders scopes lexically, except that a trailing "*" sorts before
+// any other character, so that a scope sorts before every scope it
+// satisfies. It returns a negative number if a sorts before b, a positive
+// number if b sorts before a, and zero if they are equal.
+func Compare(a, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		if a[i] == '*' && i == len(a)-1 {
+			return -1
+		}
+		if b[i] == '*' && i == len(b)-1 {
+			return 1
+		}
+		if a[i] < b[i] {
+			return -1
+		}
+		return 1
+	}
+	// one is a prefix of the other: "a*" sorts before "a" (but "a*" still
+	// sorts before "a**")
+	if len(b) == len(a)+1 && b[len(a)] == '*' && !strings.HasSuffix(a, "*") {
+		return 1
+	}
+	if len(a) == len(b)+1 && a[len(b)] == '*' && !strings.HasSuffix(b, "*") {
+		return -1
+	}
+	return len(a) - len(b)
+}
+
+// Sort sorts scopes in place, in the order defined by Compare.
+func Sort(scopes []string) {
+	sort.Slice(scopes, func(i, j int) bool {
+		return Compare(scopes[i], scopes[j]) < 0
+	})
+}
+
+// Normalize returns the minimal, sorted set of scopes that satisfies exactly
+// the same scopes as the given set: duplicates, and scopes that are
+// satisfied by another scope in the set, are removed.
+func Normalize(scopes []string) []string {
+	sorted := append([]string{}, scopes...)
+	Sort(sorted)
+	normalized := []string{}
+	for _, scope := range sorted {
+		// since a scope sorts before the scopes it satisfies, only scopes
+		// already kept can satisfy this one
+		if !Satisfied(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized
+}
+
+// Union returns the normalized set of scopes satisfied by a or b.
+func Union(a, b []string) []string {
+	return Normalize(append(append([]string{}, a...), b...))
+}
+
+// Intersection returns the normalized set of scopes satisfied by both a and
+// b. For example, the intersection of ["queue:*"] and ["queue:route:*",
+// "index:*"] is ["queue:route:*"].
+func Intersection(a, b []string) []string {
+	both := []string{}
+	for _, scope := range a {
+		if Satisfied(b, scope) {
+			both = append(both, scope)
+		}
+	}
+	for _, scope := range b {
+		if Satisfied(a, scope) {
+			both = append(both, scope)
+		}
+	}
+	return Normalize(both)
+}
@@ -0,0 +1,187 @@
+package scopes
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test cases follow those of taskcluster-lib-scopes, which implements the
+// algorithm used by the auth service.
+
+func TestSatisfies(t *testing.T) {
+	testCases := []struct {
+		have     string
+		want     string
+		expected bool
+	}{
+		{"queue:create-task", "queue:create-task", true},
+		{"queue:*", "queue:create-task", true},
+		{"queue:*", "queue:", true},
+		{"queue:*", "queue:*", true},
+		{"queue:*", "queue", false},
+		{"*", "anything:at:all", true},
+		{"*", "", true},
+		{"queue:create-task", "queue:create-task:*", false},
+		{"queue:create-task", "queue:create-tas", false},
+		{"queue:create-task*", "queue:create-task", true},
+		{"queue:create*task", "queue:create-task", false},
+		{"queue:create-task", "queue:create-task ", false},
+		{"", "", true},
+		{"", "a", false},
+	}
+	for _, tc := range testCases {
+		if got := Satisfies(tc.have, tc.want); got != tc.expected {
+			t.Errorf("Satisfies(%q, %q) = %v, expected %v", tc.have, tc.want, got, tc.expected)
+		}
+	}
+}
+
+func TestSatisfiesAny(t *testing.T) {
+	have := []string{"queue:create-task:*", "index:insert-task:project.*"}
+	testCases := []struct {
+		alternatives [][]string
+		expected     bool
+	}{
+		{[][]string{}, false},
+		{[][]string{{}}, true},
+		{[][]string{{"queue:create-task:lowest:proj/x"}}, true},
+		{[][]string{{"queue:create-task:lowest:proj/x", "index:insert-task:project.foo"}}, true},
+		{[][]string{{"queue:create-task:lowest:proj/x", "index:insert-task:other"}}, false},
+		{[][]string{{"index:insert-task:other"}, {"queue:create-task:x"}}, true},
+		{[][]string{{"queue:cancel-task"}, {"index:insert-task:other"}}, false},
+	}
+	for _, tc := range testCases {
+		if got := SatisfiesAny(have, tc.alternatives); got != tc.expected {
+			t.Errorf("SatisfiesAny(%q, %q) = %v, expected %v", have, tc.alternatives, got, tc.expected)
+		}
+	}
+}
+
+func TestMissing(t *testing.T) {
+	have := []string{"queue:*", "secrets:get:project/x/*"}
+	want := []string{"queue:create-task", "secrets:get:project/y/z", "secrets:get:project/x/z", "auth:*"}
+	expected := []string{"secrets:get:project/y/z", "auth:*"}
+	if got := Missing(have, want); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Missing(%q, %q) = %q, expected %q", have, want, got, expected)
+	}
+	if got := Missing(have, []string{"queue:x"}); len(got) != 0 {
+		t.Errorf("Expected no missing scopes, but got %q", got)
+	}
+}
+
+func TestIsSubset(t *testing.T) {
+	testCases := []struct {
+		a        []string
+		b        []string
+		expected bool
+	}{
+		{[]string{}, []string{}, true},
+		{[]string{"a"}, []string{}, false},
+		{[]string{"a", "ab", "abc*"}, []string{"a*"}, true},
+		{[]string{"a*"}, []string{"a", "ab", "abc*"}, false},
+		{[]string{"b"}, []string{"a*"}, false},
+	}
+	for _, tc := range testCases {
+		if got := IsSubset(tc.a, tc.b); got != tc.expected {
+			t.Errorf("IsSubset(%q, %q) = %v, expected %v", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	scopes := []string{"b", "a", "a*", "*", "ab", "a:*", "a:", "aa*"}
+	expected := []string{"*", "a*", "a", "a:*", "a:", "aa*", "ab", "b"}
+	Sort(scopes)
+	if !reflect.DeepEqual(scopes, expected) {
+		t.Errorf("Got %q, expected %q", scopes, expected)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		scopes   []string
+		expected []string
+	}{
+		{[]string{}, []string{}},
+		{[]string{"a", "a"}, []string{"a"}},
+		{[]string{"b", "a"}, []string{"a", "b"}},
+		{[]string{"ab", "a*"}, []string{"a*"}},
+		{[]string{"a*", "a*", "a**"}, []string{"a*"}},
+		{[]string{"a", "a*", "b*", "bc", "c"}, []string{"a*", "b*", "c"}},
+		{[]string{"abc", "ab*", "a*", "*"}, []string{"*"}},
+		{[]string{"ab*", "abc*", "ac"}, []string{"ab*", "ac"}},
+	}
+	for _, tc := range testCases {
+		if got := Normalize(tc.scopes); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Normalize(%q) = %q, expected %q", tc.scopes, got, tc.expected)
+		}
+	}
+}
+
+func TestNormalizeDoesNotModifyInput(t *testing.T) {
+	scopes := []string{"b", "a"}
+	Normalize(scopes)
+	if !reflect.DeepEqual(scopes, []string{"b", "a"}) {
+		t.Errorf("Input was modified: %q", scopes)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	testCases := []struct {
+		a        []string
+		b        []string
+		expected []string
+	}{
+		{[]string{}, []string{}, []string{}},
+		{[]string{"a"}, []string{}, []string{"a"}},
+		{[]string{"a"}, []string{"b"}, []string{"a", "b"}},
+		{[]string{"a*"}, []string{"ab", "b"}, []string{"a*", "b"}},
+		{[]string{"ab*", "c"}, []string{"a*"}, []string{"a*", "c"}},
+	}
+	for _, tc := range testCases {
+		if got := Union(tc.a, tc.b); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Union(%q, %q) = %q, expected %q", tc.a, tc.b, got, tc.expected)
+		}
+		if got := Union(tc.b, tc.a); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Union(%q, %q) = %q, expected %q", tc.b, tc.a, got, tc.expected)
+		}
+	}
+}
+
+func TestIntersection(t *testing.T) {
+	testCases := []struct {
+		a        []string
+		b        []string
+		expected []string
+	}{
+		{[]string{}, []string{"a"}, []string{}},
+		{[]string{"a"}, []string{"a"}, []string{"a"}},
+		{[]string{"a"}, []string{"b"}, []string{}},
+		{[]string{"a*"}, []string{"ab"}, []string{"ab"}},
+		{[]string{"a*"}, []string{"ab*"}, []string{"ab*"}},
+		{[]string{"a*", "b"}, []string{"ab*", "b*", "c"}, []string{"ab*", "b"}},
+		{[]string{"queue:*"}, []string{"queue:route:*", "index:*"}, []string{"queue:route:*"}},
+		{[]string{"*"}, []string{"x", "y*"}, []string{"x", "y*"}},
+	}
+	for _, tc := range testCases {
+		if got := Intersection(tc.a, tc.b); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Intersection(%q, %q) = %q, expected %q", tc.a, tc.b, got, tc.expected)
+		}
+		if got := Intersection(tc.b, tc.a); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Intersection(%q, %q) = %q, expected %q", tc.b, tc.a, got, tc.expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, scope := range []string{"", "queue:create-task:*", "a b ~"} {
+		if err := Validate(scope); err != nil {
+			t.Errorf("Validate(%q): unexpected error %v", scope, err)
+		}
+	}
+	for _, scope := range []string{"tab\there", "newline\n", "ünïcode"} {
+		if err := Validate(scope); err == nil {
+			t.Errorf("Validate(%q): expected an error", scope)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package scopes
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Template is a scope expression template, as used in Taskcluster API
+// definitions to describe the scopes that an API method requires. Templates
+// refer to parameters of the call (such as route parameters, or properties of
+// the request payload) by name, and are expanded into concrete scopes with
+// Expand, or checked against a set of scopes with Evaluate.
+//
+// Generated client packages export the templates of their API methods in a
+// RequiredScopes map, e.g. tcqueue.RequiredScopes["createTask"].
+//
+// A Template is one of RequiredScope, AllOf, AnyOf, IfThen or ForEachIn.
+type Template interface {
+	// dnf returns the template expanded into disjunctive normal form, or
+	// nil if the template is absent (an IfThen whose condition is false,
+	// and which has no Else). An absent template is dropped from the
+	// alternatives of an AnyOf, and requires nothing in an AllOf.
+	dnf(params Params) ([][]string, error)
+}
+
+// Params holds the values of the parameters referred to by a Template.
+// Parameters referred to as <name> in a RequiredScope or ForEachIn must be
+// strings, the parameter named by IfThen.If must be a bool, and the
+// parameter named by ForEachIn.In must be a []string (or []interface{}
+// holding strings).
+type Params map[string]interface{}
+
+// RequiredScope is a scope, which may contain parameter references of the
+// form <name>, e.g. "queue:cancel-task:<schedulerId>/<taskGroupId>/<taskId>".
+type RequiredScope string
+
+// AllOf is satisfied if all of its templates are satisfied.
+type AllOf []Template
+
+// AnyOf is satisfied if any of its templates is satisfied.
+type AnyOf []Template
+
+// IfThen requires the scopes of Then if the bool parameter If is true, and
+// otherwise the scopes of Else. If the condition is false and Else is nil,
+// the IfThen is absent: it is not an alternative of an enclosing AnyOf, and
+// adds no scopes to an enclosing AllOf.
+type IfThen struct {
+	If   string
+	Then Template
+	Else Template
+}
+
+// ForEachIn requires, for each item of the list parameter In, the scope
+// Each, in which the item can be referred to as <For>. For example
+// ForEachIn{For: "route", In: "routes", Each: "queue:route:<route>"}.
+type ForEachIn struct {
+	For  string
+	In   string
+	Each string
+}
+
+var parameterReference = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9_]*)>`)
+
+// substitute replaces parameter references in scope with the values of the
+// parameters, looking them up in locals first, and then in params
+func substitute(scope string, locals, params Params) (string, error) {
+	var err error
+	result := parameterReference.ReplaceAllStringFunc(scope, func(ref string) string {
+		name := ref[1 : len(ref)-1]
+		value, found := locals[name]
+		if !found {
+			value, found = params[name]
+		}
+		if !found {
+			if err == nil {
+				err = fmt.Errorf("Scope %q refers to parameter %q which has not been provided", scope, name)
+			}
+			return ref
+		}
+		s, ok := value.(string)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("Parameter %q referred to in scope %q must be a string, but is %T", name, scope, value)
+			}
+			return ref
+		}
+		return s
+	})
+	return result, err
+}
+
+func (rs RequiredScope) dnf(params Params) ([][]string, error) {
+	scope, err := substitute(string(rs), nil, params)
+	if err != nil {
+		return nil, err
+	}
+	return [][]string{{scope}}, nil
+}
+
+func (allOf AllOf) dnf(params Params) ([][]string, error) {
+	result := [][]string{{}}
+	for _, t := range allOf {
+		alternatives, err := t.dnf(params)
+		if err != nil {
+			return nil, err
+		}
+		if alternatives == nil {
+			continue
+		}
+		product := [][]string{}
+		for _, a := range result {
+			for _, b := range alternatives {
+				product = append(product, append(append([]string{}, a...), b...))
+			}
+		}
+		result = product
+	}
+	return result, nil
+}
+
+func (anyOf AnyOf) dnf(params Params) ([][]string, error) {
+	result := [][]string{}
+	for _, t := range anyOf {
+		alternatives, err := t.dnf(params)
+		if err != nil {
+			return nil, err
+		}
+		// absent templates (with nil alternatives) add no alternatives
+		result = append(result, alternatives...)
+	}
+	return result, nil
+}
+
+func (ifThen IfThen) dnf(params Params) ([][]string, error) {
+	value, found := params[ifThen.If]
+	if !found {
+		return nil, fmt.Errorf("Parameter %q has not been provided", ifThen.If)
+	}
+	condition, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("Parameter %q must be a bool, but is %T", ifThen.If, value)
+	}
+	if condition {
+		return ifThen.Then.dnf(params)
+	}
+	if ifThen.Else == nil {
+		return nil, nil
+	}
+	return ifThen.Else.dnf(params)
+}
+
+func (forEachIn ForEachIn) dnf(params Params) ([][]string, error) {
+	value, found := params[forEachIn.In]
+	if !found {
+		return nil, fmt.Errorf("Parameter %q has not been provided", forEachIn.In)
+	}
+	var items []string
+	switch v := value.(type) {
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("Items of parameter %q must be strings, but found %T", forEachIn.In, item)
+			}
+			items = append(items, s)
+		}
+	default:
+		return nil, fmt.Errorf("Parameter %q must be a list of strings, but is %T", forEachIn.In, value)
+	}
+	scopes := []string{}
+	for _, item := range items {
+		scope, err := substitute(forEachIn.Each, Params{forEachIn.For: item}, params)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+	return [][]string{scopes}, nil
+}
+
+// Expand substitutes params into template, and returns the resulting scope
+// requirements in disjunctive normal form: the call is authorized if all of
+// the scopes of any one of the returned scope sets are satisfied (see
+// SatisfiesAny). Each returned scope set is normalized. A nil or absent
+// template requires no scopes.
+func Expand(template Template, params Params) ([][]string, error) {
+	if template == nil {
+		return [][]string{{}}, nil
+	}
+	alternatives, err := template.dnf(params)
+	if err != nil {
+		return nil, err
+	}
+	if alternatives == nil {
+		return [][]string{{}}, nil
+	}
+	for i := range alternatives {
+		alternatives[i] = Normalize(alternatives[i])
+	}
+	return alternatives, nil
+}
+
+// Result is the outcome of evaluating a Template against a set of scopes.
+type Result struct {
+	// Whether the scopes satisfy the template
+	Authorized bool
+	// If authorized, the scopes of the first alternative of the expanded
+	// template that is satisfied, otherwise of the alternative with the
+	// fewest missing scopes. When authorized, these are the minimal
+	// AuthorizedScopes for credentials delegated to make the call.
+	Required []string
+	// The scopes of Required that are not satisfied, i.e. the scopes that
+	// would need to be added to authorize the call. Empty if authorized.
+	Missing []string
+}
+
+// Evaluate reports whether the scopes in have satisfy template, given the
+// parameters of the call, and if not, which scopes are missing. A nil
+// template requires no scopes. An error is returned if params do not
+// provide the parameters that template refers to.
+func Evaluate(template Template, params Params, have []string) (*Result, error) {
+	alternatives, err := Expand(template, params)
+	if err != nil {
+		return nil, err
+	}
+	var best *Result
+	for _, required := range alternatives {
+		missing := Missing(have, required)
+		if best == nil || len(missing) < len(best.Missing) {
+			best = &Result{
+				Authorized: len(missing) == 0,
+				Required:   required,
+				Missing:    missing,
+			}
+		}
+		if best.Authorized {
+			break
+		}
+	}
+	if best == nil {
+		// an empty AnyOf cannot be satisfied
+		best = &Result{
+			Required: []string{},
+			Missing:  []string{},
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,194 @@
+package scopes
+
+import (
+	"reflect"
+	"testing"
+)
+
+// createTask is the scope expression template of the queue's createTask
+// method
+var createTask = AllOf{
+	ForEachIn{For: "scope", In: "scopes", Each: "<scope>"},
+	ForEachIn{For: "route", In: "routes", Each: "queue:route:<route>"},
+	AnyOf{
+		AllOf{
+			RequiredScope("queue:scheduler-id:<schedulerId>"),
+			ForEachIn{For: "priority", In: "priorities", Each: "queue:create-task:<priority>:<provisionerId>/<workerType>"},
+		},
+		IfThen{
+			If: "legacyScopes",
+			Then: AnyOf{
+				RequiredScope("queue:create-task:<provisionerId>/<workerType>"),
+				AllOf{
+					RequiredScope("queue:define-task:<provisionerId>/<workerType>"),
+					RequiredScope("queue:task-group-id:<schedulerId>/<taskGroupId>"),
+					RequiredScope("queue:schedule-task:<schedulerId>/<taskGroupId>/<taskId>"),
+				},
+			},
+		},
+	},
+}
+
+func createTaskParams(legacyScopes bool) Params {
+	return Params{
+		"scopes":        []string{"secrets:get:project/x", "docker-worker:cache:x"},
+		"routes":        []interface{}{"index.project.x"},
+		"schedulerId":   "-",
+		"priorities":    []string{"lowest"},
+		"provisionerId": "proj-x",
+		"workerType":    "ci",
+		"legacyScopes":  legacyScopes,
+		"taskGroupId":   "tg",
+		"taskId":        "t",
+	}
+}
+
+func TestExpand(t *testing.T) {
+	alternatives, err := Expand(createTask, createTaskParams(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]string{
+		{"docker-worker:cache:x", "queue:create-task:lowest:proj-x/ci", "queue:route:index.project.x", "queue:scheduler-id:-", "secrets:get:project/x"},
+		{"docker-worker:cache:x", "queue:create-task:proj-x/ci", "queue:route:index.project.x", "secrets:get:project/x"},
+		{"docker-worker:cache:x", "queue:define-task:proj-x/ci", "queue:route:index.project.x", "queue:schedule-task:-/tg/t", "queue:task-group-id:-/tg", "secrets:get:project/x"},
+	}
+	if !reflect.DeepEqual(alternatives, expected) {
+		t.Errorf("Got %q, expected %q", alternatives, expected)
+	}
+
+	// legacy scopes disabled: the conditional is absent, and so is not an
+	// alternative to the scheduler id and create-task scopes
+	alternatives, err = Expand(createTask, createTaskParams(false))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = expected[:1]
+	if !reflect.DeepEqual(alternatives, expected) {
+		t.Errorf("Got %q, expected %q", alternatives, expected)
+	}
+}
+
+func TestExpandAbsent(t *testing.T) {
+	absent := IfThen{If: "c", Then: RequiredScope("a")}
+	testCases := []struct {
+		name     string
+		template Template
+		expected [][]string
+	}{
+		{"absent", absent, [][]string{{}}},
+		{"absent in AllOf", AllOf{RequiredScope("b"), absent}, [][]string{{"b"}}},
+		{"absent in AnyOf", AnyOf{RequiredScope("b"), absent}, [][]string{{"b"}}},
+		{"only absent in AnyOf", AnyOf{absent}, [][]string{}},
+		{"else", IfThen{If: "c", Then: RequiredScope("a"), Else: RequiredScope("b")}, [][]string{{"b"}}},
+	}
+	for _, tc := range testCases {
+		alternatives, err := Expand(tc.template, Params{"c": false})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(alternatives, tc.expected) {
+			t.Errorf("%v: got %q, expected %q", tc.name, alternatives, tc.expected)
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template Template
+		params   Params
+	}{
+		{"missing parameter", RequiredScope("a:<b>"), Params{}},
+		{"non-string parameter", RequiredScope("a:<b>"), Params{"b": 3}},
+		{"missing condition", IfThen{If: "c", Then: RequiredScope("a")}, Params{}},
+		{"non-bool condition", IfThen{If: "c", Then: RequiredScope("a")}, Params{"c": "true"}},
+		{"missing list", ForEachIn{For: "x", In: "xs", Each: "a:<x>"}, Params{}},
+		{"non-list", ForEachIn{For: "x", In: "xs", Each: "a:<x>"}, Params{"xs": "x"}},
+		{"non-string item", ForEachIn{For: "x", In: "xs", Each: "a:<x>"}, Params{"xs": []interface{}{1}}},
+		{"nested error", AnyOf{RequiredScope("a"), AllOf{RequiredScope("<b>")}}, Params{}},
+	}
+	for _, tc := range testCases {
+		if _, err := Expand(tc.template, tc.params); err == nil {
+			t.Errorf("%v: expected an error", tc.name)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		have     []string
+		legacy   bool
+		expected Result
+	}{
+		{
+			name:   "authorized",
+			have:   []string{"secrets:get:project/*", "docker-worker:cache:*", "queue:route:index.project.*", "queue:scheduler-id:-", "queue:create-task:*"},
+			legacy: false,
+			expected: Result{
+				Authorized: true,
+				Required:   []string{"docker-worker:cache:x", "queue:create-task:lowest:proj-x/ci", "queue:route:index.project.x", "queue:scheduler-id:-", "secrets:get:project/x"},
+				Missing:    []string{},
+			},
+		},
+		{
+			name:   "authorized by legacy scopes",
+			have:   []string{"secrets:*", "docker-worker:*", "queue:route:*", "queue:create-task:proj-x/*"},
+			legacy: true,
+			expected: Result{
+				Authorized: true,
+				Required:   []string{"docker-worker:cache:x", "queue:create-task:proj-x/ci", "queue:route:index.project.x", "secrets:get:project/x"},
+				Missing:    []string{},
+			},
+		},
+		{
+			name:   "legacy scopes disabled",
+			have:   []string{"secrets:*", "docker-worker:*", "queue:route:*"},
+			legacy: false,
+			expected: Result{
+				Authorized: false,
+				Required:   []string{"docker-worker:cache:x", "queue:create-task:lowest:proj-x/ci", "queue:route:index.project.x", "queue:scheduler-id:-", "secrets:get:project/x"},
+				Missing:    []string{"queue:create-task:lowest:proj-x/ci", "queue:scheduler-id:-"},
+			},
+		},
+		{
+			name:   "missing route scope",
+			have:   []string{"secrets:*", "docker-worker:*", "queue:scheduler-id:-", "queue:create-task:*"},
+			legacy: false,
+			expected: Result{
+				Authorized: false,
+				Required:   []string{"docker-worker:cache:x", "queue:create-task:lowest:proj-x/ci", "queue:route:index.project.x", "queue:scheduler-id:-", "secrets:get:project/x"},
+				Missing:    []string{"queue:route:index.project.x"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		result, err := Evaluate(createTask, createTaskParams(tc.legacy), tc.have)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(*result, tc.expected) {
+			t.Errorf("%v: got %#v, expected %#v", tc.name, *result, tc.expected)
+		}
+	}
+}
+
+func TestEvaluateTrivialTemplates(t *testing.T) {
+	result, err := Evaluate(nil, nil, nil)
+	if err != nil || !result.Authorized {
+		t.Errorf("Expected nil template to be authorized, but got %#v %v", result, err)
+	}
+	result, err = Evaluate(AllOf{}, nil, nil)
+	if err != nil || !result.Authorized {
+		t.Errorf("Expected empty AllOf to be authorized, but got %#v %v", result, err)
+	}
+	result, err = Evaluate(AnyOf{}, nil, []string{"*"})
+	if err != nil || result.Authorized {
+		t.Errorf("Expected empty AnyOf not to be authorized, but got %#v %v", result, err)
+	}
+	result, err = Evaluate(IfThen{If: "private", Then: RequiredScope("queue:get-artifact:<name>"), Else: RequiredScope("public")}, Params{"private": false}, nil)
+	if err != nil || result.Authorized || !reflect.DeepEqual(result.Missing, []string{"public"}) {
+		t.Errorf("Expected Else to be evaluated, but got %#v %v", result, err)
+	}
+}
@@ -42,10 +42,13 @@
 package tcauth
 
 import (
+	"context"
+	"errors"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Auth tcclient.Client
@@ -93,13 +96,126 @@ func NewFromEnv() *Auth {
 	}
 }
 
+// NewFromProfile returns an *Auth configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Auth, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcauth.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Auth, comprising all of its
+// API methods. Code that depends on API rather than *Auth can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	ListClients(continuationToken, limit, prefix string) (*ListClientResponse, error)
+	ListClientsWithContext(ctx context.Context, continuationToken, limit, prefix string) (*ListClientResponse, error)
+	ListClientsAll(ctx context.Context, prefix string) *GetClientResponseIterator
+	Client(clientId string) (*GetClientResponse, error)
+	ClientWithContext(ctx context.Context, clientId string) (*GetClientResponse, error)
+	CreateClient(clientId string, payload *CreateClientRequest) (*CreateClientResponse, error)
+	CreateClientWithContext(ctx context.Context, clientId string, payload *CreateClientRequest) (*CreateClientResponse, error)
+	ResetAccessToken(clientId string) (*CreateClientResponse, error)
+	ResetAccessTokenWithContext(ctx context.Context, clientId string) (*CreateClientResponse, error)
+	UpdateClient(clientId string, payload *CreateClientRequest) (*GetClientResponse, error)
+	UpdateClientWithContext(ctx context.Context, clientId string, payload *CreateClientRequest) (*GetClientResponse, error)
+	EnableClient(clientId string) (*GetClientResponse, error)
+	EnableClientWithContext(ctx context.Context, clientId string) (*GetClientResponse, error)
+	DisableClient(clientId string) (*GetClientResponse, error)
+	DisableClientWithContext(ctx context.Context, clientId string) (*GetClientResponse, error)
+	DeleteClient(clientId string) error
+	DeleteClientWithContext(ctx context.Context, clientId string) error
+	ListRoles() (*GetAllRolesNoPagination, error)
+	ListRolesWithContext(ctx context.Context) (*GetAllRolesNoPagination, error)
+	ListRoleIds(continuationToken, limit string) (*GetRoleIdsResponse, error)
+	ListRoleIdsWithContext(ctx context.Context, continuationToken, limit string) (*GetRoleIdsResponse, error)
+	ListRoleIdsAll(ctx context.Context) *StringIterator
+	ListRoles2(continuationToken, limit string) (*GetAllRolesResponse, error)
+	ListRoles2WithContext(ctx context.Context, continuationToken, limit string) (*GetAllRolesResponse, error)
+	ListRoles2All(ctx context.Context) *GetRoleResponseIterator
+	Role(roleId string) (*GetRoleResponse, error)
+	RoleWithContext(ctx context.Context, roleId string) (*GetRoleResponse, error)
+	CreateRole(roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error)
+	CreateRoleWithContext(ctx context.Context, roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error)
+	UpdateRole(roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error)
+	UpdateRoleWithContext(ctx context.Context, roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error)
+	DeleteRole(roleId string) error
+	DeleteRoleWithContext(ctx context.Context, roleId string) error
+	ExpandScopesGet(payload *SetOfScopes) (*SetOfScopes, error)
+	ExpandScopesGetWithContext(ctx context.Context, payload *SetOfScopes) (*SetOfScopes, error)
+	ExpandScopes(payload *SetOfScopes) (*SetOfScopes, error)
+	ExpandScopesWithContext(ctx context.Context, payload *SetOfScopes) (*SetOfScopes, error)
+	CurrentScopes() (*SetOfScopes, error)
+	CurrentScopesWithContext(ctx context.Context) (*SetOfScopes, error)
+	AwsS3Credentials(level, bucket, prefix, format string) (*AWSS3CredentialsResponse, error)
+	AwsS3CredentialsWithContext(ctx context.Context, level, bucket, prefix, format string) (*AWSS3CredentialsResponse, error)
+	AwsS3Credentials_SignedURL(level, bucket, prefix, format string, duration time.Duration) (*url.URL, error)
+	AzureAccounts() (*AzureListAccountResponse, error)
+	AzureAccountsWithContext(ctx context.Context) (*AzureListAccountResponse, error)
+	AzureAccounts_SignedURL(duration time.Duration) (*url.URL, error)
+	AzureTables(account, continuationToken string) (*AzureListTableResponse, error)
+	AzureTablesWithContext(ctx context.Context, account, continuationToken string) (*AzureListTableResponse, error)
+	AzureTables_SignedURL(account, continuationToken string, duration time.Duration) (*url.URL, error)
+	AzureTablesAll(ctx context.Context, account string) *StringIterator
+	AzureTableSAS(account, table, level string) (*AzureTableSharedAccessSignature, error)
+	AzureTableSASWithContext(ctx context.Context, account, table, level string) (*AzureTableSharedAccessSignature, error)
+	AzureTableSAS_SignedURL(account, table, level string, duration time.Duration) (*url.URL, error)
+	AzureContainers(account, continuationToken string) (*AzureListContainersResponse, error)
+	AzureContainersWithContext(ctx context.Context, account, continuationToken string) (*AzureListContainersResponse, error)
+	AzureContainers_SignedURL(account, continuationToken string, duration time.Duration) (*url.URL, error)
+	AzureContainersAll(ctx context.Context, account string) *StringIterator
+	AzureContainerSAS(account, container, level string) (*AzureBlobSharedAccessSignature, error)
+	AzureContainerSASWithContext(ctx context.Context, account, container, level string) (*AzureBlobSharedAccessSignature, error)
+	AzureContainerSAS_SignedURL(account, container, level string, duration time.Duration) (*url.URL, error)
+	SentryDSN(project string) (*SentryDSNResponse, error)
+	SentryDSNWithContext(ctx context.Context, project string) (*SentryDSNResponse, error)
+	SentryDSN_SignedURL(project string, duration time.Duration) (*url.URL, error)
+	StatsumToken(project string) (*StatsumTokenResponse, error)
+	StatsumTokenWithContext(ctx context.Context, project string) (*StatsumTokenResponse, error)
+	StatsumToken_SignedURL(project string, duration time.Duration) (*url.URL, error)
+	WebsocktunnelToken(wstAudience, wstClient string) (*WebsocktunnelTokenResponse, error)
+	WebsocktunnelTokenWithContext(ctx context.Context, wstAudience, wstClient string) (*WebsocktunnelTokenResponse, error)
+	WebsocktunnelToken_SignedURL(wstAudience, wstClient string, duration time.Duration) (*url.URL, error)
+	GcpCredentials(projectId, serviceAccount string) (*GCPCredentialsResponse, error)
+	GcpCredentialsWithContext(ctx context.Context, projectId, serviceAccount string) (*GCPCredentialsResponse, error)
+	GcpCredentials_SignedURL(projectId, serviceAccount string, duration time.Duration) (*url.URL, error)
+	AuthenticateHawk(payload *HawkSignatureAuthenticationRequest) (*HawkSignatureAuthenticationResponse, error)
+	AuthenticateHawkWithContext(ctx context.Context, payload *HawkSignatureAuthenticationRequest) (*HawkSignatureAuthenticationResponse, error)
+	TestAuthenticate(payload *TestAuthenticateRequest) (*TestAuthenticateResponse, error)
+	TestAuthenticateWithContext(ctx context.Context, payload *TestAuthenticateRequest) (*TestAuthenticateResponse, error)
+	TestAuthenticateGet() (*TestAuthenticateResponse, error)
+	TestAuthenticateGetWithContext(ctx context.Context) (*TestAuthenticateResponse, error)
+}
+
+var _ API = (*Auth)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (auth *Auth) Ping() error {
+	return auth.PingWithContext(auth.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See Ping for more details.
+func (auth *Auth) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*auth)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -115,6 +231,14 @@ func (auth *Auth) Ping() error {
 //
 // See #listClients
 func (auth *Auth) ListClients(continuationToken, limit, prefix string) (*ListClientResponse, error) {
+	return auth.ListClientsWithContext(auth.Context, continuationToken, limit, prefix)
+}
+
+// ListClientsWithContext is the same as ListClients, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See ListClients for more details.
+func (auth *Auth) ListClientsWithContext(ctx context.Context, continuationToken, limit, prefix string) (*ListClientResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -126,16 +250,41 @@ func (auth *Auth) ListClients(continuationToken, limit, prefix string) (*ListCli
 		v.Add("prefix", prefix)
 	}
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/clients/", new(ListClientResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "listClients"), nil, "GET", "/clients/", new(ListClientResponse), v)
 	return responseObject.(*ListClientResponse), err
 }
 
+// ListClientsAll returns an iterator over the GetClientResponse items of all
+// pages of ListClients results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListClients for more details.
+func (auth *Auth) ListClientsAll(ctx context.Context, prefix string) *GetClientResponseIterator {
+	return NewGetClientResponseIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]GetClientResponse, string, error) {
+		page, err := auth.ListClientsWithContext(ctx, continuationToken, limit, prefix)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Clients, page.ContinuationToken, nil
+	})
+}
+
 // Get information about a single client.
 //
 // See #client
 func (auth *Auth) Client(clientId string) (*GetClientResponse, error) {
+	return auth.ClientWithContext(auth.Context, clientId)
+}
+
+// ClientWithContext is the same as Client, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See Client for more details.
+func (auth *Auth) ClientWithContext(ctx context.Context, clientId string) (*GetClientResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/clients/"+url.QueryEscape(clientId), new(GetClientResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "client"), nil, "GET", "/clients/"+url.QueryEscape(clientId), new(GetClientResponse), nil)
 	return responseObject.(*GetClientResponse), err
 }
 
@@ -159,8 +308,16 @@ func (auth *Auth) Client(clientId string) (*GetClientResponse, error) {
 //
 // See #createClient
 func (auth *Auth) CreateClient(clientId string, payload *CreateClientRequest) (*CreateClientResponse, error) {
+	return auth.CreateClientWithContext(auth.Context, clientId, payload)
+}
+
+// CreateClientWithContext is the same as CreateClient, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See CreateClient for more details.
+func (auth *Auth) CreateClientWithContext(ctx context.Context, clientId string, payload *CreateClientRequest) (*CreateClientResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/clients/"+url.QueryEscape(clientId), new(CreateClientResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "createClient"), payload, "PUT", "/clients/"+url.QueryEscape(clientId), new(CreateClientResponse), nil)
 	return responseObject.(*CreateClientResponse), err
 }
 
@@ -176,8 +333,16 @@ func (auth *Auth) CreateClient(clientId string, payload *CreateClientRequest) (*
 //
 // See #resetAccessToken
 func (auth *Auth) ResetAccessToken(clientId string) (*CreateClientResponse, error) {
+	return auth.ResetAccessTokenWithContext(auth.Context, clientId)
+}
+
+// ResetAccessTokenWithContext is the same as ResetAccessToken, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See ResetAccessToken for more details.
+func (auth *Auth) ResetAccessTokenWithContext(ctx context.Context, clientId string) (*CreateClientResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/clients/"+url.QueryEscape(clientId)+"/reset", new(CreateClientResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "resetAccessToken"), nil, "POST", "/clients/"+url.QueryEscape(clientId)+"/reset", new(CreateClientResponse), nil)
 	return responseObject.(*CreateClientResponse), err
 }
 
@@ -194,8 +359,16 @@ func (auth *Auth) ResetAccessToken(clientId string) (*CreateClientResponse, erro
 //
 // See #updateClient
 func (auth *Auth) UpdateClient(clientId string, payload *CreateClientRequest) (*GetClientResponse, error) {
+	return auth.UpdateClientWithContext(auth.Context, clientId, payload)
+}
+
+// UpdateClientWithContext is the same as UpdateClient, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See UpdateClient for more details.
+func (auth *Auth) UpdateClientWithContext(ctx context.Context, clientId string, payload *CreateClientRequest) (*GetClientResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/clients/"+url.QueryEscape(clientId), new(GetClientResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "updateClient"), payload, "POST", "/clients/"+url.QueryEscape(clientId), new(GetClientResponse), nil)
 	return responseObject.(*GetClientResponse), err
 }
 
@@ -210,8 +383,16 @@ func (auth *Auth) UpdateClient(clientId string, payload *CreateClientRequest) (*
 //
 // See #enableClient
 func (auth *Auth) EnableClient(clientId string) (*GetClientResponse, error) {
+	return auth.EnableClientWithContext(auth.Context, clientId)
+}
+
+// EnableClientWithContext is the same as EnableClient, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See EnableClient for more details.
+func (auth *Auth) EnableClientWithContext(ctx context.Context, clientId string) (*GetClientResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/clients/"+url.QueryEscape(clientId)+"/enable", new(GetClientResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "enableClient"), nil, "POST", "/clients/"+url.QueryEscape(clientId)+"/enable", new(GetClientResponse), nil)
 	return responseObject.(*GetClientResponse), err
 }
 
@@ -225,8 +406,16 @@ func (auth *Auth) EnableClient(clientId string) (*GetClientResponse, error) {
 //
 // See #disableClient
 func (auth *Auth) DisableClient(clientId string) (*GetClientResponse, error) {
+	return auth.DisableClientWithContext(auth.Context, clientId)
+}
+
+// DisableClientWithContext is the same as DisableClient, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See DisableClient for more details.
+func (auth *Auth) DisableClientWithContext(ctx context.Context, clientId string) (*GetClientResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/clients/"+url.QueryEscape(clientId)+"/disable", new(GetClientResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "disableClient"), nil, "POST", "/clients/"+url.QueryEscape(clientId)+"/disable", new(GetClientResponse), nil)
 	return responseObject.(*GetClientResponse), err
 }
 
@@ -238,8 +427,16 @@ func (auth *Auth) DisableClient(clientId string) (*GetClientResponse, error) {
 //
 // See #deleteClient
 func (auth *Auth) DeleteClient(clientId string) error {
+	return auth.DeleteClientWithContext(auth.Context, clientId)
+}
+
+// DeleteClientWithContext is the same as DeleteClient, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See DeleteClient for more details.
+func (auth *Auth) DeleteClientWithContext(ctx context.Context, clientId string) error {
 	cd := tcclient.Client(*auth)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/clients/"+url.QueryEscape(clientId), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "deleteClient"), nil, "DELETE", "/clients/"+url.QueryEscape(clientId), nil, nil)
 	return err
 }
 
@@ -248,8 +445,16 @@ func (auth *Auth) DeleteClient(clientId string) error {
 //
 // See #listRoles
 func (auth *Auth) ListRoles() (*GetAllRolesNoPagination, error) {
+	return auth.ListRolesWithContext(auth.Context)
+}
+
+// ListRolesWithContext is the same as ListRoles, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See ListRoles for more details.
+func (auth *Auth) ListRolesWithContext(ctx context.Context) (*GetAllRolesNoPagination, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/roles/", new(GetAllRolesNoPagination), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "listRoles"), nil, "GET", "/roles/", new(GetAllRolesNoPagination), nil)
 	return responseObject.(*GetAllRolesNoPagination), err
 }
 
@@ -259,6 +464,14 @@ func (auth *Auth) ListRoles() (*GetAllRolesNoPagination, error) {
 //
 // See #listRoleIds
 func (auth *Auth) ListRoleIds(continuationToken, limit string) (*GetRoleIdsResponse, error) {
+	return auth.ListRoleIdsWithContext(auth.Context, continuationToken, limit)
+}
+
+// ListRoleIdsWithContext is the same as ListRoleIds, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See ListRoleIds for more details.
+func (auth *Auth) ListRoleIdsWithContext(ctx context.Context, continuationToken, limit string) (*GetRoleIdsResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -267,16 +480,41 @@ func (auth *Auth) ListRoleIds(continuationToken, limit string) (*GetRoleIdsRespo
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/roleids/", new(GetRoleIdsResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "listRoleIds"), nil, "GET", "/roleids/", new(GetRoleIdsResponse), v)
 	return responseObject.(*GetRoleIdsResponse), err
 }
 
+// ListRoleIdsAll returns an iterator over the string items of all
+// pages of ListRoleIds results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListRoleIds for more details.
+func (auth *Auth) ListRoleIdsAll(ctx context.Context) *StringIterator {
+	return NewStringIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]string, string, error) {
+		page, err := auth.ListRoleIdsWithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.RoleIds, page.ContinuationToken, nil
+	})
+}
+
 // If no limit is given, all roles are returned. Since this
 // list may become long, callers can use the `limit` and `continuationToken`
 // query arguments to page through the responses.
 //
 // See #listRoles2
 func (auth *Auth) ListRoles2(continuationToken, limit string) (*GetAllRolesResponse, error) {
+	return auth.ListRoles2WithContext(auth.Context, continuationToken, limit)
+}
+
+// ListRoles2WithContext is the same as ListRoles2, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See ListRoles2 for more details.
+func (auth *Auth) ListRoles2WithContext(ctx context.Context, continuationToken, limit string) (*GetAllRolesResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -285,17 +523,42 @@ func (auth *Auth) ListRoles2(continuationToken, limit string) (*GetAllRolesRespo
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/roles2/", new(GetAllRolesResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "listRoles2"), nil, "GET", "/roles2/", new(GetAllRolesResponse), v)
 	return responseObject.(*GetAllRolesResponse), err
 }
 
+// ListRoles2All returns an iterator over the GetRoleResponse items of all
+// pages of ListRoles2 results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListRoles2 for more details.
+func (auth *Auth) ListRoles2All(ctx context.Context) *GetRoleResponseIterator {
+	return NewGetRoleResponseIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]GetRoleResponse, string, error) {
+		page, err := auth.ListRoles2WithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Roles, page.ContinuationToken, nil
+	})
+}
+
 // Get information about a single role, including the set of scopes that the
 // role expands to.
 //
 // See #role
 func (auth *Auth) Role(roleId string) (*GetRoleResponse, error) {
+	return auth.RoleWithContext(auth.Context, roleId)
+}
+
+// RoleWithContext is the same as Role, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See Role for more details.
+func (auth *Auth) RoleWithContext(ctx context.Context, roleId string) (*GetRoleResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/roles/"+url.QueryEscape(roleId), new(GetRoleResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "role"), nil, "GET", "/roles/"+url.QueryEscape(roleId), new(GetRoleResponse), nil)
 	return responseObject.(*GetRoleResponse), err
 }
 
@@ -316,8 +579,16 @@ func (auth *Auth) Role(roleId string) (*GetRoleResponse, error) {
 //
 // See #createRole
 func (auth *Auth) CreateRole(roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error) {
+	return auth.CreateRoleWithContext(auth.Context, roleId, payload)
+}
+
+// CreateRoleWithContext is the same as CreateRole, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See CreateRole for more details.
+func (auth *Auth) CreateRoleWithContext(ctx context.Context, roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/roles/"+url.QueryEscape(roleId), new(GetRoleResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "createRole"), payload, "PUT", "/roles/"+url.QueryEscape(roleId), new(GetRoleResponse), nil)
 	return responseObject.(*GetRoleResponse), err
 }
 
@@ -336,8 +607,16 @@ func (auth *Auth) CreateRole(roleId string, payload *CreateRoleRequest) (*GetRol
 //
 // See #updateRole
 func (auth *Auth) UpdateRole(roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error) {
+	return auth.UpdateRoleWithContext(auth.Context, roleId, payload)
+}
+
+// UpdateRoleWithContext is the same as UpdateRole, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See UpdateRole for more details.
+func (auth *Auth) UpdateRoleWithContext(ctx context.Context, roleId string, payload *CreateRoleRequest) (*GetRoleResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/roles/"+url.QueryEscape(roleId), new(GetRoleResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "updateRole"), payload, "POST", "/roles/"+url.QueryEscape(roleId), new(GetRoleResponse), nil)
 	return responseObject.(*GetRoleResponse), err
 }
 
@@ -349,8 +628,16 @@ func (auth *Auth) UpdateRole(roleId string, payload *CreateRoleRequest) (*GetRol
 //
 // See #deleteRole
 func (auth *Auth) DeleteRole(roleId string) error {
+	return auth.DeleteRoleWithContext(auth.Context, roleId)
+}
+
+// DeleteRoleWithContext is the same as DeleteRole, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See DeleteRole for more details.
+func (auth *Auth) DeleteRoleWithContext(ctx context.Context, roleId string) error {
 	cd := tcclient.Client(*auth)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/roles/"+url.QueryEscape(roleId), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "deleteRole"), nil, "DELETE", "/roles/"+url.QueryEscape(roleId), nil, nil)
 	return err
 }
 
@@ -364,8 +651,16 @@ func (auth *Auth) DeleteRole(roleId string) error {
 //
 // See #expandScopesGet
 func (auth *Auth) ExpandScopesGet(payload *SetOfScopes) (*SetOfScopes, error) {
+	return auth.ExpandScopesGetWithContext(auth.Context, payload)
+}
+
+// ExpandScopesGetWithContext is the same as ExpandScopesGet, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See ExpandScopesGet for more details.
+func (auth *Auth) ExpandScopesGetWithContext(ctx context.Context, payload *SetOfScopes) (*SetOfScopes, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "GET", "/scopes/expand", new(SetOfScopes), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "expandScopesGet"), payload, "GET", "/scopes/expand", new(SetOfScopes), nil)
 	return responseObject.(*SetOfScopes), err
 }
 
@@ -374,8 +669,16 @@ func (auth *Auth) ExpandScopesGet(payload *SetOfScopes) (*SetOfScopes, error) {
 //
 // See #expandScopes
 func (auth *Auth) ExpandScopes(payload *SetOfScopes) (*SetOfScopes, error) {
+	return auth.ExpandScopesWithContext(auth.Context, payload)
+}
+
+// ExpandScopesWithContext is the same as ExpandScopes, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See ExpandScopes for more details.
+func (auth *Auth) ExpandScopesWithContext(ctx context.Context, payload *SetOfScopes) (*SetOfScopes, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/scopes/expand", new(SetOfScopes), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "expandScopes"), payload, "POST", "/scopes/expand", new(SetOfScopes), nil)
 	return responseObject.(*SetOfScopes), err
 }
 
@@ -385,8 +688,16 @@ func (auth *Auth) ExpandScopes(payload *SetOfScopes) (*SetOfScopes, error) {
 //
 // See #currentScopes
 func (auth *Auth) CurrentScopes() (*SetOfScopes, error) {
+	return auth.CurrentScopesWithContext(auth.Context)
+}
+
+// CurrentScopesWithContext is the same as CurrentScopes, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See CurrentScopes for more details.
+func (auth *Auth) CurrentScopesWithContext(ctx context.Context) (*SetOfScopes, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/scopes/current", new(SetOfScopes), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "currentScopes"), nil, "GET", "/scopes/current", new(SetOfScopes), nil)
 	return responseObject.(*SetOfScopes), err
 }
 
@@ -464,12 +775,20 @@ func (auth *Auth) CurrentScopes() (*SetOfScopes, error) {
 //
 // See #awsS3Credentials
 func (auth *Auth) AwsS3Credentials(level, bucket, prefix, format string) (*AWSS3CredentialsResponse, error) {
+	return auth.AwsS3CredentialsWithContext(auth.Context, level, bucket, prefix, format)
+}
+
+// AwsS3CredentialsWithContext is the same as AwsS3Credentials, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See AwsS3Credentials for more details.
+func (auth *Auth) AwsS3CredentialsWithContext(ctx context.Context, level, bucket, prefix, format string) (*AWSS3CredentialsResponse, error) {
 	v := url.Values{}
 	if format != "" {
 		v.Add("format", format)
 	}
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/aws/s3/"+url.QueryEscape(level)+"/"+url.QueryEscape(bucket)+"/"+url.QueryEscape(prefix), new(AWSS3CredentialsResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "awsS3Credentials"), nil, "GET", "/aws/s3/"+url.QueryEscape(level)+"/"+url.QueryEscape(bucket)+"/"+url.QueryEscape(prefix), new(AWSS3CredentialsResponse), v)
 	return responseObject.(*AWSS3CredentialsResponse), err
 }
 
@@ -498,8 +817,16 @@ func (auth *Auth) AwsS3Credentials_SignedURL(level, bucket, prefix, format strin
 //
 // See #azureAccounts
 func (auth *Auth) AzureAccounts() (*AzureListAccountResponse, error) {
+	return auth.AzureAccountsWithContext(auth.Context)
+}
+
+// AzureAccountsWithContext is the same as AzureAccounts, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See AzureAccounts for more details.
+func (auth *Auth) AzureAccountsWithContext(ctx context.Context) (*AzureListAccountResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/azure/accounts", new(AzureListAccountResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "azureAccounts"), nil, "GET", "/azure/accounts", new(AzureListAccountResponse), nil)
 	return responseObject.(*AzureListAccountResponse), err
 }
 
@@ -521,12 +848,20 @@ func (auth *Auth) AzureAccounts_SignedURL(duration time.Duration) (*url.URL, err
 //
 // See #azureTables
 func (auth *Auth) AzureTables(account, continuationToken string) (*AzureListTableResponse, error) {
+	return auth.AzureTablesWithContext(auth.Context, account, continuationToken)
+}
+
+// AzureTablesWithContext is the same as AzureTables, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See AzureTables for more details.
+func (auth *Auth) AzureTablesWithContext(ctx context.Context, account, continuationToken string) (*AzureListTableResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
 	}
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/azure/"+url.QueryEscape(account)+"/tables", new(AzureListTableResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "azureTables"), nil, "GET", "/azure/"+url.QueryEscape(account)+"/tables", new(AzureListTableResponse), v)
 	return responseObject.(*AzureListTableResponse), err
 }
 
@@ -545,6 +880,23 @@ func (auth *Auth) AzureTables_SignedURL(account, continuationToken string, durat
 	return (&cd).SignedURL("/azure/"+url.QueryEscape(account)+"/tables", v, duration)
 }
 
+// AzureTablesAll returns an iterator over the string items of all
+// pages of AzureTables results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See AzureTables for more details.
+func (auth *Auth) AzureTablesAll(ctx context.Context, account string) *StringIterator {
+	return NewStringIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]string, string, error) {
+		page, err := auth.AzureTablesWithContext(ctx, account, continuationToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Tables, page.ContinuationToken, nil
+	})
+}
+
 // Get a shared access signature (SAS) string for use with a specific Azure
 // Table Storage table.
 //
@@ -560,8 +912,16 @@ func (auth *Auth) AzureTables_SignedURL(account, continuationToken string, durat
 //
 // See #azureTableSAS
 func (auth *Auth) AzureTableSAS(account, table, level string) (*AzureTableSharedAccessSignature, error) {
+	return auth.AzureTableSASWithContext(auth.Context, account, table, level)
+}
+
+// AzureTableSASWithContext is the same as AzureTableSAS, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See AzureTableSAS for more details.
+func (auth *Auth) AzureTableSASWithContext(ctx context.Context, account, table, level string) (*AzureTableSharedAccessSignature, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/azure/"+url.QueryEscape(account)+"/table/"+url.QueryEscape(table)+"/"+url.QueryEscape(level), new(AzureTableSharedAccessSignature), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "azureTableSAS"), nil, "GET", "/azure/"+url.QueryEscape(account)+"/table/"+url.QueryEscape(table)+"/"+url.QueryEscape(level), new(AzureTableSharedAccessSignature), nil)
 	return responseObject.(*AzureTableSharedAccessSignature), err
 }
 
@@ -586,12 +946,20 @@ func (auth *Auth) AzureTableSAS_SignedURL(account, table, level string, duration
 //
 // See #azureContainers
 func (auth *Auth) AzureContainers(account, continuationToken string) (*AzureListContainersResponse, error) {
+	return auth.AzureContainersWithContext(auth.Context, account, continuationToken)
+}
+
+// AzureContainersWithContext is the same as AzureContainers, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See AzureContainers for more details.
+func (auth *Auth) AzureContainersWithContext(ctx context.Context, account, continuationToken string) (*AzureListContainersResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
 	}
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/azure/"+url.QueryEscape(account)+"/containers", new(AzureListContainersResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "azureContainers"), nil, "GET", "/azure/"+url.QueryEscape(account)+"/containers", new(AzureListContainersResponse), v)
 	return responseObject.(*AzureListContainersResponse), err
 }
 
@@ -610,6 +978,23 @@ func (auth *Auth) AzureContainers_SignedURL(account, continuationToken string, d
 	return (&cd).SignedURL("/azure/"+url.QueryEscape(account)+"/containers", v, duration)
 }
 
+// AzureContainersAll returns an iterator over the string items of all
+// pages of AzureContainers results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See AzureContainers for more details.
+func (auth *Auth) AzureContainersAll(ctx context.Context, account string) *StringIterator {
+	return NewStringIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]string, string, error) {
+		page, err := auth.AzureContainersWithContext(ctx, account, continuationToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Containers, page.ContinuationToken, nil
+	})
+}
+
 // Get a shared access signature (SAS) string for use with a specific Azure
 // Blob Storage container.
 //
@@ -625,8 +1010,16 @@ func (auth *Auth) AzureContainers_SignedURL(account, continuationToken string, d
 //
 // See #azureContainerSAS
 func (auth *Auth) AzureContainerSAS(account, container, level string) (*AzureBlobSharedAccessSignature, error) {
+	return auth.AzureContainerSASWithContext(auth.Context, account, container, level)
+}
+
+// AzureContainerSASWithContext is the same as AzureContainerSAS, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See AzureContainerSAS for more details.
+func (auth *Auth) AzureContainerSASWithContext(ctx context.Context, account, container, level string) (*AzureBlobSharedAccessSignature, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/azure/"+url.QueryEscape(account)+"/containers/"+url.QueryEscape(container)+"/"+url.QueryEscape(level), new(AzureBlobSharedAccessSignature), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "azureContainerSAS"), nil, "GET", "/azure/"+url.QueryEscape(account)+"/containers/"+url.QueryEscape(container)+"/"+url.QueryEscape(level), new(AzureBlobSharedAccessSignature), nil)
 	return responseObject.(*AzureBlobSharedAccessSignature), err
 }
 
@@ -657,8 +1050,16 @@ func (auth *Auth) AzureContainerSAS_SignedURL(account, container, level string,
 //
 // See #sentryDSN
 func (auth *Auth) SentryDSN(project string) (*SentryDSNResponse, error) {
+	return auth.SentryDSNWithContext(auth.Context, project)
+}
+
+// SentryDSNWithContext is the same as SentryDSN, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See SentryDSN for more details.
+func (auth *Auth) SentryDSNWithContext(ctx context.Context, project string) (*SentryDSNResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/sentry/"+url.QueryEscape(project)+"/dsn", new(SentryDSNResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "sentryDSN"), nil, "GET", "/sentry/"+url.QueryEscape(project)+"/dsn", new(SentryDSNResponse), nil)
 	return responseObject.(*SentryDSNResponse), err
 }
 
@@ -682,8 +1083,16 @@ func (auth *Auth) SentryDSN_SignedURL(project string, duration time.Duration) (*
 //
 // See #statsumToken
 func (auth *Auth) StatsumToken(project string) (*StatsumTokenResponse, error) {
+	return auth.StatsumTokenWithContext(auth.Context, project)
+}
+
+// StatsumTokenWithContext is the same as StatsumToken, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See StatsumToken for more details.
+func (auth *Auth) StatsumTokenWithContext(ctx context.Context, project string) (*StatsumTokenResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/statsum/"+url.QueryEscape(project)+"/token", new(StatsumTokenResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "statsumToken"), nil, "GET", "/statsum/"+url.QueryEscape(project)+"/token", new(StatsumTokenResponse), nil)
 	return responseObject.(*StatsumTokenResponse), err
 }
 
@@ -714,8 +1123,16 @@ func (auth *Auth) StatsumToken_SignedURL(project string, duration time.Duration)
 //
 // See #websocktunnelToken
 func (auth *Auth) WebsocktunnelToken(wstAudience, wstClient string) (*WebsocktunnelTokenResponse, error) {
+	return auth.WebsocktunnelTokenWithContext(auth.Context, wstAudience, wstClient)
+}
+
+// WebsocktunnelTokenWithContext is the same as WebsocktunnelToken, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See WebsocktunnelToken for more details.
+func (auth *Auth) WebsocktunnelTokenWithContext(ctx context.Context, wstAudience, wstClient string) (*WebsocktunnelTokenResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/websocktunnel/"+url.QueryEscape(wstAudience)+"/"+url.QueryEscape(wstClient), new(WebsocktunnelTokenResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "websocktunnelToken"), nil, "GET", "/websocktunnel/"+url.QueryEscape(wstAudience)+"/"+url.QueryEscape(wstClient), new(WebsocktunnelTokenResponse), nil)
 	return responseObject.(*WebsocktunnelTokenResponse), err
 }
 
@@ -746,8 +1163,16 @@ func (auth *Auth) WebsocktunnelToken_SignedURL(wstAudience, wstClient string, du
 //
 // See #gcpCredentials
 func (auth *Auth) GcpCredentials(projectId, serviceAccount string) (*GCPCredentialsResponse, error) {
+	return auth.GcpCredentialsWithContext(auth.Context, projectId, serviceAccount)
+}
+
+// GcpCredentialsWithContext is the same as GcpCredentials, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See GcpCredentials for more details.
+func (auth *Auth) GcpCredentialsWithContext(ctx context.Context, projectId, serviceAccount string) (*GCPCredentialsResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/gcp/credentials/"+url.QueryEscape(projectId)+"/"+url.QueryEscape(serviceAccount), new(GCPCredentialsResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "gcpCredentials"), nil, "GET", "/gcp/credentials/"+url.QueryEscape(projectId)+"/"+url.QueryEscape(serviceAccount), new(GCPCredentialsResponse), nil)
 	return responseObject.(*GCPCredentialsResponse), err
 }
 
@@ -771,8 +1196,16 @@ func (auth *Auth) GcpCredentials_SignedURL(projectId, serviceAccount string, dur
 //
 // See #authenticateHawk
 func (auth *Auth) AuthenticateHawk(payload *HawkSignatureAuthenticationRequest) (*HawkSignatureAuthenticationResponse, error) {
+	return auth.AuthenticateHawkWithContext(auth.Context, payload)
+}
+
+// AuthenticateHawkWithContext is the same as AuthenticateHawk, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See AuthenticateHawk for more details.
+func (auth *Auth) AuthenticateHawkWithContext(ctx context.Context, payload *HawkSignatureAuthenticationRequest) (*HawkSignatureAuthenticationResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/authenticate-hawk", new(HawkSignatureAuthenticationResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "authenticateHawk"), payload, "POST", "/authenticate-hawk", new(HawkSignatureAuthenticationResponse), nil)
 	return responseObject.(*HawkSignatureAuthenticationResponse), err
 }
 
@@ -790,8 +1223,16 @@ func (auth *Auth) AuthenticateHawk(payload *HawkSignatureAuthenticationRequest)
 //
 // See #testAuthenticate
 func (auth *Auth) TestAuthenticate(payload *TestAuthenticateRequest) (*TestAuthenticateResponse, error) {
+	return auth.TestAuthenticateWithContext(auth.Context, payload)
+}
+
+// TestAuthenticateWithContext is the same as TestAuthenticate, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See TestAuthenticate for more details.
+func (auth *Auth) TestAuthenticateWithContext(ctx context.Context, payload *TestAuthenticateRequest) (*TestAuthenticateResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/test-authenticate", new(TestAuthenticateResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "testAuthenticate"), payload, "POST", "/test-authenticate", new(TestAuthenticateResponse), nil)
 	return responseObject.(*TestAuthenticateResponse), err
 }
 
@@ -813,7 +1254,167 @@ func (auth *Auth) TestAuthenticate(payload *TestAuthenticateRequest) (*TestAuthe
 //
 // See #testAuthenticateGet
 func (auth *Auth) TestAuthenticateGet() (*TestAuthenticateResponse, error) {
+	return auth.TestAuthenticateGetWithContext(auth.Context)
+}
+
+// TestAuthenticateGetWithContext is the same as TestAuthenticateGet, except that the
+// request is bound to ctx rather than to auth.Context.
+//
+// See TestAuthenticateGet for more details.
+func (auth *Auth) TestAuthenticateGetWithContext(ctx context.Context) (*TestAuthenticateResponse, error) {
 	cd := tcclient.Client(*auth)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/test-authenticate-get/", new(TestAuthenticateResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "auth", "testAuthenticateGet"), nil, "GET", "/test-authenticate-get/", new(TestAuthenticateResponse), nil)
 	return responseObject.(*TestAuthenticateResponse), err
 }
+
+// GetClientResponseIterator iterates over the GetClientResponse items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type GetClientResponseIterator struct {
+	*tcclient.Pager
+	items []GetClientResponse
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *GetClientResponseIterator) Item() GetClientResponse {
+	return it.items[it.Index()]
+}
+
+// NewGetClientResponseIterator returns a GetClientResponseIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewGetClientResponseIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]GetClientResponse, string, error)) *GetClientResponseIterator {
+	it := new(GetClientResponseIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// StringIterator iterates over the string items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type StringIterator struct {
+	*tcclient.Pager
+	items []string
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *StringIterator) Item() string {
+	return it.items[it.Index()]
+}
+
+// NewStringIterator returns a StringIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewStringIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]string, string, error)) *StringIterator {
+	it := new(StringIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// GetRoleResponseIterator iterates over the GetRoleResponse items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type GetRoleResponseIterator struct {
+	*tcclient.Pager
+	items []GetRoleResponse
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *GetRoleResponseIterator) Item() GetRoleResponse {
+	return it.items[it.Index()]
+}
+
+// NewGetRoleResponseIterator returns a GetRoleResponseIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewGetRoleResponseIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]GetRoleResponse, string, error)) *GetRoleResponseIterator {
+	it := new(GetRoleResponseIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"createClient": scopes.AllOf{
+		scopes.RequiredScope("auth:create-client:<clientId>"),
+		scopes.ForEachIn{For: "scope", In: "scopes", Each: "<scope>"},
+	},
+	"resetAccessToken": scopes.RequiredScope("auth:reset-access-token:<clientId>"),
+	"updateClient": scopes.AllOf{
+		scopes.RequiredScope("auth:update-client:<clientId>"),
+		scopes.ForEachIn{For: "scope", In: "scopesAdded", Each: "<scope>"},
+	},
+	"enableClient":  scopes.RequiredScope("auth:enable-client:<clientId>"),
+	"disableClient": scopes.RequiredScope("auth:disable-client:<clientId>"),
+	"deleteClient":  scopes.RequiredScope("auth:delete-client:<clientId>"),
+	"createRole": scopes.AllOf{
+		scopes.RequiredScope("auth:create-role:<roleId>"),
+		scopes.ForEachIn{For: "scope", In: "scopes", Each: "<scope>"},
+	},
+	"updateRole": scopes.AllOf{
+		scopes.RequiredScope("auth:update-role:<roleId>"),
+		scopes.ForEachIn{For: "scope", In: "scopesAdded", Each: "<scope>"},
+	},
+	"deleteRole": scopes.RequiredScope("auth:delete-role:<roleId>"),
+	"awsS3Credentials": scopes.IfThen{
+		If: "levelIsReadOnly",
+		Then: scopes.AnyOf{
+			scopes.RequiredScope("auth:aws-s3:read-only:<bucket>/<prefix>"),
+			scopes.RequiredScope("auth:aws-s3:read-write:<bucket>/<prefix>"),
+		},
+	},
+	"azureAccounts": scopes.RequiredScope("auth:azure-table:list-accounts"),
+	"azureTables":   scopes.RequiredScope("auth:azure-table:list-tables:<account>"),
+	"azureTableSAS": scopes.IfThen{
+		If: "levelIsReadOnly",
+		Then: scopes.AnyOf{
+			scopes.RequiredScope("auth:azure-table:read-only:<account>/<table>"),
+			scopes.RequiredScope("auth:azure-table:read-write:<account>/<table>"),
+		},
+	},
+	"azureContainers": scopes.RequiredScope("auth:azure-container:list-containers:<account>"),
+	"azureContainerSAS": scopes.IfThen{
+		If: "levelIsReadOnly",
+		Then: scopes.AnyOf{
+			scopes.RequiredScope("auth:azure-container:read-only:<account>/<container>"),
+			scopes.RequiredScope("auth:azure-container:read-write:<account>/<container>"),
+		},
+	},
+	"sentryDSN":          scopes.RequiredScope("auth:sentry:<project>"),
+	"statsumToken":       scopes.RequiredScope("auth:statsum:<project>"),
+	"websocktunnelToken": scopes.RequiredScope("auth:websocktunnel-token:<wstAudience>/<wstClient>"),
+	"gcpCredentials":     scopes.RequiredScope("auth:gcp:access-token:<projectId>/<serviceAccount>"),
+}
@@ -5,6 +5,7 @@ package tcauth
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
 )
@@ -42,7 +43,7 @@ type (
 		//   * "auth-failed"
 		//
 		// See https://taskcluster-staging.net/schemas/auth/v1/authenticate-hawk-response.json#/oneOf[1]/properties/status
-		Status string `json:"status"`
+		Status AuthenticationFailedResponseStatus `json:"status"`
 	}
 
 	// Response from a request to authenticate a hawk request.
@@ -86,7 +87,7 @@ type (
 		//   * "hawk"
 		//
 		// See https://taskcluster-staging.net/schemas/auth/v1/authenticate-hawk-response.json#/oneOf[0]/properties/scheme
-		Scheme string `json:"scheme"`
+		Scheme Scheme `json:"scheme"`
 
 		// List of scopes the client is authorized to access.  Scopes must be
 		// composed of printable ASCII characters and spaces.
@@ -105,7 +106,7 @@ type (
 		//   * "auth-success"
 		//
 		// See https://taskcluster-staging.net/schemas/auth/v1/authenticate-hawk-response.json#/oneOf[0]/properties/status
-		Status string `json:"status"`
+		Status AuthenticationSuccessfulResponseStatus `json:"status"`
 	}
 
 	// Response to a request for an Shared-Access-Signature to access an Azure
@@ -671,7 +672,7 @@ type (
 		//   * "connect"
 		//
 		// See https://taskcluster-staging.net/schemas/auth/v1/authenticate-hawk-request.json#/properties/method
-		Method string `json:"method"`
+		Method Method `json:"method"`
 
 		// Port on which the request came in, this is typically `80` or `443`.
 		// If you are running behind a reverse proxy look for the `x-forwarded-port`
@@ -938,3 +939,110 @@ func (this *HawkSignatureAuthenticationResponse) UnmarshalJSON(data []byte) erro
 	*this = append((*this)[0:0], data...)
 	return nil
 }
+
+// AuthenticationFailedResponseStatus is the type of the `status` property of AuthenticationFailedResponse.
+// Its possible values are declared as constants.
+type AuthenticationFailedResponseStatus string
+
+// Possible values of AuthenticationFailedResponseStatus
+const (
+	AuthenticationFailedResponseStatusAuthFailed AuthenticationFailedResponseStatus = "auth-failed"
+)
+
+// Valid returns true if this is one of the possible values of AuthenticationFailedResponseStatus.
+func (this AuthenticationFailedResponseStatus) Valid() bool {
+	switch this {
+	case AuthenticationFailedResponseStatusAuthFailed:
+		return true
+	}
+	return false
+}
+
+// Scheme is the type of the `scheme` property of AuthenticationSuccessfulResponse.
+// Its possible values are declared as constants.
+type Scheme string
+
+// Possible values of Scheme
+const (
+	SchemeHawk Scheme = "hawk"
+)
+
+// Valid returns true if this is one of the possible values of Scheme.
+func (this Scheme) Valid() bool {
+	switch this {
+	case SchemeHawk:
+		return true
+	}
+	return false
+}
+
+// AuthenticationSuccessfulResponseStatus is the type of the `status` property of AuthenticationSuccessfulResponse.
+// Its possible values are declared as constants.
+type AuthenticationSuccessfulResponseStatus string
+
+// Possible values of AuthenticationSuccessfulResponseStatus
+const (
+	AuthenticationSuccessfulResponseStatusAuthSuccess AuthenticationSuccessfulResponseStatus = "auth-success"
+)
+
+// Valid returns true if this is one of the possible values of AuthenticationSuccessfulResponseStatus.
+func (this AuthenticationSuccessfulResponseStatus) Valid() bool {
+	switch this {
+	case AuthenticationSuccessfulResponseStatusAuthSuccess:
+		return true
+	}
+	return false
+}
+
+// Method is the type of the `method` property of HawkSignatureAuthenticationRequest.
+// Its possible values are declared as constants.
+type Method string
+
+// Possible values of Method
+const (
+	MethodGet         Method = "get"
+	MethodPost        Method = "post"
+	MethodPut         Method = "put"
+	MethodHead        Method = "head"
+	MethodDelete      Method = "delete"
+	MethodOptions     Method = "options"
+	MethodTrace       Method = "trace"
+	MethodCopy        Method = "copy"
+	MethodLock        Method = "lock"
+	MethodMkcol       Method = "mkcol"
+	MethodMove        Method = "move"
+	MethodPurge       Method = "purge"
+	MethodPropfind    Method = "propfind"
+	MethodProppatch   Method = "proppatch"
+	MethodUnlock      Method = "unlock"
+	MethodReport      Method = "report"
+	MethodMkactivity  Method = "mkactivity"
+	MethodCheckout    Method = "checkout"
+	MethodMerge       Method = "merge"
+	MethodMSearch     Method = "m-search"
+	MethodNotify      Method = "notify"
+	MethodSubscribe   Method = "subscribe"
+	MethodUnsubscribe Method = "unsubscribe"
+	MethodPatch       Method = "patch"
+	MethodSearch      Method = "search"
+	MethodConnect     Method = "connect"
+)
+
+// Valid returns true if this is one of the possible values of Method.
+func (this Method) Valid() bool {
+	switch this {
+	case MethodGet, MethodPost, MethodPut, MethodHead, MethodDelete, MethodOptions, MethodTrace, MethodCopy, MethodLock, MethodMkcol, MethodMove, MethodPurge, MethodPropfind, MethodProppatch, MethodUnlock, MethodReport, MethodMkactivity, MethodCheckout, MethodMerge, MethodMSearch, MethodNotify, MethodSubscribe, MethodUnsubscribe, MethodPatch, MethodSearch, MethodConnect:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid Method.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this Method) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid Method %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
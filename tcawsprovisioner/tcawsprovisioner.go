@@ -63,10 +63,12 @@
 package tcawsprovisioner
 
 import (
+	"context"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 const (
@@ -110,6 +112,65 @@ func NewFromEnv() *AwsProvisioner {
 	}
 }
 
+// NewFromProfile returns an *AwsProvisioner configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. If no root
+// URL is configured, DefaultBaseURL is used.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*AwsProvisioner, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	client := New(creds)
+	if rootURL != "" {
+		client.BaseURL = tcclient.BaseURL(rootURL, "aws-provisioner", "v1")
+	}
+	return client, nil
+}
+
+// API is the interface implemented by *AwsProvisioner, comprising all of its
+// API methods. Code that depends on API rather than *AwsProvisioner can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	ListWorkerTypeSummaries() (*ListWorkerTypeSummariesResponse, error)
+	ListWorkerTypeSummariesWithContext(ctx context.Context) (*ListWorkerTypeSummariesResponse, error)
+	CreateWorkerType(workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error)
+	CreateWorkerTypeWithContext(ctx context.Context, workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error)
+	UpdateWorkerType(workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error)
+	UpdateWorkerTypeWithContext(ctx context.Context, workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error)
+	WorkerTypeLastModified(workerType string) (*WorkerTypeLastModified, error)
+	WorkerTypeLastModifiedWithContext(ctx context.Context, workerType string) (*WorkerTypeLastModified, error)
+	WorkerType(workerType string) (*WorkerTypeResponse, error)
+	WorkerTypeWithContext(ctx context.Context, workerType string) (*WorkerTypeResponse, error)
+	WorkerType_SignedURL(workerType string, duration time.Duration) (*url.URL, error)
+	RemoveWorkerType(workerType string) error
+	RemoveWorkerTypeWithContext(ctx context.Context, workerType string) error
+	ListWorkerTypes() (*ListWorkerTypes, error)
+	ListWorkerTypesWithContext(ctx context.Context) (*ListWorkerTypes, error)
+	CreateSecret(token string, payload *SecretRequest) error
+	CreateSecretWithContext(ctx context.Context, token string, payload *SecretRequest) error
+	GetSecret(token string) (*SecretResponse, error)
+	GetSecretWithContext(ctx context.Context, token string) (*SecretResponse, error)
+	InstanceStarted(instanceId, token string) error
+	InstanceStartedWithContext(ctx context.Context, instanceId, token string) error
+	RemoveSecret(token string) error
+	RemoveSecretWithContext(ctx context.Context, token string) error
+	GetLaunchSpecs(workerType string) (*LaunchSpecsResponse, error)
+	GetLaunchSpecsWithContext(ctx context.Context, workerType string) (*LaunchSpecsResponse, error)
+	GetLaunchSpecs_SignedURL(workerType string, duration time.Duration) (*url.URL, error)
+	State(workerType string) error
+	StateWithContext(ctx context.Context, workerType string) error
+	BackendStatus() (*BackendStatusResponse, error)
+	BackendStatusWithContext(ctx context.Context) (*BackendStatusResponse, error)
+	Ping() error
+	PingWithContext(ctx context.Context) error
+}
+
+var _ API = (*AwsProvisioner)(nil)
+
 // Return a list of worker types, including some summary information about
 // current capacity for each.  While this list includes all defined worker types,
 // there may be running EC2 instances for deleted worker types that are not
@@ -117,8 +178,16 @@ func NewFromEnv() *AwsProvisioner {
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#listWorkerTypeSummaries
 func (awsProvisioner *AwsProvisioner) ListWorkerTypeSummaries() (*ListWorkerTypeSummariesResponse, error) {
+	return awsProvisioner.ListWorkerTypeSummariesWithContext(awsProvisioner.Context)
+}
+
+// ListWorkerTypeSummariesWithContext is the same as ListWorkerTypeSummaries, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See ListWorkerTypeSummaries for more details.
+func (awsProvisioner *AwsProvisioner) ListWorkerTypeSummariesWithContext(ctx context.Context) (*ListWorkerTypeSummariesResponse, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/list-worker-type-summaries", new(ListWorkerTypeSummariesResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "listWorkerTypeSummaries"), nil, "GET", "/list-worker-type-summaries", new(ListWorkerTypeSummariesResponse), nil)
 	return responseObject.(*ListWorkerTypeSummariesResponse), err
 }
 
@@ -151,8 +220,16 @@ func (awsProvisioner *AwsProvisioner) ListWorkerTypeSummaries() (*ListWorkerType
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#createWorkerType
 func (awsProvisioner *AwsProvisioner) CreateWorkerType(workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error) {
+	return awsProvisioner.CreateWorkerTypeWithContext(awsProvisioner.Context, workerType, payload)
+}
+
+// CreateWorkerTypeWithContext is the same as CreateWorkerType, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See CreateWorkerType for more details.
+func (awsProvisioner *AwsProvisioner) CreateWorkerTypeWithContext(ctx context.Context, workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/worker-type/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "createWorkerType"), payload, "PUT", "/worker-type/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
 	return responseObject.(*WorkerTypeResponse), err
 }
 
@@ -173,8 +250,16 @@ func (awsProvisioner *AwsProvisioner) CreateWorkerType(workerType string, payloa
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#updateWorkerType
 func (awsProvisioner *AwsProvisioner) UpdateWorkerType(workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error) {
+	return awsProvisioner.UpdateWorkerTypeWithContext(awsProvisioner.Context, workerType, payload)
+}
+
+// UpdateWorkerTypeWithContext is the same as UpdateWorkerType, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See UpdateWorkerType for more details.
+func (awsProvisioner *AwsProvisioner) UpdateWorkerTypeWithContext(ctx context.Context, workerType string, payload *CreateWorkerTypeRequest) (*WorkerTypeResponse, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/worker-type/"+url.QueryEscape(workerType)+"/update", new(WorkerTypeResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "updateWorkerType"), payload, "POST", "/worker-type/"+url.QueryEscape(workerType)+"/update", new(WorkerTypeResponse), nil)
 	return responseObject.(*WorkerTypeResponse), err
 }
 
@@ -186,8 +271,16 @@ func (awsProvisioner *AwsProvisioner) UpdateWorkerType(workerType string, payloa
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#workerTypeLastModified
 func (awsProvisioner *AwsProvisioner) WorkerTypeLastModified(workerType string) (*WorkerTypeLastModified, error) {
+	return awsProvisioner.WorkerTypeLastModifiedWithContext(awsProvisioner.Context, workerType)
+}
+
+// WorkerTypeLastModifiedWithContext is the same as WorkerTypeLastModified, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See WorkerTypeLastModified for more details.
+func (awsProvisioner *AwsProvisioner) WorkerTypeLastModifiedWithContext(ctx context.Context, workerType string) (*WorkerTypeLastModified, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-type-last-modified/"+url.QueryEscape(workerType), new(WorkerTypeLastModified), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "workerTypeLastModified"), nil, "GET", "/worker-type-last-modified/"+url.QueryEscape(workerType), new(WorkerTypeLastModified), nil)
 	return responseObject.(*WorkerTypeLastModified), err
 }
 
@@ -204,8 +297,16 @@ func (awsProvisioner *AwsProvisioner) WorkerTypeLastModified(workerType string)
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#workerType
 func (awsProvisioner *AwsProvisioner) WorkerType(workerType string) (*WorkerTypeResponse, error) {
+	return awsProvisioner.WorkerTypeWithContext(awsProvisioner.Context, workerType)
+}
+
+// WorkerTypeWithContext is the same as WorkerType, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See WorkerType for more details.
+func (awsProvisioner *AwsProvisioner) WorkerTypeWithContext(ctx context.Context, workerType string) (*WorkerTypeResponse, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-type/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "workerType"), nil, "GET", "/worker-type/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
 	return responseObject.(*WorkerTypeResponse), err
 }
 
@@ -238,8 +339,16 @@ func (awsProvisioner *AwsProvisioner) WorkerType_SignedURL(workerType string, du
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#removeWorkerType
 func (awsProvisioner *AwsProvisioner) RemoveWorkerType(workerType string) error {
+	return awsProvisioner.RemoveWorkerTypeWithContext(awsProvisioner.Context, workerType)
+}
+
+// RemoveWorkerTypeWithContext is the same as RemoveWorkerType, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See RemoveWorkerType for more details.
+func (awsProvisioner *AwsProvisioner) RemoveWorkerTypeWithContext(ctx context.Context, workerType string) error {
 	cd := tcclient.Client(*awsProvisioner)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/worker-type/"+url.QueryEscape(workerType), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "removeWorkerType"), nil, "DELETE", "/worker-type/"+url.QueryEscape(workerType), nil, nil)
 	return err
 }
 
@@ -250,8 +359,16 @@ func (awsProvisioner *AwsProvisioner) RemoveWorkerType(workerType string) error
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#listWorkerTypes
 func (awsProvisioner *AwsProvisioner) ListWorkerTypes() (*ListWorkerTypes, error) {
+	return awsProvisioner.ListWorkerTypesWithContext(awsProvisioner.Context)
+}
+
+// ListWorkerTypesWithContext is the same as ListWorkerTypes, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See ListWorkerTypes for more details.
+func (awsProvisioner *AwsProvisioner) ListWorkerTypesWithContext(ctx context.Context) (*ListWorkerTypes, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/list-worker-types", new(ListWorkerTypes), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "listWorkerTypes"), nil, "GET", "/list-worker-types", new(ListWorkerTypes), nil)
 	return responseObject.(*ListWorkerTypes), err
 }
 
@@ -267,8 +384,16 @@ func (awsProvisioner *AwsProvisioner) ListWorkerTypes() (*ListWorkerTypes, error
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#createSecret
 func (awsProvisioner *AwsProvisioner) CreateSecret(token string, payload *SecretRequest) error {
+	return awsProvisioner.CreateSecretWithContext(awsProvisioner.Context, token, payload)
+}
+
+// CreateSecretWithContext is the same as CreateSecret, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See CreateSecret for more details.
+func (awsProvisioner *AwsProvisioner) CreateSecretWithContext(ctx context.Context, token string, payload *SecretRequest) error {
 	cd := tcclient.Client(*awsProvisioner)
-	_, _, err := (&cd).APICall(payload, "PUT", "/secret/"+url.QueryEscape(token), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "createSecret"), payload, "PUT", "/secret/"+url.QueryEscape(token), nil, nil)
 	return err
 }
 
@@ -282,8 +407,16 @@ func (awsProvisioner *AwsProvisioner) CreateSecret(token string, payload *Secret
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#getSecret
 func (awsProvisioner *AwsProvisioner) GetSecret(token string) (*SecretResponse, error) {
+	return awsProvisioner.GetSecretWithContext(awsProvisioner.Context, token)
+}
+
+// GetSecretWithContext is the same as GetSecret, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See GetSecret for more details.
+func (awsProvisioner *AwsProvisioner) GetSecretWithContext(ctx context.Context, token string) (*SecretResponse, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/secret/"+url.QueryEscape(token), new(SecretResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "getSecret"), nil, "GET", "/secret/"+url.QueryEscape(token), new(SecretResponse), nil)
 	return responseObject.(*SecretResponse), err
 }
 
@@ -295,8 +428,16 @@ func (awsProvisioner *AwsProvisioner) GetSecret(token string) (*SecretResponse,
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#instanceStarted
 func (awsProvisioner *AwsProvisioner) InstanceStarted(instanceId, token string) error {
+	return awsProvisioner.InstanceStartedWithContext(awsProvisioner.Context, instanceId, token)
+}
+
+// InstanceStartedWithContext is the same as InstanceStarted, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See InstanceStarted for more details.
+func (awsProvisioner *AwsProvisioner) InstanceStartedWithContext(ctx context.Context, instanceId, token string) error {
 	cd := tcclient.Client(*awsProvisioner)
-	_, _, err := (&cd).APICall(nil, "GET", "/instance-started/"+url.QueryEscape(instanceId)+"/"+url.QueryEscape(token), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "instanceStarted"), nil, "GET", "/instance-started/"+url.QueryEscape(instanceId)+"/"+url.QueryEscape(token), nil, nil)
 	return err
 }
 
@@ -309,8 +450,16 @@ func (awsProvisioner *AwsProvisioner) InstanceStarted(instanceId, token string)
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#removeSecret
 func (awsProvisioner *AwsProvisioner) RemoveSecret(token string) error {
+	return awsProvisioner.RemoveSecretWithContext(awsProvisioner.Context, token)
+}
+
+// RemoveSecretWithContext is the same as RemoveSecret, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See RemoveSecret for more details.
+func (awsProvisioner *AwsProvisioner) RemoveSecretWithContext(ctx context.Context, token string) error {
 	cd := tcclient.Client(*awsProvisioner)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/secret/"+url.QueryEscape(token), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "removeSecret"), nil, "DELETE", "/secret/"+url.QueryEscape(token), nil, nil)
 	return err
 }
 
@@ -329,8 +478,16 @@ func (awsProvisioner *AwsProvisioner) RemoveSecret(token string) error {
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#getLaunchSpecs
 func (awsProvisioner *AwsProvisioner) GetLaunchSpecs(workerType string) (*LaunchSpecsResponse, error) {
+	return awsProvisioner.GetLaunchSpecsWithContext(awsProvisioner.Context, workerType)
+}
+
+// GetLaunchSpecsWithContext is the same as GetLaunchSpecs, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See GetLaunchSpecs for more details.
+func (awsProvisioner *AwsProvisioner) GetLaunchSpecsWithContext(ctx context.Context, workerType string) (*LaunchSpecsResponse, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-type/"+url.QueryEscape(workerType)+"/launch-specifications", new(LaunchSpecsResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "getLaunchSpecs"), nil, "GET", "/worker-type/"+url.QueryEscape(workerType)+"/launch-specifications", new(LaunchSpecsResponse), nil)
 	return responseObject.(*LaunchSpecsResponse), err
 }
 
@@ -354,8 +511,16 @@ func (awsProvisioner *AwsProvisioner) GetLaunchSpecs_SignedURL(workerType string
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#state
 func (awsProvisioner *AwsProvisioner) State(workerType string) error {
+	return awsProvisioner.StateWithContext(awsProvisioner.Context, workerType)
+}
+
+// StateWithContext is the same as State, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See State for more details.
+func (awsProvisioner *AwsProvisioner) StateWithContext(ctx context.Context, workerType string) error {
 	cd := tcclient.Client(*awsProvisioner)
-	_, _, err := (&cd).APICall(nil, "GET", "/state/"+url.QueryEscape(workerType), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "state"), nil, "GET", "/state/"+url.QueryEscape(workerType), nil, nil)
 	return err
 }
 
@@ -371,8 +536,16 @@ func (awsProvisioner *AwsProvisioner) State(workerType string) error {
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#backendStatus
 func (awsProvisioner *AwsProvisioner) BackendStatus() (*BackendStatusResponse, error) {
+	return awsProvisioner.BackendStatusWithContext(awsProvisioner.Context)
+}
+
+// BackendStatusWithContext is the same as BackendStatus, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See BackendStatus for more details.
+func (awsProvisioner *AwsProvisioner) BackendStatusWithContext(ctx context.Context) (*BackendStatusResponse, error) {
 	cd := tcclient.Client(*awsProvisioner)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/backend-status", new(BackendStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "backendStatus"), nil, "GET", "/backend-status", new(BackendStatusResponse), nil)
 	return responseObject.(*BackendStatusResponse), err
 }
 
@@ -381,7 +554,34 @@ func (awsProvisioner *AwsProvisioner) BackendStatus() (*BackendStatusResponse, e
 //
 // See https://docs.taskcluster.net/reference/core/aws-provisioner/api-docs#ping
 func (awsProvisioner *AwsProvisioner) Ping() error {
+	return awsProvisioner.PingWithContext(awsProvisioner.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to awsProvisioner.Context.
+//
+// See Ping for more details.
+func (awsProvisioner *AwsProvisioner) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*awsProvisioner)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "aws-provisioner", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"createWorkerType": scopes.RequiredScope("aws-provisioner:manage-worker-type:<workerType>"),
+	"updateWorkerType": scopes.RequiredScope("aws-provisioner:manage-worker-type:<workerType>"),
+	"workerType": scopes.AnyOf{
+		scopes.RequiredScope("aws-provisioner:view-worker-type:<workerType>"),
+		scopes.RequiredScope("aws-provisioner:manage-worker-type:<workerType>"),
+	},
+	"removeWorkerType": scopes.RequiredScope("aws-provisioner:manage-worker-type:<workerType>"),
+	"createSecret":     scopes.RequiredScope("aws-provisioner:create-secret:<workerType>"),
+	"getLaunchSpecs": scopes.AnyOf{
+		scopes.RequiredScope("aws-provisioner:view-worker-type:<workerType>"),
+		scopes.RequiredScope("aws-provisioner:manage-worker-type:<workerType>"),
+	},
+}
@@ -37,10 +37,12 @@
 package tcec2manager
 
 import (
+	"context"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 const (
@@ -84,14 +86,103 @@ func NewFromEnv() *EC2Manager {
 	}
 }
 
+// NewFromProfile returns an *EC2Manager configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. If no root
+// URL is configured, DefaultBaseURL is used.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*EC2Manager, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	client := New(creds)
+	if rootURL != "" {
+		client.BaseURL = tcclient.BaseURL(rootURL, "ec2-manager", "v1")
+	}
+	return client, nil
+}
+
+// API is the interface implemented by *EC2Manager, comprising all of its
+// API methods. Code that depends on API rather than *EC2Manager can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	ListWorkerTypes() (*ListOfWorkerTypes, error)
+	ListWorkerTypesWithContext(ctx context.Context) (*ListOfWorkerTypes, error)
+	RunInstance(workerType string, payload *MakeASpotRequest) error
+	RunInstanceWithContext(ctx context.Context, workerType string, payload *MakeASpotRequest) error
+	TerminateWorkerType(workerType string) error
+	TerminateWorkerTypeWithContext(ctx context.Context, workerType string) error
+	WorkerTypeStats(workerType string) (*OverviewOfComputationalResources, error)
+	WorkerTypeStatsWithContext(ctx context.Context, workerType string) (*OverviewOfComputationalResources, error)
+	WorkerTypeHealth(workerType string) (*HealthOfTheEC2Account, error)
+	WorkerTypeHealthWithContext(ctx context.Context, workerType string) (*HealthOfTheEC2Account, error)
+	WorkerTypeErrors(workerType string) (*Errors, error)
+	WorkerTypeErrorsWithContext(ctx context.Context, workerType string) (*Errors, error)
+	WorkerTypeState(workerType string) (*OverviewOfComputationalResources1, error)
+	WorkerTypeStateWithContext(ctx context.Context, workerType string) (*OverviewOfComputationalResources1, error)
+	EnsureKeyPair(name string, payload *SSHPublicKey) error
+	EnsureKeyPairWithContext(ctx context.Context, name string, payload *SSHPublicKey) error
+	EnsureKeyPair_SignedURL(name string, duration time.Duration) (*url.URL, error)
+	RemoveKeyPair(name string) error
+	RemoveKeyPairWithContext(ctx context.Context, name string) error
+	TerminateInstance(region, instanceId string) error
+	TerminateInstanceWithContext(ctx context.Context, region, instanceId string) error
+	GetPrices() (*ListOfPrices, error)
+	GetPricesWithContext(ctx context.Context) (*ListOfPrices, error)
+	GetSpecificPrices(payload *ListOfRestrictionsForPrices) (*ListOfPrices, error)
+	GetSpecificPricesWithContext(ctx context.Context, payload *ListOfRestrictionsForPrices) (*ListOfPrices, error)
+	GetHealth() (*HealthOfTheEC2Account, error)
+	GetHealthWithContext(ctx context.Context) (*HealthOfTheEC2Account, error)
+	GetRecentErrors() (*Errors, error)
+	GetRecentErrorsWithContext(ctx context.Context) (*Errors, error)
+	Regions() error
+	RegionsWithContext(ctx context.Context) error
+	Regions_SignedURL(duration time.Duration) (*url.URL, error)
+	AmiUsage() error
+	AmiUsageWithContext(ctx context.Context) error
+	AmiUsage_SignedURL(duration time.Duration) (*url.URL, error)
+	EbsUsage() error
+	EbsUsageWithContext(ctx context.Context) error
+	EbsUsage_SignedURL(duration time.Duration) (*url.URL, error)
+	DbpoolStats() error
+	DbpoolStatsWithContext(ctx context.Context) error
+	DbpoolStats_SignedURL(duration time.Duration) (*url.URL, error)
+	AllState() error
+	AllStateWithContext(ctx context.Context) error
+	AllState_SignedURL(duration time.Duration) (*url.URL, error)
+	SqsStats() error
+	SqsStatsWithContext(ctx context.Context) error
+	SqsStats_SignedURL(duration time.Duration) (*url.URL, error)
+	PurgeQueues() error
+	PurgeQueuesWithContext(ctx context.Context) error
+	PurgeQueues_SignedURL(duration time.Duration) (*url.URL, error)
+	APIReference() error
+	APIReferenceWithContext(ctx context.Context) error
+	Ping() error
+	PingWithContext(ctx context.Context) error
+}
+
+var _ API = (*EC2Manager)(nil)
+
 // Stability: *** EXPERIMENTAL ***
 //
 // This method is only for debugging the ec2-manager
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#listWorkerTypes
 func (eC2Manager *EC2Manager) ListWorkerTypes() (*ListOfWorkerTypes, error) {
+	return eC2Manager.ListWorkerTypesWithContext(eC2Manager.Context)
+}
+
+// ListWorkerTypesWithContext is the same as ListWorkerTypes, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See ListWorkerTypes for more details.
+func (eC2Manager *EC2Manager) ListWorkerTypesWithContext(ctx context.Context) (*ListOfWorkerTypes, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-types", new(ListOfWorkerTypes), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "listWorkerTypes"), nil, "GET", "/worker-types", new(ListOfWorkerTypes), nil)
 	return responseObject.(*ListOfWorkerTypes), err
 }
 
@@ -104,8 +195,16 @@ func (eC2Manager *EC2Manager) ListWorkerTypes() (*ListOfWorkerTypes, error) {
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#runInstance
 func (eC2Manager *EC2Manager) RunInstance(workerType string, payload *MakeASpotRequest) error {
+	return eC2Manager.RunInstanceWithContext(eC2Manager.Context, workerType, payload)
+}
+
+// RunInstanceWithContext is the same as RunInstance, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See RunInstance for more details.
+func (eC2Manager *EC2Manager) RunInstanceWithContext(ctx context.Context, workerType string, payload *MakeASpotRequest) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(payload, "PUT", "/worker-types/"+url.QueryEscape(workerType)+"/instance", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "runInstance"), payload, "PUT", "/worker-types/"+url.QueryEscape(workerType)+"/instance", nil, nil)
 	return err
 }
 
@@ -118,8 +217,16 @@ func (eC2Manager *EC2Manager) RunInstance(workerType string, payload *MakeASpotR
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#terminateWorkerType
 func (eC2Manager *EC2Manager) TerminateWorkerType(workerType string) error {
+	return eC2Manager.TerminateWorkerTypeWithContext(eC2Manager.Context, workerType)
+}
+
+// TerminateWorkerTypeWithContext is the same as TerminateWorkerType, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See TerminateWorkerType for more details.
+func (eC2Manager *EC2Manager) TerminateWorkerTypeWithContext(ctx context.Context, workerType string) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/worker-types/"+url.QueryEscape(workerType)+"/resources", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "terminateWorkerType"), nil, "DELETE", "/worker-types/"+url.QueryEscape(workerType)+"/resources", nil, nil)
 	return err
 }
 
@@ -129,8 +236,16 @@ func (eC2Manager *EC2Manager) TerminateWorkerType(workerType string) error {
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#workerTypeStats
 func (eC2Manager *EC2Manager) WorkerTypeStats(workerType string) (*OverviewOfComputationalResources, error) {
+	return eC2Manager.WorkerTypeStatsWithContext(eC2Manager.Context, workerType)
+}
+
+// WorkerTypeStatsWithContext is the same as WorkerTypeStats, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See WorkerTypeStats for more details.
+func (eC2Manager *EC2Manager) WorkerTypeStatsWithContext(ctx context.Context, workerType string) (*OverviewOfComputationalResources, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/stats", new(OverviewOfComputationalResources), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "workerTypeStats"), nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/stats", new(OverviewOfComputationalResources), nil)
 	return responseObject.(*OverviewOfComputationalResources), err
 }
 
@@ -140,8 +255,16 @@ func (eC2Manager *EC2Manager) WorkerTypeStats(workerType string) (*OverviewOfCom
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#workerTypeHealth
 func (eC2Manager *EC2Manager) WorkerTypeHealth(workerType string) (*HealthOfTheEC2Account, error) {
+	return eC2Manager.WorkerTypeHealthWithContext(eC2Manager.Context, workerType)
+}
+
+// WorkerTypeHealthWithContext is the same as WorkerTypeHealth, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See WorkerTypeHealth for more details.
+func (eC2Manager *EC2Manager) WorkerTypeHealthWithContext(ctx context.Context, workerType string) (*HealthOfTheEC2Account, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/health", new(HealthOfTheEC2Account), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "workerTypeHealth"), nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/health", new(HealthOfTheEC2Account), nil)
 	return responseObject.(*HealthOfTheEC2Account), err
 }
 
@@ -151,8 +274,16 @@ func (eC2Manager *EC2Manager) WorkerTypeHealth(workerType string) (*HealthOfTheE
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#workerTypeErrors
 func (eC2Manager *EC2Manager) WorkerTypeErrors(workerType string) (*Errors, error) {
+	return eC2Manager.WorkerTypeErrorsWithContext(eC2Manager.Context, workerType)
+}
+
+// WorkerTypeErrorsWithContext is the same as WorkerTypeErrors, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See WorkerTypeErrors for more details.
+func (eC2Manager *EC2Manager) WorkerTypeErrorsWithContext(ctx context.Context, workerType string) (*Errors, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/errors", new(Errors), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "workerTypeErrors"), nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/errors", new(Errors), nil)
 	return responseObject.(*Errors), err
 }
 
@@ -162,8 +293,16 @@ func (eC2Manager *EC2Manager) WorkerTypeErrors(workerType string) (*Errors, erro
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#workerTypeState
 func (eC2Manager *EC2Manager) WorkerTypeState(workerType string) (*OverviewOfComputationalResources1, error) {
+	return eC2Manager.WorkerTypeStateWithContext(eC2Manager.Context, workerType)
+}
+
+// WorkerTypeStateWithContext is the same as WorkerTypeState, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See WorkerTypeState for more details.
+func (eC2Manager *EC2Manager) WorkerTypeStateWithContext(ctx context.Context, workerType string) (*OverviewOfComputationalResources1, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/state", new(OverviewOfComputationalResources1), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "workerTypeState"), nil, "GET", "/worker-types/"+url.QueryEscape(workerType)+"/state", new(OverviewOfComputationalResources1), nil)
 	return responseObject.(*OverviewOfComputationalResources1), err
 }
 
@@ -176,8 +315,16 @@ func (eC2Manager *EC2Manager) WorkerTypeState(workerType string) (*OverviewOfCom
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#ensureKeyPair
 func (eC2Manager *EC2Manager) EnsureKeyPair(name string, payload *SSHPublicKey) error {
+	return eC2Manager.EnsureKeyPairWithContext(eC2Manager.Context, name, payload)
+}
+
+// EnsureKeyPairWithContext is the same as EnsureKeyPair, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See EnsureKeyPair for more details.
+func (eC2Manager *EC2Manager) EnsureKeyPairWithContext(ctx context.Context, name string, payload *SSHPublicKey) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(payload, "GET", "/key-pairs/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "ensureKeyPair"), payload, "GET", "/key-pairs/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -201,8 +348,16 @@ func (eC2Manager *EC2Manager) EnsureKeyPair_SignedURL(name string, duration time
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#removeKeyPair
 func (eC2Manager *EC2Manager) RemoveKeyPair(name string) error {
+	return eC2Manager.RemoveKeyPairWithContext(eC2Manager.Context, name)
+}
+
+// RemoveKeyPairWithContext is the same as RemoveKeyPair, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See RemoveKeyPair for more details.
+func (eC2Manager *EC2Manager) RemoveKeyPairWithContext(ctx context.Context, name string) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/key-pairs/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "removeKeyPair"), nil, "DELETE", "/key-pairs/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -217,8 +372,16 @@ func (eC2Manager *EC2Manager) RemoveKeyPair(name string) error {
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#terminateInstance
 func (eC2Manager *EC2Manager) TerminateInstance(region, instanceId string) error {
+	return eC2Manager.TerminateInstanceWithContext(eC2Manager.Context, region, instanceId)
+}
+
+// TerminateInstanceWithContext is the same as TerminateInstance, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See TerminateInstance for more details.
+func (eC2Manager *EC2Manager) TerminateInstanceWithContext(ctx context.Context, region, instanceId string) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/region/"+url.QueryEscape(region)+"/instance/"+url.QueryEscape(instanceId), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "terminateInstance"), nil, "DELETE", "/region/"+url.QueryEscape(region)+"/instance/"+url.QueryEscape(instanceId), nil, nil)
 	return err
 }
 
@@ -228,8 +391,16 @@ func (eC2Manager *EC2Manager) TerminateInstance(region, instanceId string) error
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#getPrices
 func (eC2Manager *EC2Manager) GetPrices() (*ListOfPrices, error) {
+	return eC2Manager.GetPricesWithContext(eC2Manager.Context)
+}
+
+// GetPricesWithContext is the same as GetPrices, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See GetPrices for more details.
+func (eC2Manager *EC2Manager) GetPricesWithContext(ctx context.Context) (*ListOfPrices, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/prices", new(ListOfPrices), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "getPrices"), nil, "GET", "/prices", new(ListOfPrices), nil)
 	return responseObject.(*ListOfPrices), err
 }
 
@@ -239,8 +410,16 @@ func (eC2Manager *EC2Manager) GetPrices() (*ListOfPrices, error) {
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#getSpecificPrices
 func (eC2Manager *EC2Manager) GetSpecificPrices(payload *ListOfRestrictionsForPrices) (*ListOfPrices, error) {
+	return eC2Manager.GetSpecificPricesWithContext(eC2Manager.Context, payload)
+}
+
+// GetSpecificPricesWithContext is the same as GetSpecificPrices, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See GetSpecificPrices for more details.
+func (eC2Manager *EC2Manager) GetSpecificPricesWithContext(ctx context.Context, payload *ListOfRestrictionsForPrices) (*ListOfPrices, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/prices", new(ListOfPrices), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "getSpecificPrices"), payload, "POST", "/prices", new(ListOfPrices), nil)
 	return responseObject.(*ListOfPrices), err
 }
 
@@ -250,8 +429,16 @@ func (eC2Manager *EC2Manager) GetSpecificPrices(payload *ListOfRestrictionsForPr
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#getHealth
 func (eC2Manager *EC2Manager) GetHealth() (*HealthOfTheEC2Account, error) {
+	return eC2Manager.GetHealthWithContext(eC2Manager.Context)
+}
+
+// GetHealthWithContext is the same as GetHealth, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See GetHealth for more details.
+func (eC2Manager *EC2Manager) GetHealthWithContext(ctx context.Context) (*HealthOfTheEC2Account, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/health", new(HealthOfTheEC2Account), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "getHealth"), nil, "GET", "/health", new(HealthOfTheEC2Account), nil)
 	return responseObject.(*HealthOfTheEC2Account), err
 }
 
@@ -261,8 +448,16 @@ func (eC2Manager *EC2Manager) GetHealth() (*HealthOfTheEC2Account, error) {
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#getRecentErrors
 func (eC2Manager *EC2Manager) GetRecentErrors() (*Errors, error) {
+	return eC2Manager.GetRecentErrorsWithContext(eC2Manager.Context)
+}
+
+// GetRecentErrorsWithContext is the same as GetRecentErrors, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See GetRecentErrors for more details.
+func (eC2Manager *EC2Manager) GetRecentErrorsWithContext(ctx context.Context) (*Errors, error) {
 	cd := tcclient.Client(*eC2Manager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/errors", new(Errors), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "getRecentErrors"), nil, "GET", "/errors", new(Errors), nil)
 	return responseObject.(*Errors), err
 }
 
@@ -275,8 +470,16 @@ func (eC2Manager *EC2Manager) GetRecentErrors() (*Errors, error) {
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#regions
 func (eC2Manager *EC2Manager) Regions() error {
+	return eC2Manager.RegionsWithContext(eC2Manager.Context)
+}
+
+// RegionsWithContext is the same as Regions, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See Regions for more details.
+func (eC2Manager *EC2Manager) RegionsWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/regions", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "regions"), nil, "GET", "/internal/regions", nil, nil)
 	return err
 }
 
@@ -305,8 +508,16 @@ func (eC2Manager *EC2Manager) Regions_SignedURL(duration time.Duration) (*url.UR
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#amiUsage
 func (eC2Manager *EC2Manager) AmiUsage() error {
+	return eC2Manager.AmiUsageWithContext(eC2Manager.Context)
+}
+
+// AmiUsageWithContext is the same as AmiUsage, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See AmiUsage for more details.
+func (eC2Manager *EC2Manager) AmiUsageWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/ami-usage", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "amiUsage"), nil, "GET", "/internal/ami-usage", nil, nil)
 	return err
 }
 
@@ -339,8 +550,16 @@ func (eC2Manager *EC2Manager) AmiUsage_SignedURL(duration time.Duration) (*url.U
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#ebsUsage
 func (eC2Manager *EC2Manager) EbsUsage() error {
+	return eC2Manager.EbsUsageWithContext(eC2Manager.Context)
+}
+
+// EbsUsageWithContext is the same as EbsUsage, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See EbsUsage for more details.
+func (eC2Manager *EC2Manager) EbsUsageWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/ebs-usage", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "ebsUsage"), nil, "GET", "/internal/ebs-usage", nil, nil)
 	return err
 }
 
@@ -364,8 +583,16 @@ func (eC2Manager *EC2Manager) EbsUsage_SignedURL(duration time.Duration) (*url.U
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#dbpoolStats
 func (eC2Manager *EC2Manager) DbpoolStats() error {
+	return eC2Manager.DbpoolStatsWithContext(eC2Manager.Context)
+}
+
+// DbpoolStatsWithContext is the same as DbpoolStats, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See DbpoolStats for more details.
+func (eC2Manager *EC2Manager) DbpoolStatsWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/db-pool-stats", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "dbpoolStats"), nil, "GET", "/internal/db-pool-stats", nil, nil)
 	return err
 }
 
@@ -389,8 +616,16 @@ func (eC2Manager *EC2Manager) DbpoolStats_SignedURL(duration time.Duration) (*ur
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#allState
 func (eC2Manager *EC2Manager) AllState() error {
+	return eC2Manager.AllStateWithContext(eC2Manager.Context)
+}
+
+// AllStateWithContext is the same as AllState, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See AllState for more details.
+func (eC2Manager *EC2Manager) AllStateWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/all-state", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "allState"), nil, "GET", "/internal/all-state", nil, nil)
 	return err
 }
 
@@ -414,8 +649,16 @@ func (eC2Manager *EC2Manager) AllState_SignedURL(duration time.Duration) (*url.U
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#sqsStats
 func (eC2Manager *EC2Manager) SqsStats() error {
+	return eC2Manager.SqsStatsWithContext(eC2Manager.Context)
+}
+
+// SqsStatsWithContext is the same as SqsStats, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See SqsStats for more details.
+func (eC2Manager *EC2Manager) SqsStatsWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/sqs-stats", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "sqsStats"), nil, "GET", "/internal/sqs-stats", nil, nil)
 	return err
 }
 
@@ -439,8 +682,16 @@ func (eC2Manager *EC2Manager) SqsStats_SignedURL(duration time.Duration) (*url.U
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#purgeQueues
 func (eC2Manager *EC2Manager) PurgeQueues() error {
+	return eC2Manager.PurgeQueuesWithContext(eC2Manager.Context)
+}
+
+// PurgeQueuesWithContext is the same as PurgeQueues, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See PurgeQueues for more details.
+func (eC2Manager *EC2Manager) PurgeQueuesWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/purge-queues", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "purgeQueues"), nil, "GET", "/internal/purge-queues", nil, nil)
 	return err
 }
 
@@ -461,8 +712,16 @@ func (eC2Manager *EC2Manager) PurgeQueues_SignedURL(duration time.Duration) (*ur
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#apiReference
 func (eC2Manager *EC2Manager) APIReference() error {
+	return eC2Manager.APIReferenceWithContext(eC2Manager.Context)
+}
+
+// APIReferenceWithContext is the same as APIReference, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See APIReference for more details.
+func (eC2Manager *EC2Manager) APIReferenceWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/internal/api-reference", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "apiReference"), nil, "GET", "/internal/api-reference", nil, nil)
 	return err
 }
 
@@ -471,7 +730,37 @@ func (eC2Manager *EC2Manager) APIReference() error {
 //
 // See https://docs.taskcluster.net/reference/core/ec2-manager/api-docs#ping
 func (eC2Manager *EC2Manager) Ping() error {
+	return eC2Manager.PingWithContext(eC2Manager.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to eC2Manager.Context.
+//
+// See Ping for more details.
+func (eC2Manager *EC2Manager) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*eC2Manager)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "ec2-manager", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"runInstance":         scopes.RequiredScope("ec2-manager:manage-resources:<workerType>"),
+	"terminateWorkerType": scopes.RequiredScope("ec2-manager:manage-resources:<workerType>"),
+	"ensureKeyPair":       scopes.RequiredScope("ec2-manager:manage-key-pairs:<name>"),
+	"removeKeyPair":       scopes.RequiredScope("ec2-manager:manage-key-pairs:<name>"),
+	"terminateInstance": scopes.AnyOf{
+		scopes.RequiredScope("ec2-manager:manage-instances:<region>:<instanceId>"),
+		scopes.RequiredScope("ec2-manager:manage-resources:<workerType>"),
+	},
+	"regions":     scopes.RequiredScope("ec2-manager:internals"),
+	"amiUsage":    scopes.RequiredScope("ec2-manager:internals"),
+	"ebsUsage":    scopes.RequiredScope("ec2-manager:internals"),
+	"dbpoolStats": scopes.RequiredScope("ec2-manager:internals"),
+	"allState":    scopes.RequiredScope("ec2-manager:internals"),
+	"sqsStats":    scopes.RequiredScope("ec2-manager:internals"),
+	"purgeQueues": scopes.RequiredScope("ec2-manager:internals"),
+}
@@ -0,0 +1,74 @@
+package tcec2manager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tcclient "github.com/taskcluster/taskcluster-client-go"
+)
+
+func TestEndpointService(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer s.Close()
+	var endpoint tcclient.Endpoint
+	eC2Manager := New(nil)
+	eC2Manager.BaseURL = s.URL
+	eC2Manager.Middleware = []tcclient.Middleware{
+		func(req *http.Request, next tcclient.RoundTripFunc) (*http.Response, error) {
+			endpoint, _ = tcclient.EndpointFromContext(req.Context())
+			return next(req)
+		},
+	}
+	for name, call := range map[string]func() error{
+		"listWorkerTypes": func() error {
+			_, err := eC2Manager.ListWorkerTypes()
+			return err
+		},
+		"terminateWorkerType": func() error {
+			return eC2Manager.TerminateWorkerType("abc")
+		},
+		"getPrices": func() error {
+			_, err := eC2Manager.GetPrices()
+			return err
+		},
+		"ping": eC2Manager.Ping,
+	} {
+		endpoint = tcclient.Endpoint{}
+		if err := call(); err != nil {
+			t.Fatalf("Unexpected error calling %v: %v", name, err)
+		}
+		if endpoint != (tcclient.Endpoint{Service: "ec2-manager", Name: name}) {
+			t.Errorf("Expected endpoint ec2-manager/%v but got %#v", name, endpoint)
+		}
+	}
+}
+
+func TestNewFromProfile(t *testing.T) {
+	for _, name := range []string{"TASKCLUSTER_CONFIG", "TASKCLUSTER_PROFILE", "TASKCLUSTER_ROOT_URL", "TASKCLUSTER_PROXY_URL", "TASKCLUSTER_CLIENT_ID"} {
+		if value, set := os.LookupEnv(name); set {
+			defer os.Setenv(name, value)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Unsetenv(name)
+	}
+	os.Setenv("TASKCLUSTER_CONFIG", filepath.Join(os.TempDir(), "nonexistent-tc-config.yml"))
+	for rootURL, expected := range map[string]string{
+		"":                       DefaultBaseURL,
+		"https://tc.example.com": "https://tc.example.com/api/ec2-manager/v1",
+	} {
+		os.Setenv("TASKCLUSTER_ROOT_URL", rootURL)
+		eC2Manager, err := NewFromProfile("")
+		if err != nil {
+			t.Fatalf("Unexpected error for root URL %q: %v", rootURL, err)
+		}
+		if eC2Manager.BaseURL != expected || eC2Manager.Authenticate {
+			t.Errorf("Expected unauthenticated client with base URL %v for root URL %q, but got %#v", expected, rootURL, eC2Manager)
+		}
+	}
+}
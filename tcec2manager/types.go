@@ -466,7 +466,7 @@ type (
 		Region string `json:"region,omitempty"`
 
 		// See http://schemas.taskcluster.net/ec2-manager/v1/errors.json#/properties/errors/items/properties/time
-		Time tcclient.Time `json:"time,omitempty"`
+		Time *tcclient.Time `json:"time,omitempty"`
 
 		// Possible values:
 		//   * "instance-request"
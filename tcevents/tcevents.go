@@ -39,9 +39,12 @@
 package tcevents
 
 import (
+	"context"
+	"errors"
 	"net/url"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Events tcclient.Client
@@ -89,13 +92,51 @@ func NewFromEnv() *Events {
 	}
 }
 
+// NewFromProfile returns an *Events configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Events, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcevents.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Events, comprising all of its
+// API methods. Code that depends on API rather than *Events can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	Connect(bindings string) error
+	ConnectWithContext(ctx context.Context, bindings string) error
+}
+
+var _ API = (*Events)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (events *Events) Ping() error {
+	return events.PingWithContext(events.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to events.Context.
+//
+// See Ping for more details.
+func (events *Events) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*events)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "events", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -105,11 +146,25 @@ func (events *Events) Ping() error {
 //
 // See #connect
 func (events *Events) Connect(bindings string) error {
+	return events.ConnectWithContext(events.Context, bindings)
+}
+
+// ConnectWithContext is the same as Connect, except that the
+// request is bound to ctx rather than to events.Context.
+//
+// See Connect for more details.
+func (events *Events) ConnectWithContext(ctx context.Context, bindings string) error {
 	v := url.Values{}
 	if bindings != "" {
 		v.Add("bindings", bindings)
 	}
 	cd := tcclient.Client(*events)
-	_, _, err := (&cd).APICall(nil, "GET", "/connect/", nil, v)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "events", "connect"), nil, "GET", "/connect/", nil, v)
 	return err
 }
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{}
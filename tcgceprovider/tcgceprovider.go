@@ -37,7 +37,10 @@
 package tcgceprovider
 
 import (
+	"context"
+	"errors"
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type GceProvider tcclient.Client
@@ -85,13 +88,51 @@ func NewFromEnv() *GceProvider {
 	}
 }
 
+// NewFromProfile returns a *GceProvider configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*GceProvider, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcgceprovider.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *GceProvider, comprising all of its
+// API methods. Code that depends on API rather than *GceProvider can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	GetCredentials() error
+	GetCredentialsWithContext(ctx context.Context) error
+}
+
+var _ API = (*GceProvider)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (gceProvider *GceProvider) Ping() error {
+	return gceProvider.PingWithContext(gceProvider.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to gceProvider.Context.
+//
+// See Ping for more details.
+func (gceProvider *GceProvider) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*gceProvider)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "gce-provider", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -101,7 +142,21 @@ func (gceProvider *GceProvider) Ping() error {
 //
 // See #getCredentials
 func (gceProvider *GceProvider) GetCredentials() error {
+	return gceProvider.GetCredentialsWithContext(gceProvider.Context)
+}
+
+// GetCredentialsWithContext is the same as GetCredentials, except that the
+// request is bound to ctx rather than to gceProvider.Context.
+//
+// See GetCredentials for more details.
+func (gceProvider *GceProvider) GetCredentialsWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*gceProvider)
-	_, _, err := (&cd).APICall(nil, "POST", "/credentials", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "gce-provider", "getCredentials"), nil, "POST", "/credentials", nil, nil)
 	return err
 }
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{}
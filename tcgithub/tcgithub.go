@@ -44,9 +44,12 @@
 package tcgithub
 
 import (
+	"context"
+	"errors"
 	"net/url"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Github tcclient.Client
@@ -94,13 +97,64 @@ func NewFromEnv() *Github {
 	}
 }
 
+// NewFromProfile returns a *Github configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Github, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcgithub.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Github, comprising all of its
+// API methods. Code that depends on API rather than *Github can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	GithubWebHookConsumer() error
+	GithubWebHookConsumerWithContext(ctx context.Context) error
+	Builds(continuationToken, limit, organization, repository, sha string) (*BuildsResponse, error)
+	BuildsWithContext(ctx context.Context, continuationToken, limit, organization, repository, sha string) (*BuildsResponse, error)
+	BuildsAll(ctx context.Context, organization, repository, sha string) *BuildIterator
+	Badge(owner, repo, branch string) error
+	BadgeWithContext(ctx context.Context, owner, repo, branch string) error
+	Repository(owner, repo string) (*RepositoryResponse, error)
+	RepositoryWithContext(ctx context.Context, owner, repo string) (*RepositoryResponse, error)
+	Latest(owner, repo, branch string) error
+	LatestWithContext(ctx context.Context, owner, repo, branch string) error
+	CreateStatus(owner, repo, sha string, payload *CreateStatusRequest) error
+	CreateStatusWithContext(ctx context.Context, owner, repo, sha string, payload *CreateStatusRequest) error
+	CreateComment(owner, repo, number string, payload *CreateCommentRequest) error
+	CreateCommentWithContext(ctx context.Context, owner, repo, number string, payload *CreateCommentRequest) error
+}
+
+var _ API = (*Github)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (github *Github) Ping() error {
+	return github.PingWithContext(github.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See Ping for more details.
+func (github *Github) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*github)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -111,8 +165,16 @@ func (github *Github) Ping() error {
 //
 // See #githubWebHookConsumer
 func (github *Github) GithubWebHookConsumer() error {
+	return github.GithubWebHookConsumerWithContext(github.Context)
+}
+
+// GithubWebHookConsumerWithContext is the same as GithubWebHookConsumer, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See GithubWebHookConsumer for more details.
+func (github *Github) GithubWebHookConsumerWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*github)
-	_, _, err := (&cd).APICall(nil, "POST", "/github", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "githubWebHookConsumer"), nil, "POST", "/github", nil, nil)
 	return err
 }
 
@@ -124,6 +186,14 @@ func (github *Github) GithubWebHookConsumer() error {
 //
 // See #builds
 func (github *Github) Builds(continuationToken, limit, organization, repository, sha string) (*BuildsResponse, error) {
+	return github.BuildsWithContext(github.Context, continuationToken, limit, organization, repository, sha)
+}
+
+// BuildsWithContext is the same as Builds, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See Builds for more details.
+func (github *Github) BuildsWithContext(ctx context.Context, continuationToken, limit, organization, repository, sha string) (*BuildsResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -141,10 +211,27 @@ func (github *Github) Builds(continuationToken, limit, organization, repository,
 		v.Add("sha", sha)
 	}
 	cd := tcclient.Client(*github)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/builds", new(BuildsResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "builds"), nil, "GET", "/builds", new(BuildsResponse), v)
 	return responseObject.(*BuildsResponse), err
 }
 
+// BuildsAll returns an iterator over the Build items of all
+// pages of Builds results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See Builds for more details.
+func (github *Github) BuildsAll(ctx context.Context, organization, repository, sha string) *BuildIterator {
+	return NewBuildIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Build, string, error) {
+		page, err := github.BuildsWithContext(ctx, continuationToken, limit, organization, repository, sha)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Builds, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Checks the status of the latest build of a given branch
@@ -152,8 +239,16 @@ func (github *Github) Builds(continuationToken, limit, organization, repository,
 //
 // See #badge
 func (github *Github) Badge(owner, repo, branch string) error {
+	return github.BadgeWithContext(github.Context, owner, repo, branch)
+}
+
+// BadgeWithContext is the same as Badge, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See Badge for more details.
+func (github *Github) BadgeWithContext(ctx context.Context, owner, repo, branch string) error {
 	cd := tcclient.Client(*github)
-	_, _, err := (&cd).APICall(nil, "GET", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/"+url.QueryEscape(branch)+"/badge.svg", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "badge"), nil, "GET", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/"+url.QueryEscape(branch)+"/badge.svg", nil, nil)
 	return err
 }
 
@@ -164,8 +259,16 @@ func (github *Github) Badge(owner, repo, branch string) error {
 //
 // See #repository
 func (github *Github) Repository(owner, repo string) (*RepositoryResponse, error) {
+	return github.RepositoryWithContext(github.Context, owner, repo)
+}
+
+// RepositoryWithContext is the same as Repository, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See Repository for more details.
+func (github *Github) RepositoryWithContext(ctx context.Context, owner, repo string) (*RepositoryResponse, error) {
 	cd := tcclient.Client(*github)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo), new(RepositoryResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "repository"), nil, "GET", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo), new(RepositoryResponse), nil)
 	return responseObject.(*RepositoryResponse), err
 }
 
@@ -179,8 +282,16 @@ func (github *Github) Repository(owner, repo string) (*RepositoryResponse, error
 //
 // See #latest
 func (github *Github) Latest(owner, repo, branch string) error {
+	return github.LatestWithContext(github.Context, owner, repo, branch)
+}
+
+// LatestWithContext is the same as Latest, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See Latest for more details.
+func (github *Github) LatestWithContext(ctx context.Context, owner, repo, branch string) error {
 	cd := tcclient.Client(*github)
-	_, _, err := (&cd).APICall(nil, "GET", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/"+url.QueryEscape(branch)+"/latest", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "latest"), nil, "GET", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/"+url.QueryEscape(branch)+"/latest", nil, nil)
 	return err
 }
 
@@ -196,8 +307,16 @@ func (github *Github) Latest(owner, repo, branch string) error {
 //
 // See #createStatus
 func (github *Github) CreateStatus(owner, repo, sha string, payload *CreateStatusRequest) error {
+	return github.CreateStatusWithContext(github.Context, owner, repo, sha, payload)
+}
+
+// CreateStatusWithContext is the same as CreateStatus, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See CreateStatus for more details.
+func (github *Github) CreateStatusWithContext(ctx context.Context, owner, repo, sha string, payload *CreateStatusRequest) error {
 	cd := tcclient.Client(*github)
-	_, _, err := (&cd).APICall(payload, "POST", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/statuses/"+url.QueryEscape(sha), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "createStatus"), payload, "POST", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/statuses/"+url.QueryEscape(sha), nil, nil)
 	return err
 }
 
@@ -210,7 +329,56 @@ func (github *Github) CreateStatus(owner, repo, sha string, payload *CreateStatu
 //
 // See #createComment
 func (github *Github) CreateComment(owner, repo, number string, payload *CreateCommentRequest) error {
+	return github.CreateCommentWithContext(github.Context, owner, repo, number, payload)
+}
+
+// CreateCommentWithContext is the same as CreateComment, except that the
+// request is bound to ctx rather than to github.Context.
+//
+// See CreateComment for more details.
+func (github *Github) CreateCommentWithContext(ctx context.Context, owner, repo, number string, payload *CreateCommentRequest) error {
 	cd := tcclient.Client(*github)
-	_, _, err := (&cd).APICall(payload, "POST", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/issues/"+url.QueryEscape(number)+"/comments", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "github", "createComment"), payload, "POST", "/repository/"+url.QueryEscape(owner)+"/"+url.QueryEscape(repo)+"/issues/"+url.QueryEscape(number)+"/comments", nil, nil)
 	return err
 }
+
+// BuildIterator iterates over the Build items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type BuildIterator struct {
+	*tcclient.Pager
+	items []Build
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *BuildIterator) Item() Build {
+	return it.items[it.Index()]
+}
+
+// NewBuildIterator returns a BuildIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewBuildIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]Build, string, error)) *BuildIterator {
+	it := new(BuildIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"createStatus":  scopes.RequiredScope("github:create-status:<owner>/<repo>"),
+	"createComment": scopes.RequiredScope("github:create-comment:<owner>/<repo>"),
+}
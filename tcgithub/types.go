@@ -3,6 +3,9 @@
 package tcgithub
 
 import (
+	"encoding/json"
+	"fmt"
+
 	tcclient "github.com/taskcluster/taskcluster-client-go"
 )
 
@@ -64,7 +67,7 @@ type (
 		//   * "failure"
 		//
 		// See https://taskcluster-staging.net/schemas/github/v1/build-list.json#/properties/builds/items/properties/state
-		State string `json:"state"`
+		State State `json:"state"`
 
 		// Taskcluster task-group associated with the build.
 		//
@@ -132,7 +135,7 @@ type (
 		//   * "failure"
 		//
 		// See https://taskcluster-staging.net/schemas/github/v1/create-status.json#/properties/state
-		State string `json:"state"`
+		State State `json:"state"`
 
 		// The target URL to associate with this status. This URL will be linked from the GitHub UI to allow users to easily see the 'source' of the Status.
 		//
@@ -166,3 +169,34 @@ type (
 	// See https://taskcluster-staging.net/schemas/github/v1/build-list.json#/properties/builds/items/properties/eventId/oneOf[1]
 	UnknownGithubGUID string
 )
+
+// State is the type of the `state` property of Build and CreateStatusRequest.
+// Its possible values are declared as constants.
+type State string
+
+// Possible values of State
+const (
+	StatePending State = "pending"
+	StateSuccess State = "success"
+	StateError   State = "error"
+	StateFailure State = "failure"
+)
+
+// Valid returns true if this is one of the possible values of State.
+func (this State) Valid() bool {
+	switch this {
+	case StatePending, StateSuccess, StateError, StateFailure:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid State.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this State) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid State %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
@@ -28,7 +28,7 @@ type (
 		//   * "review_request_removed"
 		//
 		// See https://taskcluster-staging.net/schemas/github/v1/github-pull-request-message.json#/properties/action
-		Action string `json:"action"`
+		Action Action `json:"action"`
 
 		// The raw body of github event (for version 1)
 		//
@@ -277,3 +277,31 @@ type (
 		Version float64 `json:"version"`
 	}
 )
+
+// Action is the type of the `action` property of GitHubPullRequestMessage.
+// Its possible values are declared as constants.
+type Action string
+
+// Possible values of Action
+const (
+	ActionAssigned             Action = "assigned"
+	ActionUnassigned           Action = "unassigned"
+	ActionLabeled              Action = "labeled"
+	ActionUnlabeled            Action = "unlabeled"
+	ActionOpened               Action = "opened"
+	ActionEdited               Action = "edited"
+	ActionClosed               Action = "closed"
+	ActionReopened             Action = "reopened"
+	ActionSynchronize          Action = "synchronize"
+	ActionReviewRequested      Action = "review_requested"
+	ActionReviewRequestRemoved Action = "review_request_removed"
+)
+
+// Valid returns true if this is one of the possible values of Action.
+func (this Action) Valid() bool {
+	switch this {
+	case ActionAssigned, ActionUnassigned, ActionLabeled, ActionUnlabeled, ActionOpened, ActionEdited, ActionClosed, ActionReopened, ActionSynchronize, ActionReviewRequested, ActionReviewRequestRemoved:
+		return true
+	}
+	return false
+}
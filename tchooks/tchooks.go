@@ -37,10 +37,13 @@
 package tchooks
 
 import (
+	"context"
+	"errors"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Hooks tcclient.Client
@@ -88,13 +91,74 @@ func NewFromEnv() *Hooks {
 	}
 }
 
+// NewFromProfile returns a *Hooks configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Hooks, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tchooks.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Hooks, comprising all of its
+// API methods. Code that depends on API rather than *Hooks can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	ListHookGroups() (*HookGroups, error)
+	ListHookGroupsWithContext(ctx context.Context) (*HookGroups, error)
+	ListHooks(hookGroupId string) (*HookList, error)
+	ListHooksWithContext(ctx context.Context, hookGroupId string) (*HookList, error)
+	Hook(hookGroupId, hookId string) (*HookDefinition, error)
+	HookWithContext(ctx context.Context, hookGroupId, hookId string) (*HookDefinition, error)
+	GetHookStatus(hookGroupId, hookId string) (*HookStatusResponse, error)
+	GetHookStatusWithContext(ctx context.Context, hookGroupId, hookId string) (*HookStatusResponse, error)
+	CreateHook(hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error)
+	CreateHookWithContext(ctx context.Context, hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error)
+	UpdateHook(hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error)
+	UpdateHookWithContext(ctx context.Context, hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error)
+	RemoveHook(hookGroupId, hookId string) error
+	RemoveHookWithContext(ctx context.Context, hookGroupId, hookId string) error
+	TriggerHook(hookGroupId, hookId string, payload *TriggerHookRequest) (*TriggerHookResponse, error)
+	TriggerHookWithContext(ctx context.Context, hookGroupId, hookId string, payload *TriggerHookRequest) (*TriggerHookResponse, error)
+	GetTriggerToken(hookGroupId, hookId string) (*TriggerTokenResponse, error)
+	GetTriggerTokenWithContext(ctx context.Context, hookGroupId, hookId string) (*TriggerTokenResponse, error)
+	GetTriggerToken_SignedURL(hookGroupId, hookId string, duration time.Duration) (*url.URL, error)
+	ResetTriggerToken(hookGroupId, hookId string) (*TriggerTokenResponse, error)
+	ResetTriggerTokenWithContext(ctx context.Context, hookGroupId, hookId string) (*TriggerTokenResponse, error)
+	TriggerHookWithToken(hookGroupId, hookId, token string, payload *TriggerHookRequest) (*TriggerHookResponse, error)
+	TriggerHookWithTokenWithContext(ctx context.Context, hookGroupId, hookId, token string, payload *TriggerHookRequest) (*TriggerHookResponse, error)
+	ListLastFires(hookGroupId, hookId string) (*LastFiresList, error)
+	ListLastFiresWithContext(ctx context.Context, hookGroupId, hookId string) (*LastFiresList, error)
+}
+
+var _ API = (*Hooks)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (hooks *Hooks) Ping() error {
+	return hooks.PingWithContext(hooks.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See Ping for more details.
+func (hooks *Hooks) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*hooks)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -102,8 +166,16 @@ func (hooks *Hooks) Ping() error {
 //
 // See #listHookGroups
 func (hooks *Hooks) ListHookGroups() (*HookGroups, error) {
+	return hooks.ListHookGroupsWithContext(hooks.Context)
+}
+
+// ListHookGroupsWithContext is the same as ListHookGroups, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See ListHookGroups for more details.
+func (hooks *Hooks) ListHookGroupsWithContext(ctx context.Context) (*HookGroups, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/hooks", new(HookGroups), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "listHookGroups"), nil, "GET", "/hooks", new(HookGroups), nil)
 	return responseObject.(*HookGroups), err
 }
 
@@ -112,8 +184,16 @@ func (hooks *Hooks) ListHookGroups() (*HookGroups, error) {
 //
 // See #listHooks
 func (hooks *Hooks) ListHooks(hookGroupId string) (*HookList, error) {
+	return hooks.ListHooksWithContext(hooks.Context, hookGroupId)
+}
+
+// ListHooksWithContext is the same as ListHooks, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See ListHooks for more details.
+func (hooks *Hooks) ListHooksWithContext(ctx context.Context, hookGroupId string) (*HookList, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId), new(HookList), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "listHooks"), nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId), new(HookList), nil)
 	return responseObject.(*HookList), err
 }
 
@@ -122,8 +202,16 @@ func (hooks *Hooks) ListHooks(hookGroupId string) (*HookList, error) {
 //
 // See #hook
 func (hooks *Hooks) Hook(hookGroupId, hookId string) (*HookDefinition, error) {
+	return hooks.HookWithContext(hooks.Context, hookGroupId, hookId)
+}
+
+// HookWithContext is the same as Hook, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See Hook for more details.
+func (hooks *Hooks) HookWithContext(ctx context.Context, hookGroupId, hookId string) (*HookDefinition, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), new(HookDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "hook"), nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), new(HookDefinition), nil)
 	return responseObject.(*HookDefinition), err
 }
 
@@ -136,8 +224,16 @@ func (hooks *Hooks) Hook(hookGroupId, hookId string) (*HookDefinition, error) {
 //
 // See #getHookStatus
 func (hooks *Hooks) GetHookStatus(hookGroupId, hookId string) (*HookStatusResponse, error) {
+	return hooks.GetHookStatusWithContext(hooks.Context, hookGroupId, hookId)
+}
+
+// GetHookStatusWithContext is the same as GetHookStatus, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See GetHookStatus for more details.
+func (hooks *Hooks) GetHookStatusWithContext(ctx context.Context, hookGroupId, hookId string) (*HookStatusResponse, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/status", new(HookStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "getHookStatus"), nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/status", new(HookStatusResponse), nil)
 	return responseObject.(*HookStatusResponse), err
 }
 
@@ -154,8 +250,16 @@ func (hooks *Hooks) GetHookStatus(hookGroupId, hookId string) (*HookStatusRespon
 //
 // See #createHook
 func (hooks *Hooks) CreateHook(hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error) {
+	return hooks.CreateHookWithContext(hooks.Context, hookGroupId, hookId, payload)
+}
+
+// CreateHookWithContext is the same as CreateHook, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See CreateHook for more details.
+func (hooks *Hooks) CreateHookWithContext(ctx context.Context, hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), new(HookDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "createHook"), payload, "PUT", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), new(HookDefinition), nil)
 	return responseObject.(*HookDefinition), err
 }
 
@@ -169,8 +273,16 @@ func (hooks *Hooks) CreateHook(hookGroupId, hookId string, payload *HookCreation
 //
 // See #updateHook
 func (hooks *Hooks) UpdateHook(hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error) {
+	return hooks.UpdateHookWithContext(hooks.Context, hookGroupId, hookId, payload)
+}
+
+// UpdateHookWithContext is the same as UpdateHook, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See UpdateHook for more details.
+func (hooks *Hooks) UpdateHookWithContext(ctx context.Context, hookGroupId, hookId string, payload *HookCreationRequest) (*HookDefinition, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), new(HookDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "updateHook"), payload, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), new(HookDefinition), nil)
 	return responseObject.(*HookDefinition), err
 }
 
@@ -181,8 +293,16 @@ func (hooks *Hooks) UpdateHook(hookGroupId, hookId string, payload *HookCreation
 //
 // See #removeHook
 func (hooks *Hooks) RemoveHook(hookGroupId, hookId string) error {
+	return hooks.RemoveHookWithContext(hooks.Context, hookGroupId, hookId)
+}
+
+// RemoveHookWithContext is the same as RemoveHook, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See RemoveHook for more details.
+func (hooks *Hooks) RemoveHookWithContext(ctx context.Context, hookGroupId, hookId string) error {
 	cd := tcclient.Client(*hooks)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "removeHook"), nil, "DELETE", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId), nil, nil)
 	return err
 }
 
@@ -197,8 +317,16 @@ func (hooks *Hooks) RemoveHook(hookGroupId, hookId string) error {
 //
 // See #triggerHook
 func (hooks *Hooks) TriggerHook(hookGroupId, hookId string, payload *TriggerHookRequest) (*TriggerHookResponse, error) {
+	return hooks.TriggerHookWithContext(hooks.Context, hookGroupId, hookId, payload)
+}
+
+// TriggerHookWithContext is the same as TriggerHook, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See TriggerHook for more details.
+func (hooks *Hooks) TriggerHookWithContext(ctx context.Context, hookGroupId, hookId string, payload *TriggerHookRequest) (*TriggerHookResponse, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/trigger", new(TriggerHookResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "triggerHook"), payload, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/trigger", new(TriggerHookResponse), nil)
 	return responseObject.(*TriggerHookResponse), err
 }
 
@@ -210,8 +338,16 @@ func (hooks *Hooks) TriggerHook(hookGroupId, hookId string, payload *TriggerHook
 //
 // See #getTriggerToken
 func (hooks *Hooks) GetTriggerToken(hookGroupId, hookId string) (*TriggerTokenResponse, error) {
+	return hooks.GetTriggerTokenWithContext(hooks.Context, hookGroupId, hookId)
+}
+
+// GetTriggerTokenWithContext is the same as GetTriggerToken, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See GetTriggerToken for more details.
+func (hooks *Hooks) GetTriggerTokenWithContext(ctx context.Context, hookGroupId, hookId string) (*TriggerTokenResponse, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/token", new(TriggerTokenResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "getTriggerToken"), nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/token", new(TriggerTokenResponse), nil)
 	return responseObject.(*TriggerTokenResponse), err
 }
 
@@ -234,8 +370,16 @@ func (hooks *Hooks) GetTriggerToken_SignedURL(hookGroupId, hookId string, durati
 //
 // See #resetTriggerToken
 func (hooks *Hooks) ResetTriggerToken(hookGroupId, hookId string) (*TriggerTokenResponse, error) {
+	return hooks.ResetTriggerTokenWithContext(hooks.Context, hookGroupId, hookId)
+}
+
+// ResetTriggerTokenWithContext is the same as ResetTriggerToken, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See ResetTriggerToken for more details.
+func (hooks *Hooks) ResetTriggerTokenWithContext(ctx context.Context, hookGroupId, hookId string) (*TriggerTokenResponse, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/token", new(TriggerTokenResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "resetTriggerToken"), nil, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/token", new(TriggerTokenResponse), nil)
 	return responseObject.(*TriggerTokenResponse), err
 }
 
@@ -247,8 +391,16 @@ func (hooks *Hooks) ResetTriggerToken(hookGroupId, hookId string) (*TriggerToken
 //
 // See #triggerHookWithToken
 func (hooks *Hooks) TriggerHookWithToken(hookGroupId, hookId, token string, payload *TriggerHookRequest) (*TriggerHookResponse, error) {
+	return hooks.TriggerHookWithTokenWithContext(hooks.Context, hookGroupId, hookId, token, payload)
+}
+
+// TriggerHookWithTokenWithContext is the same as TriggerHookWithToken, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See TriggerHookWithToken for more details.
+func (hooks *Hooks) TriggerHookWithTokenWithContext(ctx context.Context, hookGroupId, hookId, token string, payload *TriggerHookRequest) (*TriggerHookResponse, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/trigger/"+url.QueryEscape(token), new(TriggerHookResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "triggerHookWithToken"), payload, "POST", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/trigger/"+url.QueryEscape(token), new(TriggerHookResponse), nil)
 	return responseObject.(*TriggerHookResponse), err
 }
 
@@ -259,7 +411,34 @@ func (hooks *Hooks) TriggerHookWithToken(hookGroupId, hookId, token string, payl
 //
 // See #listLastFires
 func (hooks *Hooks) ListLastFires(hookGroupId, hookId string) (*LastFiresList, error) {
+	return hooks.ListLastFiresWithContext(hooks.Context, hookGroupId, hookId)
+}
+
+// ListLastFiresWithContext is the same as ListLastFires, except that the
+// request is bound to ctx rather than to hooks.Context.
+//
+// See ListLastFires for more details.
+func (hooks *Hooks) ListLastFiresWithContext(ctx context.Context, hookGroupId, hookId string) (*LastFiresList, error) {
 	cd := tcclient.Client(*hooks)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/last-fires", new(LastFiresList), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "hooks", "listLastFires"), nil, "GET", "/hooks/"+url.QueryEscape(hookGroupId)+"/"+url.QueryEscape(hookId)+"/last-fires", new(LastFiresList), nil)
 	return responseObject.(*LastFiresList), err
 }
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"createHook": scopes.AllOf{
+		scopes.RequiredScope("hooks:modify-hook:<hookGroupId>/<hookId>"),
+		scopes.RequiredScope("assume:hook-id:<hookGroupId>/<hookId>"),
+	},
+	"updateHook": scopes.AllOf{
+		scopes.RequiredScope("hooks:modify-hook:<hookGroupId>/<hookId>"),
+		scopes.RequiredScope("assume:hook-id:<hookGroupId>/<hookId>"),
+	},
+	"removeHook":        scopes.RequiredScope("hooks:modify-hook:<hookGroupId>/<hookId>"),
+	"triggerHook":       scopes.RequiredScope("hooks:trigger-hook:<hookGroupId>/<hookId>"),
+	"getTriggerToken":   scopes.RequiredScope("hooks:get-trigger-token:<hookGroupId>/<hookId>"),
+	"resetTriggerToken": scopes.RequiredScope("hooks:reset-trigger-token:<hookGroupId>/<hookId>"),
+}
@@ -43,7 +43,7 @@ type (
 		//   * "error"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/hook-status.json#/properties/lastFire/oneOf[1]/properties/result
-		Result string `json:"result"`
+		Result FailedFireResult `json:"result"`
 
 		// The time the task was created.  This will not necessarily match `task.created`.
 		//
@@ -244,7 +244,7 @@ type (
 		// any schedules.
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/hook-status.json#/properties/nextScheduledDate
-		NextScheduledDate tcclient.Time `json:"nextScheduledDate,omitempty"`
+		NextScheduledDate *tcclient.Time `json:"nextScheduledDate,omitempty"`
 	}
 
 	// List of lastFires
@@ -265,7 +265,7 @@ type (
 		//   * "no-fire"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/hook-status.json#/properties/lastFire/oneOf[2]/properties/result
-		Result string `json:"result"`
+		Result NoFireResult `json:"result"`
 	}
 
 	// JSON object with information about a run
@@ -284,7 +284,7 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/task-status.json#/properties/status/properties/runs/items/properties/reasonCreated
-		ReasonCreated string `json:"reasonCreated"`
+		ReasonCreated ReasonCreated `json:"reasonCreated"`
 
 		// Reason that run was resolved, this is mainly
 		// useful for runs resolved as `exception`.
@@ -305,14 +305,14 @@ type (
 		//   * "intermittent-task"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/task-status.json#/properties/status/properties/runs/items/properties/reasonResolved
-		ReasonResolved string `json:"reasonResolved,omitempty"`
+		ReasonResolved ReasonResolved `json:"reasonResolved,omitempty"`
 
 		// Date-time at which this run was resolved, ie. when the run changed
 		// state from `running` to either `completed`, `failed` or `exception`.
 		// This property is only present after the run as been resolved.
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/task-status.json#/properties/status/properties/runs/items/properties/resolved
-		Resolved tcclient.Time `json:"resolved,omitempty"`
+		Resolved *tcclient.Time `json:"resolved,omitempty"`
 
 		// Id of this task run, `run-id`s always starts from `0`
 		//
@@ -333,7 +333,7 @@ type (
 		// after the run has been claimed.
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/task-status.json#/properties/status/properties/runs/items/properties/started
-		Started tcclient.Time `json:"started,omitempty"`
+		Started *tcclient.Time `json:"started,omitempty"`
 
 		// State of this run
 		//
@@ -345,14 +345,14 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/task-status.json#/properties/status/properties/runs/items/properties/state
-		State string `json:"state"`
+		State RunInformationState `json:"state"`
 
 		// Time at which the run expires and is resolved as `failed`, if the
 		// run isn't reclaimed. Note, only present after the run has been
 		// claimed.
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/task-status.json#/properties/status/properties/runs/items/properties/takenUntil
-		TakenUntil tcclient.Time `json:"takenUntil,omitempty"`
+		TakenUntil *tcclient.Time `json:"takenUntil,omitempty"`
 
 		// Identifier for group that worker who executes this run is a part of,
 		// this identifier is mainly used for efficient routing.
@@ -438,7 +438,7 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/task-status.json#/properties/status/properties/state
-		State string `json:"state"`
+		State StatusState `json:"state"`
 
 		// Identifier for a group of tasks scheduled together with this task, by
 		// scheduler identified by `schedulerId`. For tasks scheduled by the
@@ -477,7 +477,7 @@ type (
 		//   * "success"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/hook-status.json#/properties/lastFire/oneOf[0]/properties/result
-		Result string `json:"result"`
+		Result SuccessfulFireResult `json:"result"`
 
 		// The task created
 		//
@@ -552,7 +552,7 @@ type (
 		//   * "pulseMessage"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/list-lastFires-response.json#/properties/lastFires/items/properties/firedBy
-		FiredBy string `json:"firedBy"`
+		FiredBy FiredBy `json:"firedBy"`
 
 		// Syntax:     ^([a-zA-Z0-9-_]*)$
 		// Min length: 1
@@ -575,7 +575,7 @@ type (
 		//   * "error"
 		//
 		// See https://taskcluster-staging.net/schemas/hooks/v1/list-lastFires-response.json#/properties/lastFires/items/properties/result
-		Result string `json:"result"`
+		Result VarResult `json:"result"`
 
 		// Time when the task was created
 		//
@@ -624,3 +624,192 @@ func (this *TriggerHookResponse) UnmarshalJSON(data []byte) error {
 	*this = append((*this)[0:0], data...)
 	return nil
 }
+
+// FailedFireResult is the type of the `result` property of FailedFire.
+// Its possible values are declared as constants.
+type FailedFireResult string
+
+// Possible values of FailedFireResult
+const (
+	FailedFireResultError FailedFireResult = "error"
+)
+
+// Valid returns true if this is one of the possible values of FailedFireResult.
+func (this FailedFireResult) Valid() bool {
+	switch this {
+	case FailedFireResultError:
+		return true
+	}
+	return false
+}
+
+// NoFireResult is the type of the `result` property of NoFire.
+// Its possible values are declared as constants.
+type NoFireResult string
+
+// Possible values of NoFireResult
+const (
+	NoFireResultNoFire NoFireResult = "no-fire"
+)
+
+// Valid returns true if this is one of the possible values of NoFireResult.
+func (this NoFireResult) Valid() bool {
+	switch this {
+	case NoFireResultNoFire:
+		return true
+	}
+	return false
+}
+
+// ReasonCreated is the type of the `reasonCreated` property of RunInformation.
+// Its possible values are declared as constants.
+type ReasonCreated string
+
+// Possible values of ReasonCreated
+const (
+	ReasonCreatedScheduled ReasonCreated = "scheduled"
+	ReasonCreatedRetry     ReasonCreated = "retry"
+	ReasonCreatedTaskRetry ReasonCreated = "task-retry"
+	ReasonCreatedRerun     ReasonCreated = "rerun"
+	ReasonCreatedException ReasonCreated = "exception"
+)
+
+// Valid returns true if this is one of the possible values of ReasonCreated.
+func (this ReasonCreated) Valid() bool {
+	switch this {
+	case ReasonCreatedScheduled, ReasonCreatedRetry, ReasonCreatedTaskRetry, ReasonCreatedRerun, ReasonCreatedException:
+		return true
+	}
+	return false
+}
+
+// ReasonResolved is the type of the `reasonResolved` property of RunInformation.
+// Its possible values are declared as constants.
+type ReasonResolved string
+
+// Possible values of ReasonResolved
+const (
+	ReasonResolvedCompleted           ReasonResolved = "completed"
+	ReasonResolvedFailed              ReasonResolved = "failed"
+	ReasonResolvedDeadlineExceeded    ReasonResolved = "deadline-exceeded"
+	ReasonResolvedCanceled            ReasonResolved = "canceled"
+	ReasonResolvedSuperseded          ReasonResolved = "superseded"
+	ReasonResolvedClaimExpired        ReasonResolved = "claim-expired"
+	ReasonResolvedWorkerShutdown      ReasonResolved = "worker-shutdown"
+	ReasonResolvedMalformedPayload    ReasonResolved = "malformed-payload"
+	ReasonResolvedResourceUnavailable ReasonResolved = "resource-unavailable"
+	ReasonResolvedInternalError       ReasonResolved = "internal-error"
+	ReasonResolvedIntermittentTask    ReasonResolved = "intermittent-task"
+)
+
+// Valid returns true if this is one of the possible values of ReasonResolved.
+func (this ReasonResolved) Valid() bool {
+	switch this {
+	case ReasonResolvedCompleted, ReasonResolvedFailed, ReasonResolvedDeadlineExceeded, ReasonResolvedCanceled, ReasonResolvedSuperseded, ReasonResolvedClaimExpired, ReasonResolvedWorkerShutdown, ReasonResolvedMalformedPayload, ReasonResolvedResourceUnavailable, ReasonResolvedInternalError, ReasonResolvedIntermittentTask:
+		return true
+	}
+	return false
+}
+
+// RunInformationState is the type of the `state` property of RunInformation.
+// Its possible values are declared as constants.
+type RunInformationState string
+
+// Possible values of RunInformationState
+const (
+	RunInformationStatePending   RunInformationState = "pending"
+	RunInformationStateRunning   RunInformationState = "running"
+	RunInformationStateCompleted RunInformationState = "completed"
+	RunInformationStateFailed    RunInformationState = "failed"
+	RunInformationStateException RunInformationState = "exception"
+)
+
+// Valid returns true if this is one of the possible values of RunInformationState.
+func (this RunInformationState) Valid() bool {
+	switch this {
+	case RunInformationStatePending, RunInformationStateRunning, RunInformationStateCompleted, RunInformationStateFailed, RunInformationStateException:
+		return true
+	}
+	return false
+}
+
+// StatusState is the type of the `state` property of Status.
+// Its possible values are declared as constants.
+type StatusState string
+
+// Possible values of StatusState
+const (
+	StatusStateUnscheduled StatusState = "unscheduled"
+	StatusStatePending     StatusState = "pending"
+	StatusStateRunning     StatusState = "running"
+	StatusStateCompleted   StatusState = "completed"
+	StatusStateFailed      StatusState = "failed"
+	StatusStateException   StatusState = "exception"
+)
+
+// Valid returns true if this is one of the possible values of StatusState.
+func (this StatusState) Valid() bool {
+	switch this {
+	case StatusStateUnscheduled, StatusStatePending, StatusStateRunning, StatusStateCompleted, StatusStateFailed, StatusStateException:
+		return true
+	}
+	return false
+}
+
+// SuccessfulFireResult is the type of the `result` property of SuccessfulFire.
+// Its possible values are declared as constants.
+type SuccessfulFireResult string
+
+// Possible values of SuccessfulFireResult
+const (
+	SuccessfulFireResultSuccess SuccessfulFireResult = "success"
+)
+
+// Valid returns true if this is one of the possible values of SuccessfulFireResult.
+func (this SuccessfulFireResult) Valid() bool {
+	switch this {
+	case SuccessfulFireResultSuccess:
+		return true
+	}
+	return false
+}
+
+// FiredBy is the type of the `firedBy` property of Var.
+// Its possible values are declared as constants.
+type FiredBy string
+
+// Possible values of FiredBy
+const (
+	FiredBySchedule             FiredBy = "schedule"
+	FiredByTriggerHook          FiredBy = "triggerHook"
+	FiredByTriggerHookWithToken FiredBy = "triggerHookWithToken"
+	FiredByPulseMessage         FiredBy = "pulseMessage"
+)
+
+// Valid returns true if this is one of the possible values of FiredBy.
+func (this FiredBy) Valid() bool {
+	switch this {
+	case FiredBySchedule, FiredByTriggerHook, FiredByTriggerHookWithToken, FiredByPulseMessage:
+		return true
+	}
+	return false
+}
+
+// VarResult is the type of the `result` property of Var.
+// Its possible values are declared as constants.
+type VarResult string
+
+// Possible values of VarResult
+const (
+	VarResultSuccess VarResult = "success"
+	VarResultError   VarResult = "error"
+)
+
+// Valid returns true if this is one of the possible values of VarResult.
+func (this VarResult) Valid() bool {
+	switch this {
+	case VarResultSuccess, VarResultError:
+		return true
+	}
+	return false
+}
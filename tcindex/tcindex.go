@@ -41,10 +41,13 @@
 package tcindex
 
 import (
+	"context"
+	"errors"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Index tcclient.Client
@@ -92,13 +95,62 @@ func NewFromEnv() *Index {
 	}
 }
 
+// NewFromProfile returns an *Index configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Index, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcindex.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Index, comprising all of its
+// API methods. Code that depends on API rather than *Index can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	FindTask(indexPath string) (*IndexedTaskResponse, error)
+	FindTaskWithContext(ctx context.Context, indexPath string) (*IndexedTaskResponse, error)
+	ListNamespaces(namespace, continuationToken, limit string) (*ListNamespacesResponse, error)
+	ListNamespacesWithContext(ctx context.Context, namespace, continuationToken, limit string) (*ListNamespacesResponse, error)
+	ListNamespacesAll(ctx context.Context, namespace string) *NamespaceIterator
+	ListTasks(namespace, continuationToken, limit string) (*ListTasksResponse, error)
+	ListTasksWithContext(ctx context.Context, namespace, continuationToken, limit string) (*ListTasksResponse, error)
+	ListTasksAll(ctx context.Context, namespace string) *TaskIterator
+	InsertTask(namespace string, payload *InsertTaskRequest) (*IndexedTaskResponse, error)
+	InsertTaskWithContext(ctx context.Context, namespace string, payload *InsertTaskRequest) (*IndexedTaskResponse, error)
+	FindArtifactFromTask(indexPath, name string) error
+	FindArtifactFromTaskWithContext(ctx context.Context, indexPath, name string) error
+	FindArtifactFromTask_SignedURL(indexPath, name string, duration time.Duration) (*url.URL, error)
+}
+
+var _ API = (*Index)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (index *Index) Ping() error {
+	return index.PingWithContext(index.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to index.Context.
+//
+// See Ping for more details.
+func (index *Index) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*index)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "index", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -107,8 +159,16 @@ func (index *Index) Ping() error {
 //
 // See #findTask
 func (index *Index) FindTask(indexPath string) (*IndexedTaskResponse, error) {
+	return index.FindTaskWithContext(index.Context, indexPath)
+}
+
+// FindTaskWithContext is the same as FindTask, except that the
+// request is bound to ctx rather than to index.Context.
+//
+// See FindTask for more details.
+func (index *Index) FindTaskWithContext(ctx context.Context, indexPath string) (*IndexedTaskResponse, error) {
 	cd := tcclient.Client(*index)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(indexPath), new(IndexedTaskResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "index", "findTask"), nil, "GET", "/task/"+url.QueryEscape(indexPath), new(IndexedTaskResponse), nil)
 	return responseObject.(*IndexedTaskResponse), err
 }
 
@@ -122,6 +182,14 @@ func (index *Index) FindTask(indexPath string) (*IndexedTaskResponse, error) {
 //
 // See #listNamespaces
 func (index *Index) ListNamespaces(namespace, continuationToken, limit string) (*ListNamespacesResponse, error) {
+	return index.ListNamespacesWithContext(index.Context, namespace, continuationToken, limit)
+}
+
+// ListNamespacesWithContext is the same as ListNamespaces, except that the
+// request is bound to ctx rather than to index.Context.
+//
+// See ListNamespaces for more details.
+func (index *Index) ListNamespacesWithContext(ctx context.Context, namespace, continuationToken, limit string) (*ListNamespacesResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -130,10 +198,27 @@ func (index *Index) ListNamespaces(namespace, continuationToken, limit string) (
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*index)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/namespaces/"+url.QueryEscape(namespace), new(ListNamespacesResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "index", "listNamespaces"), nil, "GET", "/namespaces/"+url.QueryEscape(namespace), new(ListNamespacesResponse), v)
 	return responseObject.(*ListNamespacesResponse), err
 }
 
+// ListNamespacesAll returns an iterator over the Namespace items of all
+// pages of ListNamespaces results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListNamespaces for more details.
+func (index *Index) ListNamespacesAll(ctx context.Context, namespace string) *NamespaceIterator {
+	return NewNamespaceIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Namespace, string, error) {
+		page, err := index.ListNamespacesWithContext(ctx, namespace, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Namespaces, page.ContinuationToken, nil
+	})
+}
+
 // List the tasks immediately under a given namespace.
 //
 // This endpoint
@@ -147,6 +232,14 @@ func (index *Index) ListNamespaces(namespace, continuationToken, limit string) (
 //
 // See #listTasks
 func (index *Index) ListTasks(namespace, continuationToken, limit string) (*ListTasksResponse, error) {
+	return index.ListTasksWithContext(index.Context, namespace, continuationToken, limit)
+}
+
+// ListTasksWithContext is the same as ListTasks, except that the
+// request is bound to ctx rather than to index.Context.
+//
+// See ListTasks for more details.
+func (index *Index) ListTasksWithContext(ctx context.Context, namespace, continuationToken, limit string) (*ListTasksResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -155,10 +248,27 @@ func (index *Index) ListTasks(namespace, continuationToken, limit string) (*List
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*index)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/tasks/"+url.QueryEscape(namespace), new(ListTasksResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "index", "listTasks"), nil, "GET", "/tasks/"+url.QueryEscape(namespace), new(ListTasksResponse), v)
 	return responseObject.(*ListTasksResponse), err
 }
 
+// ListTasksAll returns an iterator over the Task items of all
+// pages of ListTasks results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListTasks for more details.
+func (index *Index) ListTasksAll(ctx context.Context, namespace string) *TaskIterator {
+	return NewTaskIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Task, string, error) {
+		page, err := index.ListTasksWithContext(ctx, namespace, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Tasks, page.ContinuationToken, nil
+	})
+}
+
 // Insert a task into the index.  If the new rank is less than the existing rank
 // at the given index path, the task is not indexed but the response is still 200 OK.
 //
@@ -170,8 +280,16 @@ func (index *Index) ListTasks(namespace, continuationToken, limit string) (*List
 //
 // See #insertTask
 func (index *Index) InsertTask(namespace string, payload *InsertTaskRequest) (*IndexedTaskResponse, error) {
+	return index.InsertTaskWithContext(index.Context, namespace, payload)
+}
+
+// InsertTaskWithContext is the same as InsertTask, except that the
+// request is bound to ctx rather than to index.Context.
+//
+// See InsertTask for more details.
+func (index *Index) InsertTaskWithContext(ctx context.Context, namespace string, payload *InsertTaskRequest) (*IndexedTaskResponse, error) {
 	cd := tcclient.Client(*index)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/task/"+url.QueryEscape(namespace), new(IndexedTaskResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "index", "insertTask"), payload, "PUT", "/task/"+url.QueryEscape(namespace), new(IndexedTaskResponse), nil)
 	return responseObject.(*IndexedTaskResponse), err
 }
 
@@ -196,8 +314,16 @@ func (index *Index) InsertTask(namespace string, payload *InsertTaskRequest) (*I
 //
 // See #findArtifactFromTask
 func (index *Index) FindArtifactFromTask(indexPath, name string) error {
+	return index.FindArtifactFromTaskWithContext(index.Context, indexPath, name)
+}
+
+// FindArtifactFromTaskWithContext is the same as FindArtifactFromTask, except that the
+// request is bound to ctx rather than to index.Context.
+//
+// See FindArtifactFromTask for more details.
+func (index *Index) FindArtifactFromTaskWithContext(ctx context.Context, indexPath, name string) error {
 	cd := tcclient.Client(*index)
-	_, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(indexPath)+"/artifacts/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "index", "findArtifactFromTask"), nil, "GET", "/task/"+url.QueryEscape(indexPath)+"/artifacts/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -212,3 +338,79 @@ func (index *Index) FindArtifactFromTask_SignedURL(indexPath, name string, durat
 	cd := tcclient.Client(*index)
 	return (&cd).SignedURL("/task/"+url.QueryEscape(indexPath)+"/artifacts/"+url.QueryEscape(name), nil, duration)
 }
+
+// NamespaceIterator iterates over the Namespace items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type NamespaceIterator struct {
+	*tcclient.Pager
+	items []Namespace
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *NamespaceIterator) Item() Namespace {
+	return it.items[it.Index()]
+}
+
+// NewNamespaceIterator returns a NamespaceIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewNamespaceIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]Namespace, string, error)) *NamespaceIterator {
+	it := new(NamespaceIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// TaskIterator iterates over the Task items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type TaskIterator struct {
+	*tcclient.Pager
+	items []Task
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *TaskIterator) Item() Task {
+	return it.items[it.Index()]
+}
+
+// NewTaskIterator returns a TaskIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewTaskIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]Task, string, error)) *TaskIterator {
+	it := new(TaskIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"insertTask": scopes.RequiredScope("index:insert-task:<namespace>"),
+	"findArtifactFromTask": scopes.IfThen{
+		If:   "private",
+		Then: scopes.RequiredScope("queue:get-artifact:<name>"),
+	},
+}
@@ -38,9 +38,12 @@
 package tclogin
 
 import (
+	"context"
+	"errors"
 	"net/url"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Login tcclient.Client
@@ -88,13 +91,51 @@ func NewFromEnv() *Login {
 	}
 }
 
+// NewFromProfile returns a *Login configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Login, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tclogin.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Login, comprising all of its
+// API methods. Code that depends on API rather than *Login can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	OidcCredentials(provider string) (*CredentialsResponse, error)
+	OidcCredentialsWithContext(ctx context.Context, provider string) (*CredentialsResponse, error)
+}
+
+var _ API = (*Login)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (login *Login) Ping() error {
+	return login.PingWithContext(login.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to login.Context.
+//
+// See Ping for more details.
+func (login *Login) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*login)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "login", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -124,7 +165,21 @@ func (login *Login) Ping() error {
 //
 // See #oidcCredentials
 func (login *Login) OidcCredentials(provider string) (*CredentialsResponse, error) {
+	return login.OidcCredentialsWithContext(login.Context, provider)
+}
+
+// OidcCredentialsWithContext is the same as OidcCredentials, except that the
+// request is bound to ctx rather than to login.Context.
+//
+// See OidcCredentials for more details.
+func (login *Login) OidcCredentialsWithContext(ctx context.Context, provider string) (*CredentialsResponse, error) {
 	cd := tcclient.Client(*login)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/oidc-credentials/"+url.QueryEscape(provider), new(CredentialsResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "login", "oidcCredentials"), nil, "GET", "/oidc-credentials/"+url.QueryEscape(provider), new(CredentialsResponse), nil)
 	return responseObject.(*CredentialsResponse), err
 }
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{}
@@ -38,10 +38,13 @@
 package tcnotify
 
 import (
+	"context"
+	"errors"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Notify tcclient.Client
@@ -89,13 +92,63 @@ func NewFromEnv() *Notify {
 	}
 }
 
+// NewFromProfile returns a *Notify configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Notify, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcnotify.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Notify, comprising all of its
+// API methods. Code that depends on API rather than *Notify can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	Email(payload *SendEmailRequest) error
+	EmailWithContext(ctx context.Context, payload *SendEmailRequest) error
+	Pulse(payload *PostPulseMessageRequest) error
+	PulseWithContext(ctx context.Context, payload *PostPulseMessageRequest) error
+	Irc(payload *PostIRCMessageRequest) error
+	IrcWithContext(ctx context.Context, payload *PostIRCMessageRequest) error
+	AddDenylistAddress(payload *NotificationTypeAndAddress) error
+	AddDenylistAddressWithContext(ctx context.Context, payload *NotificationTypeAndAddress) error
+	DeleteDenylistAddress(payload *NotificationTypeAndAddress) error
+	DeleteDenylistAddressWithContext(ctx context.Context, payload *NotificationTypeAndAddress) error
+	ListDenylist(continuationToken, limit string) (*ListOfNotificationAdresses, error)
+	ListDenylistWithContext(ctx context.Context, continuationToken, limit string) (*ListOfNotificationAdresses, error)
+	ListDenylist_SignedURL(continuationToken, limit string, duration time.Duration) (*url.URL, error)
+	ListDenylistAll(ctx context.Context) *NotificationTypeAndAddressIterator
+}
+
+var _ API = (*Notify)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (notify *Notify) Ping() error {
+	return notify.PingWithContext(notify.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to notify.Context.
+//
+// See Ping for more details.
+func (notify *Notify) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*notify)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "notify", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -111,8 +164,16 @@ func (notify *Notify) Ping() error {
 //
 // See #email
 func (notify *Notify) Email(payload *SendEmailRequest) error {
+	return notify.EmailWithContext(notify.Context, payload)
+}
+
+// EmailWithContext is the same as Email, except that the
+// request is bound to ctx rather than to notify.Context.
+//
+// See Email for more details.
+func (notify *Notify) EmailWithContext(ctx context.Context, payload *SendEmailRequest) error {
 	cd := tcclient.Client(*notify)
-	_, _, err := (&cd).APICall(payload, "POST", "/email", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "notify", "email"), payload, "POST", "/email", nil, nil)
 	return err
 }
 
@@ -125,8 +186,16 @@ func (notify *Notify) Email(payload *SendEmailRequest) error {
 //
 // See #pulse
 func (notify *Notify) Pulse(payload *PostPulseMessageRequest) error {
+	return notify.PulseWithContext(notify.Context, payload)
+}
+
+// PulseWithContext is the same as Pulse, except that the
+// request is bound to ctx rather than to notify.Context.
+//
+// See Pulse for more details.
+func (notify *Notify) PulseWithContext(ctx context.Context, payload *PostPulseMessageRequest) error {
 	cd := tcclient.Client(*notify)
-	_, _, err := (&cd).APICall(payload, "POST", "/pulse", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "notify", "pulse"), payload, "POST", "/pulse", nil, nil)
 	return err
 }
 
@@ -150,8 +219,16 @@ func (notify *Notify) Pulse(payload *PostPulseMessageRequest) error {
 //
 // See #irc
 func (notify *Notify) Irc(payload *PostIRCMessageRequest) error {
+	return notify.IrcWithContext(notify.Context, payload)
+}
+
+// IrcWithContext is the same as Irc, except that the
+// request is bound to ctx rather than to notify.Context.
+//
+// See Irc for more details.
+func (notify *Notify) IrcWithContext(ctx context.Context, payload *PostIRCMessageRequest) error {
 	cd := tcclient.Client(*notify)
-	_, _, err := (&cd).APICall(payload, "POST", "/irc", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "notify", "irc"), payload, "POST", "/irc", nil, nil)
 	return err
 }
 
@@ -167,8 +244,16 @@ func (notify *Notify) Irc(payload *PostIRCMessageRequest) error {
 //
 // See #addDenylistAddress
 func (notify *Notify) AddDenylistAddress(payload *NotificationTypeAndAddress) error {
+	return notify.AddDenylistAddressWithContext(notify.Context, payload)
+}
+
+// AddDenylistAddressWithContext is the same as AddDenylistAddress, except that the
+// request is bound to ctx rather than to notify.Context.
+//
+// See AddDenylistAddress for more details.
+func (notify *Notify) AddDenylistAddressWithContext(ctx context.Context, payload *NotificationTypeAndAddress) error {
 	cd := tcclient.Client(*notify)
-	_, _, err := (&cd).APICall(payload, "POST", "/denylist/add", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "notify", "addDenylistAddress"), payload, "POST", "/denylist/add", nil, nil)
 	return err
 }
 
@@ -181,8 +266,16 @@ func (notify *Notify) AddDenylistAddress(payload *NotificationTypeAndAddress) er
 //
 // See #deleteDenylistAddress
 func (notify *Notify) DeleteDenylistAddress(payload *NotificationTypeAndAddress) error {
+	return notify.DeleteDenylistAddressWithContext(notify.Context, payload)
+}
+
+// DeleteDenylistAddressWithContext is the same as DeleteDenylistAddress, except that the
+// request is bound to ctx rather than to notify.Context.
+//
+// See DeleteDenylistAddress for more details.
+func (notify *Notify) DeleteDenylistAddressWithContext(ctx context.Context, payload *NotificationTypeAndAddress) error {
 	cd := tcclient.Client(*notify)
-	_, _, err := (&cd).APICall(payload, "DELETE", "/denylist/delete", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "notify", "deleteDenylistAddress"), payload, "DELETE", "/denylist/delete", nil, nil)
 	return err
 }
 
@@ -205,6 +298,14 @@ func (notify *Notify) DeleteDenylistAddress(payload *NotificationTypeAndAddress)
 //
 // See #listDenylist
 func (notify *Notify) ListDenylist(continuationToken, limit string) (*ListOfNotificationAdresses, error) {
+	return notify.ListDenylistWithContext(notify.Context, continuationToken, limit)
+}
+
+// ListDenylistWithContext is the same as ListDenylist, except that the
+// request is bound to ctx rather than to notify.Context.
+//
+// See ListDenylist for more details.
+func (notify *Notify) ListDenylistWithContext(ctx context.Context, continuationToken, limit string) (*ListOfNotificationAdresses, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -213,7 +314,7 @@ func (notify *Notify) ListDenylist(continuationToken, limit string) (*ListOfNoti
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*notify)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/denylist/list", new(ListOfNotificationAdresses), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "notify", "listDenylist"), nil, "GET", "/denylist/list", new(ListOfNotificationAdresses), v)
 	return responseObject.(*ListOfNotificationAdresses), err
 }
 
@@ -234,3 +335,68 @@ func (notify *Notify) ListDenylist_SignedURL(continuationToken, limit string, du
 	cd := tcclient.Client(*notify)
 	return (&cd).SignedURL("/denylist/list", v, duration)
 }
+
+// ListDenylistAll returns an iterator over the NotificationTypeAndAddress items of all
+// pages of ListDenylist results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListDenylist for more details.
+func (notify *Notify) ListDenylistAll(ctx context.Context) *NotificationTypeAndAddressIterator {
+	return NewNotificationTypeAndAddressIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]NotificationTypeAndAddress, string, error) {
+		page, err := notify.ListDenylistWithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Addresses, page.ContinuationToken, nil
+	})
+}
+
+// NotificationTypeAndAddressIterator iterates over the NotificationTypeAndAddress items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type NotificationTypeAndAddressIterator struct {
+	*tcclient.Pager
+	items []NotificationTypeAndAddress
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *NotificationTypeAndAddressIterator) Item() NotificationTypeAndAddress {
+	return it.items[it.Index()]
+}
+
+// NewNotificationTypeAndAddressIterator returns a NotificationTypeAndAddressIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewNotificationTypeAndAddressIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]NotificationTypeAndAddress, string, error)) *NotificationTypeAndAddressIterator {
+	it := new(NotificationTypeAndAddressIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"email": scopes.RequiredScope("notify:email:<address>"),
+	"pulse": scopes.RequiredScope("notify:pulse:<routingKey>"),
+	"irc": scopes.IfThen{
+		If:   "channelRequest",
+		Then: scopes.RequiredScope("notify:irc-channel:<channel>"),
+	},
+	"addDenylistAddress":    scopes.RequiredScope("notify:manage-denylist"),
+	"deleteDenylistAddress": scopes.RequiredScope("notify:manage-denylist"),
+	"listDenylist":          scopes.RequiredScope("notify:manage-denylist"),
+}
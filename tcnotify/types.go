@@ -5,6 +5,7 @@ package tcnotify
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 type (
@@ -83,7 +84,7 @@ type (
 		//   * "irc-channel"
 		//
 		// See https://taskcluster-staging.net/schemas/notify/v1/notification-address.json#/properties/notificationType
-		NotificationType string `json:"notificationType"`
+		NotificationType NotificationType `json:"notificationType"`
 	}
 
 	// Request to post a message on IRC.
@@ -185,7 +186,7 @@ type (
 		// Default:    "simple"
 		//
 		// See https://taskcluster-staging.net/schemas/notify/v1/email-request.json#/properties/template
-		Template string `json:"template,omitempty"`
+		Template Template `json:"template,omitempty"`
 	}
 )
 
@@ -204,3 +205,63 @@ func (this *PostIRCMessageRequest) UnmarshalJSON(data []byte) error {
 	*this = append((*this)[0:0], data...)
 	return nil
 }
+
+// NotificationType is the type of the `notificationType` property of NotificationTypeAndAddress.
+// Its possible values are declared as constants.
+type NotificationType string
+
+// Possible values of NotificationType
+const (
+	NotificationTypeEmail      NotificationType = "email"
+	NotificationTypePulse      NotificationType = "pulse"
+	NotificationTypeIrcUser    NotificationType = "irc-user"
+	NotificationTypeIrcChannel NotificationType = "irc-channel"
+)
+
+// Valid returns true if this is one of the possible values of NotificationType.
+func (this NotificationType) Valid() bool {
+	switch this {
+	case NotificationTypeEmail, NotificationTypePulse, NotificationTypeIrcUser, NotificationTypeIrcChannel:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid NotificationType.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this NotificationType) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid NotificationType %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// Template is the type of the `template` property of SendEmailRequest.
+// Its possible values are declared as constants.
+type Template string
+
+// Possible values of Template
+const (
+	TemplateSimple     Template = "simple"
+	TemplateFullscreen Template = "fullscreen"
+)
+
+// Valid returns true if this is one of the possible values of Template.
+func (this Template) Valid() bool {
+	switch this {
+	case TemplateSimple, TemplateFullscreen:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid Template.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this Template) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid Template %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
@@ -41,9 +41,12 @@
 package tcpurgecache
 
 import (
+	"context"
+	"errors"
 	"net/url"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type PurgeCache tcclient.Client
@@ -91,13 +94,56 @@ func NewFromEnv() *PurgeCache {
 	}
 }
 
+// NewFromProfile returns a *PurgeCache configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*PurgeCache, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcpurgecache.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *PurgeCache, comprising all of its
+// API methods. Code that depends on API rather than *PurgeCache can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	PurgeCache(provisionerId, workerType string, payload *PurgeCacheRequest) error
+	PurgeCacheWithContext(ctx context.Context, provisionerId, workerType string, payload *PurgeCacheRequest) error
+	AllPurgeRequests(continuationToken, limit string) (*OpenAllPurgeRequestsList, error)
+	AllPurgeRequestsWithContext(ctx context.Context, continuationToken, limit string) (*OpenAllPurgeRequestsList, error)
+	AllPurgeRequestsAll(ctx context.Context) *PurgeCacheRequestsEntryIterator
+	PurgeRequests(provisionerId, workerType, since string) (*OpenPurgeRequestList, error)
+	PurgeRequestsWithContext(ctx context.Context, provisionerId, workerType, since string) (*OpenPurgeRequestList, error)
+}
+
+var _ API = (*PurgeCache)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (purgeCache *PurgeCache) Ping() error {
+	return purgeCache.PingWithContext(purgeCache.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to purgeCache.Context.
+//
+// See Ping for more details.
+func (purgeCache *PurgeCache) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*purgeCache)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "purge-cache", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -112,8 +158,16 @@ func (purgeCache *PurgeCache) Ping() error {
 //
 // See #purgeCache
 func (purgeCache *PurgeCache) PurgeCache(provisionerId, workerType string, payload *PurgeCacheRequest) error {
+	return purgeCache.PurgeCacheWithContext(purgeCache.Context, provisionerId, workerType, payload)
+}
+
+// PurgeCacheWithContext is the same as PurgeCache, except that the
+// request is bound to ctx rather than to purgeCache.Context.
+//
+// See PurgeCache for more details.
+func (purgeCache *PurgeCache) PurgeCacheWithContext(ctx context.Context, provisionerId, workerType string, payload *PurgeCacheRequest) error {
 	cd := tcclient.Client(*purgeCache)
-	_, _, err := (&cd).APICall(payload, "POST", "/purge-cache/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "purge-cache", "purgeCache"), payload, "POST", "/purge-cache/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), nil, nil)
 	return err
 }
 
@@ -127,6 +181,14 @@ func (purgeCache *PurgeCache) PurgeCache(provisionerId, workerType string, paylo
 //
 // See #allPurgeRequests
 func (purgeCache *PurgeCache) AllPurgeRequests(continuationToken, limit string) (*OpenAllPurgeRequestsList, error) {
+	return purgeCache.AllPurgeRequestsWithContext(purgeCache.Context, continuationToken, limit)
+}
+
+// AllPurgeRequestsWithContext is the same as AllPurgeRequests, except that the
+// request is bound to ctx rather than to purgeCache.Context.
+//
+// See AllPurgeRequests for more details.
+func (purgeCache *PurgeCache) AllPurgeRequestsWithContext(ctx context.Context, continuationToken, limit string) (*OpenAllPurgeRequestsList, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -135,10 +197,27 @@ func (purgeCache *PurgeCache) AllPurgeRequests(continuationToken, limit string)
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*purgeCache)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/purge-cache/list", new(OpenAllPurgeRequestsList), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "purge-cache", "allPurgeRequests"), nil, "GET", "/purge-cache/list", new(OpenAllPurgeRequestsList), v)
 	return responseObject.(*OpenAllPurgeRequestsList), err
 }
 
+// AllPurgeRequestsAll returns an iterator over the PurgeCacheRequestsEntry items of all
+// pages of AllPurgeRequests results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See AllPurgeRequests for more details.
+func (purgeCache *PurgeCache) AllPurgeRequestsAll(ctx context.Context) *PurgeCacheRequestsEntryIterator {
+	return NewPurgeCacheRequestsEntryIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]PurgeCacheRequestsEntry, string, error) {
+		page, err := purgeCache.AllPurgeRequestsWithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Requests, page.ContinuationToken, nil
+	})
+}
+
 // List the caches for this `provisionerId`/`workerType` that should to be
 // purged if they are from before the time given in the response.
 //
@@ -146,11 +225,59 @@ func (purgeCache *PurgeCache) AllPurgeRequests(continuationToken, limit string)
 //
 // See #purgeRequests
 func (purgeCache *PurgeCache) PurgeRequests(provisionerId, workerType, since string) (*OpenPurgeRequestList, error) {
+	return purgeCache.PurgeRequestsWithContext(purgeCache.Context, provisionerId, workerType, since)
+}
+
+// PurgeRequestsWithContext is the same as PurgeRequests, except that the
+// request is bound to ctx rather than to purgeCache.Context.
+//
+// See PurgeRequests for more details.
+func (purgeCache *PurgeCache) PurgeRequestsWithContext(ctx context.Context, provisionerId, workerType, since string) (*OpenPurgeRequestList, error) {
 	v := url.Values{}
 	if since != "" {
 		v.Add("since", since)
 	}
 	cd := tcclient.Client(*purgeCache)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/purge-cache/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), new(OpenPurgeRequestList), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "purge-cache", "purgeRequests"), nil, "GET", "/purge-cache/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), new(OpenPurgeRequestList), v)
 	return responseObject.(*OpenPurgeRequestList), err
 }
+
+// PurgeCacheRequestsEntryIterator iterates over the PurgeCacheRequestsEntry items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type PurgeCacheRequestsEntryIterator struct {
+	*tcclient.Pager
+	items []PurgeCacheRequestsEntry
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *PurgeCacheRequestsEntryIterator) Item() PurgeCacheRequestsEntry {
+	return it.items[it.Index()]
+}
+
+// NewPurgeCacheRequestsEntryIterator returns a PurgeCacheRequestsEntryIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewPurgeCacheRequestsEntryIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]PurgeCacheRequestsEntry, string, error)) *PurgeCacheRequestsEntryIterator {
+	it := new(PurgeCacheRequestsEntryIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"purgeCache": scopes.RequiredScope("purge-cache:<provisionerId>/<workerType>:<cacheName>"),
+}
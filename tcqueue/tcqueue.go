@@ -45,10 +45,13 @@
 package tcqueue
 
 import (
+	"context"
+	"errors"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Queue tcclient.Client
@@ -96,13 +99,122 @@ func NewFromEnv() *Queue {
 	}
 }
 
+// NewFromProfile returns a *Queue configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Queue, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcqueue.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Queue, comprising all of its
+// API methods. Code that depends on API rather than *Queue can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	Task(taskId string) (*TaskDefinitionResponse, error)
+	TaskWithContext(ctx context.Context, taskId string) (*TaskDefinitionResponse, error)
+	Status(taskId string) (*TaskStatusResponse, error)
+	StatusWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error)
+	ListTaskGroup(taskGroupId, continuationToken, limit string) (*ListTaskGroupResponse, error)
+	ListTaskGroupWithContext(ctx context.Context, taskGroupId, continuationToken, limit string) (*ListTaskGroupResponse, error)
+	ListTaskGroupAll(ctx context.Context, taskGroupId string) *TaskDefinitionAndStatusIterator
+	ListDependentTasks(taskId, continuationToken, limit string) (*ListDependentTasksResponse, error)
+	ListDependentTasksWithContext(ctx context.Context, taskId, continuationToken, limit string) (*ListDependentTasksResponse, error)
+	ListDependentTasksAll(ctx context.Context, taskId string) *TaskDefinitionAndStatusIterator
+	CreateTask(taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error)
+	CreateTaskWithContext(ctx context.Context, taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error)
+	DefineTask(taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error)
+	DefineTaskWithContext(ctx context.Context, taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error)
+	ScheduleTask(taskId string) (*TaskStatusResponse, error)
+	ScheduleTaskWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error)
+	RerunTask(taskId string) (*TaskStatusResponse, error)
+	RerunTaskWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error)
+	CancelTask(taskId string) (*TaskStatusResponse, error)
+	CancelTaskWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error)
+	ClaimWork(provisionerId, workerType string, payload *ClaimWorkRequest) (*ClaimWorkResponse, error)
+	ClaimWorkWithContext(ctx context.Context, provisionerId, workerType string, payload *ClaimWorkRequest) (*ClaimWorkResponse, error)
+	ClaimTask(taskId, runId string, payload *TaskClaimRequest) (*TaskClaimResponse, error)
+	ClaimTaskWithContext(ctx context.Context, taskId, runId string, payload *TaskClaimRequest) (*TaskClaimResponse, error)
+	ReclaimTask(taskId, runId string) (*TaskReclaimResponse, error)
+	ReclaimTaskWithContext(ctx context.Context, taskId, runId string) (*TaskReclaimResponse, error)
+	ReportCompleted(taskId, runId string) (*TaskStatusResponse, error)
+	ReportCompletedWithContext(ctx context.Context, taskId, runId string) (*TaskStatusResponse, error)
+	ReportFailed(taskId, runId string) (*TaskStatusResponse, error)
+	ReportFailedWithContext(ctx context.Context, taskId, runId string) (*TaskStatusResponse, error)
+	ReportException(taskId, runId string, payload *TaskExceptionRequest) (*TaskStatusResponse, error)
+	ReportExceptionWithContext(ctx context.Context, taskId, runId string, payload *TaskExceptionRequest) (*TaskStatusResponse, error)
+	CreateArtifact(taskId, runId, name string, payload *PostArtifactRequest) (*PostArtifactResponse, error)
+	CreateArtifactWithContext(ctx context.Context, taskId, runId, name string, payload *PostArtifactRequest) (*PostArtifactResponse, error)
+	CompleteArtifact(taskId, runId, name string, payload *CompleteArtifactRequest) error
+	CompleteArtifactWithContext(ctx context.Context, taskId, runId, name string, payload *CompleteArtifactRequest) error
+	GetArtifact(taskId, runId, name string) error
+	GetArtifactWithContext(ctx context.Context, taskId, runId, name string) error
+	GetArtifact_SignedURL(taskId, runId, name string, duration time.Duration) (*url.URL, error)
+	GetLatestArtifact(taskId, name string) error
+	GetLatestArtifactWithContext(ctx context.Context, taskId, name string) error
+	GetLatestArtifact_SignedURL(taskId, name string, duration time.Duration) (*url.URL, error)
+	ListArtifacts(taskId, runId, continuationToken, limit string) (*ListArtifactsResponse, error)
+	ListArtifactsWithContext(ctx context.Context, taskId, runId, continuationToken, limit string) (*ListArtifactsResponse, error)
+	ListArtifactsAll(ctx context.Context, taskId, runId string) *ArtifactIterator
+	ListLatestArtifacts(taskId, continuationToken, limit string) (*ListArtifactsResponse, error)
+	ListLatestArtifactsWithContext(ctx context.Context, taskId, continuationToken, limit string) (*ListArtifactsResponse, error)
+	ListLatestArtifactsAll(ctx context.Context, taskId string) *ArtifactIterator
+	ListProvisioners(continuationToken, limit string) (*ListProvisionersResponse, error)
+	ListProvisionersWithContext(ctx context.Context, continuationToken, limit string) (*ListProvisionersResponse, error)
+	ListProvisionersAll(ctx context.Context) *ProvisionerInformationIterator
+	GetProvisioner(provisionerId string) (*ProvisionerResponse, error)
+	GetProvisionerWithContext(ctx context.Context, provisionerId string) (*ProvisionerResponse, error)
+	DeclareProvisioner(provisionerId string, payload *ProvisionerRequest) (*ProvisionerResponse, error)
+	DeclareProvisionerWithContext(ctx context.Context, provisionerId string, payload *ProvisionerRequest) (*ProvisionerResponse, error)
+	PendingTasks(provisionerId, workerType string) (*CountPendingTasksResponse, error)
+	PendingTasksWithContext(ctx context.Context, provisionerId, workerType string) (*CountPendingTasksResponse, error)
+	ListWorkerTypes(provisionerId, continuationToken, limit string) (*ListWorkerTypesResponse, error)
+	ListWorkerTypesWithContext(ctx context.Context, provisionerId, continuationToken, limit string) (*ListWorkerTypesResponse, error)
+	ListWorkerTypesAll(ctx context.Context, provisionerId string) *WorkerTypeIterator
+	GetWorkerType(provisionerId, workerType string) (*WorkerTypeResponse, error)
+	GetWorkerTypeWithContext(ctx context.Context, provisionerId, workerType string) (*WorkerTypeResponse, error)
+	DeclareWorkerType(provisionerId, workerType string, payload *WorkerTypeRequest) (*WorkerTypeResponse, error)
+	DeclareWorkerTypeWithContext(ctx context.Context, provisionerId, workerType string, payload *WorkerTypeRequest) (*WorkerTypeResponse, error)
+	ListWorkers(provisionerId, workerType, continuationToken, limit, quarantined string) (*ListWorkersResponse, error)
+	ListWorkersWithContext(ctx context.Context, provisionerId, workerType, continuationToken, limit, quarantined string) (*ListWorkersResponse, error)
+	ListWorkersAll(ctx context.Context, provisionerId, workerType, quarantined string) *WorkerIterator
+	GetWorker(provisionerId, workerType, workerGroup, workerId string) (*WorkerResponse, error)
+	GetWorkerWithContext(ctx context.Context, provisionerId, workerType, workerGroup, workerId string) (*WorkerResponse, error)
+	QuarantineWorker(provisionerId, workerType, workerGroup, workerId string, payload *QuarantineWorkerRequest) (*WorkerResponse, error)
+	QuarantineWorkerWithContext(ctx context.Context, provisionerId, workerType, workerGroup, workerId string, payload *QuarantineWorkerRequest) (*WorkerResponse, error)
+	DeclareWorker(provisionerId, workerType, workerGroup, workerId string, payload *WorkerRequest) (*WorkerResponse, error)
+	DeclareWorkerWithContext(ctx context.Context, provisionerId, workerType, workerGroup, workerId string, payload *WorkerRequest) (*WorkerResponse, error)
+}
+
+var _ API = (*Queue)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (queue *Queue) Ping() error {
+	return queue.PingWithContext(queue.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See Ping for more details.
+func (queue *Queue) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*queue)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -112,8 +224,16 @@ func (queue *Queue) Ping() error {
 //
 // See #task
 func (queue *Queue) Task(taskId string) (*TaskDefinitionResponse, error) {
+	return queue.TaskWithContext(queue.Context, taskId)
+}
+
+// TaskWithContext is the same as Task, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See Task for more details.
+func (queue *Queue) TaskWithContext(ctx context.Context, taskId string) (*TaskDefinitionResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(taskId), new(TaskDefinitionResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "task"), nil, "GET", "/task/"+url.QueryEscape(taskId), new(TaskDefinitionResponse), nil)
 	return responseObject.(*TaskDefinitionResponse), err
 }
 
@@ -121,8 +241,16 @@ func (queue *Queue) Task(taskId string) (*TaskDefinitionResponse, error) {
 //
 // See #status
 func (queue *Queue) Status(taskId string) (*TaskStatusResponse, error) {
+	return queue.StatusWithContext(queue.Context, taskId)
+}
+
+// StatusWithContext is the same as Status, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See Status for more details.
+func (queue *Queue) StatusWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(taskId)+"/status", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "status"), nil, "GET", "/task/"+url.QueryEscape(taskId)+"/status", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -145,6 +273,14 @@ func (queue *Queue) Status(taskId string) (*TaskStatusResponse, error) {
 //
 // See #listTaskGroup
 func (queue *Queue) ListTaskGroup(taskGroupId, continuationToken, limit string) (*ListTaskGroupResponse, error) {
+	return queue.ListTaskGroupWithContext(queue.Context, taskGroupId, continuationToken, limit)
+}
+
+// ListTaskGroupWithContext is the same as ListTaskGroup, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ListTaskGroup for more details.
+func (queue *Queue) ListTaskGroupWithContext(ctx context.Context, taskGroupId, continuationToken, limit string) (*ListTaskGroupResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -153,10 +289,27 @@ func (queue *Queue) ListTaskGroup(taskGroupId, continuationToken, limit string)
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/task-group/"+url.QueryEscape(taskGroupId)+"/list", new(ListTaskGroupResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "listTaskGroup"), nil, "GET", "/task-group/"+url.QueryEscape(taskGroupId)+"/list", new(ListTaskGroupResponse), v)
 	return responseObject.(*ListTaskGroupResponse), err
 }
 
+// ListTaskGroupAll returns an iterator over the TaskDefinitionAndStatus items of all
+// pages of ListTaskGroup results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListTaskGroup for more details.
+func (queue *Queue) ListTaskGroupAll(ctx context.Context, taskGroupId string) *TaskDefinitionAndStatusIterator {
+	return NewTaskDefinitionAndStatusIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]TaskDefinitionAndStatus, string, error) {
+		page, err := queue.ListTaskGroupWithContext(ctx, taskGroupId, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Tasks, page.ContinuationToken, nil
+	})
+}
+
 // List tasks that depend on the given `taskId`.
 //
 // As many tasks from different task-groups may dependent on a single tasks,
@@ -176,6 +329,14 @@ func (queue *Queue) ListTaskGroup(taskGroupId, continuationToken, limit string)
 //
 // See #listDependentTasks
 func (queue *Queue) ListDependentTasks(taskId, continuationToken, limit string) (*ListDependentTasksResponse, error) {
+	return queue.ListDependentTasksWithContext(queue.Context, taskId, continuationToken, limit)
+}
+
+// ListDependentTasksWithContext is the same as ListDependentTasks, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ListDependentTasks for more details.
+func (queue *Queue) ListDependentTasksWithContext(ctx context.Context, taskId, continuationToken, limit string) (*ListDependentTasksResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -184,10 +345,27 @@ func (queue *Queue) ListDependentTasks(taskId, continuationToken, limit string)
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(taskId)+"/dependents", new(ListDependentTasksResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "listDependentTasks"), nil, "GET", "/task/"+url.QueryEscape(taskId)+"/dependents", new(ListDependentTasksResponse), v)
 	return responseObject.(*ListDependentTasksResponse), err
 }
 
+// ListDependentTasksAll returns an iterator over the TaskDefinitionAndStatus items of all
+// pages of ListDependentTasks results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListDependentTasks for more details.
+func (queue *Queue) ListDependentTasksAll(ctx context.Context, taskId string) *TaskDefinitionAndStatusIterator {
+	return NewTaskDefinitionAndStatusIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]TaskDefinitionAndStatus, string, error) {
+		page, err := queue.ListDependentTasksWithContext(ctx, taskId, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Tasks, page.ContinuationToken, nil
+	})
+}
+
 // Create a new task, this is an **idempotent** operation, so repeat it if
 // you get an internal server error or network connection is dropped.
 //
@@ -237,8 +415,16 @@ func (queue *Queue) ListDependentTasks(taskId, continuationToken, limit string)
 //
 // See #createTask
 func (queue *Queue) CreateTask(taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error) {
+	return queue.CreateTaskWithContext(queue.Context, taskId, payload)
+}
+
+// CreateTaskWithContext is the same as CreateTask, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See CreateTask for more details.
+func (queue *Queue) CreateTaskWithContext(ctx context.Context, taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/task/"+url.QueryEscape(taskId), new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "createTask"), payload, "PUT", "/task/"+url.QueryEscape(taskId), new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -265,8 +451,16 @@ func (queue *Queue) CreateTask(taskId string, payload *TaskDefinitionRequest) (*
 //
 // See #defineTask
 func (queue *Queue) DefineTask(taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error) {
+	return queue.DefineTaskWithContext(queue.Context, taskId, payload)
+}
+
+// DefineTaskWithContext is the same as DefineTask, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See DefineTask for more details.
+func (queue *Queue) DefineTaskWithContext(ctx context.Context, taskId string, payload *TaskDefinitionRequest) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/task/"+url.QueryEscape(taskId)+"/define", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "defineTask"), payload, "POST", "/task/"+url.QueryEscape(taskId)+"/define", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -294,8 +488,16 @@ func (queue *Queue) DefineTask(taskId string, payload *TaskDefinitionRequest) (*
 //
 // See #scheduleTask
 func (queue *Queue) ScheduleTask(taskId string) (*TaskStatusResponse, error) {
+	return queue.ScheduleTaskWithContext(queue.Context, taskId)
+}
+
+// ScheduleTaskWithContext is the same as ScheduleTask, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ScheduleTask for more details.
+func (queue *Queue) ScheduleTaskWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/task/"+url.QueryEscape(taskId)+"/schedule", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "scheduleTask"), nil, "POST", "/task/"+url.QueryEscape(taskId)+"/schedule", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -326,8 +528,16 @@ func (queue *Queue) ScheduleTask(taskId string) (*TaskStatusResponse, error) {
 //
 // See #rerunTask
 func (queue *Queue) RerunTask(taskId string) (*TaskStatusResponse, error) {
+	return queue.RerunTaskWithContext(queue.Context, taskId)
+}
+
+// RerunTaskWithContext is the same as RerunTask, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See RerunTask for more details.
+func (queue *Queue) RerunTaskWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/task/"+url.QueryEscape(taskId)+"/rerun", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "rerunTask"), nil, "POST", "/task/"+url.QueryEscape(taskId)+"/rerun", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -353,8 +563,16 @@ func (queue *Queue) RerunTask(taskId string) (*TaskStatusResponse, error) {
 //
 // See #cancelTask
 func (queue *Queue) CancelTask(taskId string) (*TaskStatusResponse, error) {
+	return queue.CancelTaskWithContext(queue.Context, taskId)
+}
+
+// CancelTaskWithContext is the same as CancelTask, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See CancelTask for more details.
+func (queue *Queue) CancelTaskWithContext(ctx context.Context, taskId string) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/task/"+url.QueryEscape(taskId)+"/cancel", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "cancelTask"), nil, "POST", "/task/"+url.QueryEscape(taskId)+"/cancel", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -374,8 +592,16 @@ func (queue *Queue) CancelTask(taskId string) (*TaskStatusResponse, error) {
 //
 // See #claimWork
 func (queue *Queue) ClaimWork(provisionerId, workerType string, payload *ClaimWorkRequest) (*ClaimWorkResponse, error) {
+	return queue.ClaimWorkWithContext(queue.Context, provisionerId, workerType, payload)
+}
+
+// ClaimWorkWithContext is the same as ClaimWork, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ClaimWork for more details.
+func (queue *Queue) ClaimWorkWithContext(ctx context.Context, provisionerId, workerType string, payload *ClaimWorkRequest) (*ClaimWorkResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/claim-work/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), new(ClaimWorkResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "claimWork"), payload, "POST", "/claim-work/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), new(ClaimWorkResponse), nil)
 	return responseObject.(*ClaimWorkResponse), err
 }
 
@@ -395,8 +621,16 @@ func (queue *Queue) ClaimWork(provisionerId, workerType string, payload *ClaimWo
 //
 // See #claimTask
 func (queue *Queue) ClaimTask(taskId, runId string, payload *TaskClaimRequest) (*TaskClaimResponse, error) {
+	return queue.ClaimTaskWithContext(queue.Context, taskId, runId, payload)
+}
+
+// ClaimTaskWithContext is the same as ClaimTask, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ClaimTask for more details.
+func (queue *Queue) ClaimTaskWithContext(ctx context.Context, taskId, runId string, payload *TaskClaimRequest) (*TaskClaimResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/claim", new(TaskClaimResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "claimTask"), payload, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/claim", new(TaskClaimResponse), nil)
 	return responseObject.(*TaskClaimResponse), err
 }
 
@@ -431,8 +665,16 @@ func (queue *Queue) ClaimTask(taskId, runId string, payload *TaskClaimRequest) (
 //
 // See #reclaimTask
 func (queue *Queue) ReclaimTask(taskId, runId string) (*TaskReclaimResponse, error) {
+	return queue.ReclaimTaskWithContext(queue.Context, taskId, runId)
+}
+
+// ReclaimTaskWithContext is the same as ReclaimTask, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ReclaimTask for more details.
+func (queue *Queue) ReclaimTaskWithContext(ctx context.Context, taskId, runId string) (*TaskReclaimResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/reclaim", new(TaskReclaimResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "reclaimTask"), nil, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/reclaim", new(TaskReclaimResponse), nil)
 	return responseObject.(*TaskReclaimResponse), err
 }
 
@@ -447,8 +689,16 @@ func (queue *Queue) ReclaimTask(taskId, runId string) (*TaskReclaimResponse, err
 //
 // See #reportCompleted
 func (queue *Queue) ReportCompleted(taskId, runId string) (*TaskStatusResponse, error) {
+	return queue.ReportCompletedWithContext(queue.Context, taskId, runId)
+}
+
+// ReportCompletedWithContext is the same as ReportCompleted, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ReportCompleted for more details.
+func (queue *Queue) ReportCompletedWithContext(ctx context.Context, taskId, runId string) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/completed", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "reportCompleted"), nil, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/completed", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -469,8 +719,16 @@ func (queue *Queue) ReportCompleted(taskId, runId string) (*TaskStatusResponse,
 //
 // See #reportFailed
 func (queue *Queue) ReportFailed(taskId, runId string) (*TaskStatusResponse, error) {
+	return queue.ReportFailedWithContext(queue.Context, taskId, runId)
+}
+
+// ReportFailedWithContext is the same as ReportFailed, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ReportFailed for more details.
+func (queue *Queue) ReportFailedWithContext(ctx context.Context, taskId, runId string) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/failed", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "reportFailed"), nil, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/failed", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -497,8 +755,16 @@ func (queue *Queue) ReportFailed(taskId, runId string) (*TaskStatusResponse, err
 //
 // See #reportException
 func (queue *Queue) ReportException(taskId, runId string, payload *TaskExceptionRequest) (*TaskStatusResponse, error) {
+	return queue.ReportExceptionWithContext(queue.Context, taskId, runId, payload)
+}
+
+// ReportExceptionWithContext is the same as ReportException, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ReportException for more details.
+func (queue *Queue) ReportExceptionWithContext(ctx context.Context, taskId, runId string, payload *TaskExceptionRequest) (*TaskStatusResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/exception", new(TaskStatusResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "reportException"), payload, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/exception", new(TaskStatusResponse), nil)
 	return responseObject.(*TaskStatusResponse), err
 }
 
@@ -581,8 +847,16 @@ func (queue *Queue) ReportException(taskId, runId string, payload *TaskException
 //
 // See #createArtifact
 func (queue *Queue) CreateArtifact(taskId, runId, name string, payload *PostArtifactRequest) (*PostArtifactResponse, error) {
+	return queue.CreateArtifactWithContext(queue.Context, taskId, runId, name, payload)
+}
+
+// CreateArtifactWithContext is the same as CreateArtifact, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See CreateArtifact for more details.
+func (queue *Queue) CreateArtifactWithContext(ctx context.Context, taskId, runId, name string, payload *PostArtifactRequest) (*PostArtifactResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts/"+url.QueryEscape(name), new(PostArtifactResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "createArtifact"), payload, "POST", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts/"+url.QueryEscape(name), new(PostArtifactResponse), nil)
 	return responseObject.(*PostArtifactResponse), err
 }
 
@@ -607,8 +881,16 @@ func (queue *Queue) CreateArtifact(taskId, runId, name string, payload *PostArti
 //
 // See #completeArtifact
 func (queue *Queue) CompleteArtifact(taskId, runId, name string, payload *CompleteArtifactRequest) error {
+	return queue.CompleteArtifactWithContext(queue.Context, taskId, runId, name, payload)
+}
+
+// CompleteArtifactWithContext is the same as CompleteArtifact, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See CompleteArtifact for more details.
+func (queue *Queue) CompleteArtifactWithContext(ctx context.Context, taskId, runId, name string, payload *CompleteArtifactRequest) error {
 	cd := tcclient.Client(*queue)
-	_, _, err := (&cd).APICall(payload, "PUT", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "completeArtifact"), payload, "PUT", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -697,8 +979,16 @@ func (queue *Queue) CompleteArtifact(taskId, runId, name string, payload *Comple
 //
 // See #getArtifact
 func (queue *Queue) GetArtifact(taskId, runId, name string) error {
+	return queue.GetArtifactWithContext(queue.Context, taskId, runId, name)
+}
+
+// GetArtifactWithContext is the same as GetArtifact, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See GetArtifact for more details.
+func (queue *Queue) GetArtifactWithContext(ctx context.Context, taskId, runId, name string) error {
 	cd := tcclient.Client(*queue)
-	_, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "getArtifact"), nil, "GET", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -736,8 +1026,16 @@ func (queue *Queue) GetArtifact_SignedURL(taskId, runId, name string, duration t
 //
 // See #getLatestArtifact
 func (queue *Queue) GetLatestArtifact(taskId, name string) error {
+	return queue.GetLatestArtifactWithContext(queue.Context, taskId, name)
+}
+
+// GetLatestArtifactWithContext is the same as GetLatestArtifact, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See GetLatestArtifact for more details.
+func (queue *Queue) GetLatestArtifactWithContext(ctx context.Context, taskId, name string) error {
 	cd := tcclient.Client(*queue)
-	_, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(taskId)+"/artifacts/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "getLatestArtifact"), nil, "GET", "/task/"+url.QueryEscape(taskId)+"/artifacts/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -767,6 +1065,14 @@ func (queue *Queue) GetLatestArtifact_SignedURL(taskId, name string, duration ti
 //
 // See #listArtifacts
 func (queue *Queue) ListArtifacts(taskId, runId, continuationToken, limit string) (*ListArtifactsResponse, error) {
+	return queue.ListArtifactsWithContext(queue.Context, taskId, runId, continuationToken, limit)
+}
+
+// ListArtifactsWithContext is the same as ListArtifacts, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ListArtifacts for more details.
+func (queue *Queue) ListArtifactsWithContext(ctx context.Context, taskId, runId, continuationToken, limit string) (*ListArtifactsResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -775,10 +1081,27 @@ func (queue *Queue) ListArtifacts(taskId, runId, continuationToken, limit string
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts", new(ListArtifactsResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "listArtifacts"), nil, "GET", "/task/"+url.QueryEscape(taskId)+"/runs/"+url.QueryEscape(runId)+"/artifacts", new(ListArtifactsResponse), v)
 	return responseObject.(*ListArtifactsResponse), err
 }
 
+// ListArtifactsAll returns an iterator over the Artifact items of all
+// pages of ListArtifacts results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListArtifacts for more details.
+func (queue *Queue) ListArtifactsAll(ctx context.Context, taskId, runId string) *ArtifactIterator {
+	return NewArtifactIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Artifact, string, error) {
+		page, err := queue.ListArtifactsWithContext(ctx, taskId, runId, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Artifacts, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Returns a list of artifacts and associated meta-data for the latest run
@@ -794,6 +1117,14 @@ func (queue *Queue) ListArtifacts(taskId, runId, continuationToken, limit string
 //
 // See #listLatestArtifacts
 func (queue *Queue) ListLatestArtifacts(taskId, continuationToken, limit string) (*ListArtifactsResponse, error) {
+	return queue.ListLatestArtifactsWithContext(queue.Context, taskId, continuationToken, limit)
+}
+
+// ListLatestArtifactsWithContext is the same as ListLatestArtifacts, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ListLatestArtifacts for more details.
+func (queue *Queue) ListLatestArtifactsWithContext(ctx context.Context, taskId, continuationToken, limit string) (*ListArtifactsResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -802,10 +1133,27 @@ func (queue *Queue) ListLatestArtifacts(taskId, continuationToken, limit string)
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/task/"+url.QueryEscape(taskId)+"/artifacts", new(ListArtifactsResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "listLatestArtifacts"), nil, "GET", "/task/"+url.QueryEscape(taskId)+"/artifacts", new(ListArtifactsResponse), v)
 	return responseObject.(*ListArtifactsResponse), err
 }
 
+// ListLatestArtifactsAll returns an iterator over the Artifact items of all
+// pages of ListLatestArtifacts results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListLatestArtifacts for more details.
+func (queue *Queue) ListLatestArtifactsAll(ctx context.Context, taskId string) *ArtifactIterator {
+	return NewArtifactIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Artifact, string, error) {
+		page, err := queue.ListLatestArtifactsWithContext(ctx, taskId, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Artifacts, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Get all active provisioners.
@@ -821,6 +1169,14 @@ func (queue *Queue) ListLatestArtifacts(taskId, continuationToken, limit string)
 //
 // See #listProvisioners
 func (queue *Queue) ListProvisioners(continuationToken, limit string) (*ListProvisionersResponse, error) {
+	return queue.ListProvisionersWithContext(queue.Context, continuationToken, limit)
+}
+
+// ListProvisionersWithContext is the same as ListProvisioners, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ListProvisioners for more details.
+func (queue *Queue) ListProvisionersWithContext(ctx context.Context, continuationToken, limit string) (*ListProvisionersResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -829,10 +1185,27 @@ func (queue *Queue) ListProvisioners(continuationToken, limit string) (*ListProv
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/provisioners", new(ListProvisionersResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "listProvisioners"), nil, "GET", "/provisioners", new(ListProvisionersResponse), v)
 	return responseObject.(*ListProvisionersResponse), err
 }
 
+// ListProvisionersAll returns an iterator over the ProvisionerInformation items of all
+// pages of ListProvisioners results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListProvisioners for more details.
+func (queue *Queue) ListProvisionersAll(ctx context.Context) *ProvisionerInformationIterator {
+	return NewProvisionerInformationIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]ProvisionerInformation, string, error) {
+		page, err := queue.ListProvisionersWithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Provisioners, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Get an active provisioner.
@@ -843,8 +1216,16 @@ func (queue *Queue) ListProvisioners(continuationToken, limit string) (*ListProv
 //
 // See #getProvisioner
 func (queue *Queue) GetProvisioner(provisionerId string) (*ProvisionerResponse, error) {
+	return queue.GetProvisionerWithContext(queue.Context, provisionerId)
+}
+
+// GetProvisionerWithContext is the same as GetProvisioner, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See GetProvisioner for more details.
+func (queue *Queue) GetProvisionerWithContext(ctx context.Context, provisionerId string) (*ProvisionerResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId), new(ProvisionerResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "getProvisioner"), nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId), new(ProvisionerResponse), nil)
 	return responseObject.(*ProvisionerResponse), err
 }
 
@@ -866,8 +1247,16 @@ func (queue *Queue) GetProvisioner(provisionerId string) (*ProvisionerResponse,
 //
 // See #declareProvisioner
 func (queue *Queue) DeclareProvisioner(provisionerId string, payload *ProvisionerRequest) (*ProvisionerResponse, error) {
+	return queue.DeclareProvisionerWithContext(queue.Context, provisionerId, payload)
+}
+
+// DeclareProvisionerWithContext is the same as DeclareProvisioner, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See DeclareProvisioner for more details.
+func (queue *Queue) DeclareProvisionerWithContext(ctx context.Context, provisionerId string, payload *ProvisionerRequest) (*ProvisionerResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId), new(ProvisionerResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "declareProvisioner"), payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId), new(ProvisionerResponse), nil)
 	return responseObject.(*ProvisionerResponse), err
 }
 
@@ -881,8 +1270,16 @@ func (queue *Queue) DeclareProvisioner(provisionerId string, payload *Provisione
 //
 // See #pendingTasks
 func (queue *Queue) PendingTasks(provisionerId, workerType string) (*CountPendingTasksResponse, error) {
+	return queue.PendingTasksWithContext(queue.Context, provisionerId, workerType)
+}
+
+// PendingTasksWithContext is the same as PendingTasks, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See PendingTasks for more details.
+func (queue *Queue) PendingTasksWithContext(ctx context.Context, provisionerId, workerType string) (*CountPendingTasksResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/pending/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), new(CountPendingTasksResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "pendingTasks"), nil, "GET", "/pending/"+url.QueryEscape(provisionerId)+"/"+url.QueryEscape(workerType), new(CountPendingTasksResponse), nil)
 	return responseObject.(*CountPendingTasksResponse), err
 }
 
@@ -897,6 +1294,14 @@ func (queue *Queue) PendingTasks(provisionerId, workerType string) (*CountPendin
 //
 // See #listWorkerTypes
 func (queue *Queue) ListWorkerTypes(provisionerId, continuationToken, limit string) (*ListWorkerTypesResponse, error) {
+	return queue.ListWorkerTypesWithContext(queue.Context, provisionerId, continuationToken, limit)
+}
+
+// ListWorkerTypesWithContext is the same as ListWorkerTypes, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ListWorkerTypes for more details.
+func (queue *Queue) ListWorkerTypesWithContext(ctx context.Context, provisionerId, continuationToken, limit string) (*ListWorkerTypesResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -905,18 +1310,43 @@ func (queue *Queue) ListWorkerTypes(provisionerId, continuationToken, limit stri
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types", new(ListWorkerTypesResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "listWorkerTypes"), nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types", new(ListWorkerTypesResponse), v)
 	return responseObject.(*ListWorkerTypesResponse), err
 }
 
+// ListWorkerTypesAll returns an iterator over the WorkerType items of all
+// pages of ListWorkerTypes results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListWorkerTypes for more details.
+func (queue *Queue) ListWorkerTypesAll(ctx context.Context, provisionerId string) *WorkerTypeIterator {
+	return NewWorkerTypeIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]WorkerType, string, error) {
+		page, err := queue.ListWorkerTypesWithContext(ctx, provisionerId, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.WorkerTypes, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Get a worker-type from a provisioner.
 //
 // See #getWorkerType
 func (queue *Queue) GetWorkerType(provisionerId, workerType string) (*WorkerTypeResponse, error) {
+	return queue.GetWorkerTypeWithContext(queue.Context, provisionerId, workerType)
+}
+
+// GetWorkerTypeWithContext is the same as GetWorkerType, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See GetWorkerType for more details.
+func (queue *Queue) GetWorkerTypeWithContext(ctx context.Context, provisionerId, workerType string) (*WorkerTypeResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "getWorkerType"), nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
 	return responseObject.(*WorkerTypeResponse), err
 }
 
@@ -934,8 +1364,16 @@ func (queue *Queue) GetWorkerType(provisionerId, workerType string) (*WorkerType
 //
 // See #declareWorkerType
 func (queue *Queue) DeclareWorkerType(provisionerId, workerType string, payload *WorkerTypeRequest) (*WorkerTypeResponse, error) {
+	return queue.DeclareWorkerTypeWithContext(queue.Context, provisionerId, workerType, payload)
+}
+
+// DeclareWorkerTypeWithContext is the same as DeclareWorkerType, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See DeclareWorkerType for more details.
+func (queue *Queue) DeclareWorkerTypeWithContext(ctx context.Context, provisionerId, workerType string, payload *WorkerTypeRequest) (*WorkerTypeResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "declareWorkerType"), payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType), new(WorkerTypeResponse), nil)
 	return responseObject.(*WorkerTypeResponse), err
 }
 
@@ -954,6 +1392,14 @@ func (queue *Queue) DeclareWorkerType(provisionerId, workerType string, payload
 //
 // See #listWorkers
 func (queue *Queue) ListWorkers(provisionerId, workerType, continuationToken, limit, quarantined string) (*ListWorkersResponse, error) {
+	return queue.ListWorkersWithContext(queue.Context, provisionerId, workerType, continuationToken, limit, quarantined)
+}
+
+// ListWorkersWithContext is the same as ListWorkers, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See ListWorkers for more details.
+func (queue *Queue) ListWorkersWithContext(ctx context.Context, provisionerId, workerType, continuationToken, limit, quarantined string) (*ListWorkersResponse, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -965,18 +1411,43 @@ func (queue *Queue) ListWorkers(provisionerId, workerType, continuationToken, li
 		v.Add("quarantined", quarantined)
 	}
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/workers", new(ListWorkersResponse), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "listWorkers"), nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/workers", new(ListWorkersResponse), v)
 	return responseObject.(*ListWorkersResponse), err
 }
 
+// ListWorkersAll returns an iterator over the Worker items of all
+// pages of ListWorkers results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListWorkers for more details.
+func (queue *Queue) ListWorkersAll(ctx context.Context, provisionerId, workerType, quarantined string) *WorkerIterator {
+	return NewWorkerIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Worker, string, error) {
+		page, err := queue.ListWorkersWithContext(ctx, provisionerId, workerType, continuationToken, limit, quarantined)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Workers, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Get a worker from a worker-type.
 //
 // See #getWorker
 func (queue *Queue) GetWorker(provisionerId, workerType, workerGroup, workerId string) (*WorkerResponse, error) {
+	return queue.GetWorkerWithContext(queue.Context, provisionerId, workerType, workerGroup, workerId)
+}
+
+// GetWorkerWithContext is the same as GetWorker, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See GetWorker for more details.
+func (queue *Queue) GetWorkerWithContext(ctx context.Context, provisionerId, workerType, workerGroup, workerId string) (*WorkerResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/workers/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "getWorker"), nil, "GET", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/workers/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerResponse), nil)
 	return responseObject.(*WorkerResponse), err
 }
 
@@ -989,8 +1460,16 @@ func (queue *Queue) GetWorker(provisionerId, workerType, workerGroup, workerId s
 //
 // See #quarantineWorker
 func (queue *Queue) QuarantineWorker(provisionerId, workerType, workerGroup, workerId string, payload *QuarantineWorkerRequest) (*WorkerResponse, error) {
+	return queue.QuarantineWorkerWithContext(queue.Context, provisionerId, workerType, workerGroup, workerId, payload)
+}
+
+// QuarantineWorkerWithContext is the same as QuarantineWorker, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See QuarantineWorker for more details.
+func (queue *Queue) QuarantineWorkerWithContext(ctx context.Context, provisionerId, workerType, workerGroup, workerId string, payload *QuarantineWorkerRequest) (*WorkerResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/workers/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "quarantineWorker"), payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/workers/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerResponse), nil)
 	return responseObject.(*WorkerResponse), err
 }
 
@@ -1006,7 +1485,314 @@ func (queue *Queue) QuarantineWorker(provisionerId, workerType, workerGroup, wor
 //
 // See #declareWorker
 func (queue *Queue) DeclareWorker(provisionerId, workerType, workerGroup, workerId string, payload *WorkerRequest) (*WorkerResponse, error) {
+	return queue.DeclareWorkerWithContext(queue.Context, provisionerId, workerType, workerGroup, workerId, payload)
+}
+
+// DeclareWorkerWithContext is the same as DeclareWorker, except that the
+// request is bound to ctx rather than to queue.Context.
+//
+// See DeclareWorker for more details.
+func (queue *Queue) DeclareWorkerWithContext(ctx context.Context, provisionerId, workerType, workerGroup, workerId string, payload *WorkerRequest) (*WorkerResponse, error) {
 	cd := tcclient.Client(*queue)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "queue", "declareWorker"), payload, "PUT", "/provisioners/"+url.QueryEscape(provisionerId)+"/worker-types/"+url.QueryEscape(workerType)+"/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerResponse), nil)
 	return responseObject.(*WorkerResponse), err
 }
+
+// TaskDefinitionAndStatusIterator iterates over the TaskDefinitionAndStatus items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type TaskDefinitionAndStatusIterator struct {
+	*tcclient.Pager
+	items []TaskDefinitionAndStatus
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *TaskDefinitionAndStatusIterator) Item() TaskDefinitionAndStatus {
+	return it.items[it.Index()]
+}
+
+// NewTaskDefinitionAndStatusIterator returns a TaskDefinitionAndStatusIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewTaskDefinitionAndStatusIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]TaskDefinitionAndStatus, string, error)) *TaskDefinitionAndStatusIterator {
+	it := new(TaskDefinitionAndStatusIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// ArtifactIterator iterates over the Artifact items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type ArtifactIterator struct {
+	*tcclient.Pager
+	items []Artifact
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *ArtifactIterator) Item() Artifact {
+	return it.items[it.Index()]
+}
+
+// NewArtifactIterator returns an ArtifactIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewArtifactIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]Artifact, string, error)) *ArtifactIterator {
+	it := new(ArtifactIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// ProvisionerInformationIterator iterates over the ProvisionerInformation items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type ProvisionerInformationIterator struct {
+	*tcclient.Pager
+	items []ProvisionerInformation
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *ProvisionerInformationIterator) Item() ProvisionerInformation {
+	return it.items[it.Index()]
+}
+
+// NewProvisionerInformationIterator returns a ProvisionerInformationIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewProvisionerInformationIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]ProvisionerInformation, string, error)) *ProvisionerInformationIterator {
+	it := new(ProvisionerInformationIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// WorkerTypeIterator iterates over the WorkerType items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type WorkerTypeIterator struct {
+	*tcclient.Pager
+	items []WorkerType
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *WorkerTypeIterator) Item() WorkerType {
+	return it.items[it.Index()]
+}
+
+// NewWorkerTypeIterator returns a WorkerTypeIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewWorkerTypeIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]WorkerType, string, error)) *WorkerTypeIterator {
+	it := new(WorkerTypeIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// WorkerIterator iterates over the Worker items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type WorkerIterator struct {
+	*tcclient.Pager
+	items []Worker
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *WorkerIterator) Item() Worker {
+	return it.items[it.Index()]
+}
+
+// NewWorkerIterator returns a WorkerIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewWorkerIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]Worker, string, error)) *WorkerIterator {
+	it := new(WorkerIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"createTask": scopes.AllOf{
+		scopes.ForEachIn{For: "scope", In: "scopes", Each: "<scope>"},
+		scopes.ForEachIn{For: "route", In: "routes", Each: "queue:route:<route>"},
+		scopes.AnyOf{
+			scopes.AllOf{
+				scopes.RequiredScope("queue:scheduler-id:<schedulerId>"),
+				scopes.ForEachIn{For: "priority", In: "priorities", Each: "queue:create-task:<priority>:<provisionerId>/<workerType>"},
+			},
+			scopes.IfThen{
+				If: "legacyScopes",
+				Then: scopes.AnyOf{
+					scopes.RequiredScope("queue:create-task:<provisionerId>/<workerType>"),
+					scopes.AllOf{
+						scopes.RequiredScope("queue:define-task:<provisionerId>/<workerType>"),
+						scopes.RequiredScope("queue:task-group-id:<schedulerId>/<taskGroupId>"),
+						scopes.RequiredScope("queue:schedule-task:<schedulerId>/<taskGroupId>/<taskId>"),
+					},
+				},
+			},
+		},
+	},
+	"defineTask": scopes.AllOf{
+		scopes.ForEachIn{For: "scope", In: "scopes", Each: "<scope>"},
+		scopes.ForEachIn{For: "route", In: "routes", Each: "queue:route:<route>"},
+		scopes.AnyOf{
+			scopes.AllOf{
+				scopes.RequiredScope("queue:scheduler-id:<schedulerId>"),
+				scopes.ForEachIn{For: "priority", In: "priorities", Each: "queue:create-task:<priority>:<provisionerId>/<workerType>"},
+			},
+			scopes.IfThen{
+				If: "legacyScopes",
+				Then: scopes.AnyOf{
+					scopes.RequiredScope("queue:define-task:<provisionerId>/<workerType>"),
+					scopes.RequiredScope("queue:create-task:<provisionerId>/<workerType>"),
+					scopes.AllOf{
+						scopes.RequiredScope("queue:define-task:<provisionerId>/<workerType>"),
+						scopes.RequiredScope("queue:task-group-id:<schedulerId>/<taskGroupId>"),
+					},
+				},
+			},
+		},
+	},
+	"scheduleTask": scopes.AnyOf{
+		scopes.RequiredScope("queue:schedule-task:<schedulerId>/<taskGroupId>/<taskId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:schedule-task"),
+			scopes.RequiredScope("assume:scheduler-id:<schedulerId>/<taskGroupId>"),
+		},
+	},
+	"rerunTask": scopes.AnyOf{
+		scopes.RequiredScope("queue:rerun-task:<schedulerId>/<taskGroupId>/<taskId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:rerun-task"),
+			scopes.RequiredScope("assume:scheduler-id:<schedulerId>/<taskGroupId>"),
+		},
+	},
+	"cancelTask": scopes.AnyOf{
+		scopes.RequiredScope("queue:cancel-task:<schedulerId>/<taskGroupId>/<taskId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:cancel-task"),
+			scopes.RequiredScope("assume:scheduler-id:<schedulerId>/<taskGroupId>"),
+		},
+	},
+	"claimWork": scopes.AllOf{
+		scopes.RequiredScope("queue:claim-work:<provisionerId>/<workerType>"),
+		scopes.RequiredScope("queue:worker-id:<workerGroup>/<workerId>"),
+	},
+	"claimTask": scopes.AnyOf{
+		scopes.AllOf{
+			scopes.RequiredScope("queue:claim-task:<provisionerId>/<workerType>"),
+			scopes.RequiredScope("queue:worker-id:<workerGroup>/<workerId>"),
+		},
+		scopes.AllOf{
+			scopes.RequiredScope("queue:claim-task"),
+			scopes.RequiredScope("assume:worker-type:<provisionerId>/<workerType>"),
+			scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+		},
+	},
+	"reclaimTask": scopes.AnyOf{
+		scopes.RequiredScope("queue:reclaim-task:<taskId>/<runId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:claim-task"),
+			scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+		},
+	},
+	"reportCompleted": scopes.AnyOf{
+		scopes.RequiredScope("queue:resolve-task:<taskId>/<runId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:resolve-task"),
+			scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+		},
+	},
+	"reportFailed": scopes.AnyOf{
+		scopes.RequiredScope("queue:resolve-task:<taskId>/<runId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:resolve-task"),
+			scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+		},
+	},
+	"reportException": scopes.AnyOf{
+		scopes.RequiredScope("queue:resolve-task:<taskId>/<runId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:resolve-task"),
+			scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+		},
+	},
+	"createArtifact": scopes.AnyOf{
+		scopes.RequiredScope("queue:create-artifact:<taskId>/<runId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:create-artifact:<name>"),
+			scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+		},
+	},
+	"completeArtifact": scopes.AnyOf{
+		scopes.RequiredScope("queue:create-artifact:<taskId>/<runId>"),
+		scopes.AllOf{
+			scopes.RequiredScope("queue:create-artifact:<name>"),
+			scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+		},
+	},
+	"getArtifact": scopes.IfThen{
+		If:   "private",
+		Then: scopes.RequiredScope("queue:get-artifact:<name>"),
+	},
+	"getLatestArtifact": scopes.IfThen{
+		If:   "private",
+		Then: scopes.RequiredScope("queue:get-artifact:<name>"),
+	},
+	"declareProvisioner": scopes.ForEachIn{For: "property", In: "properties", Each: "queue:declare-provisioner:<provisionerId>#<property>"},
+	"declareWorkerType":  scopes.ForEachIn{For: "property", In: "properties", Each: "queue:declare-worker-type:<provisionerId>/<workerType>#<property>"},
+	"quarantineWorker":   scopes.RequiredScope("queue:quarantine-worker:<provisionerId>/<workerType>/<workerGroup>/<workerId>"),
+	"declareWorker":      scopes.ForEachIn{For: "property", In: "properties", Each: "queue:declare-worker:<provisionerId>/<workerType>/<workerGroup>/<workerId>#<property>"},
+}
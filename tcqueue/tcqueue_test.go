@@ -0,0 +1,122 @@
+package tcqueue
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/taskcluster/taskcluster-client-go/scopes"
+)
+
+func TestNewFromProfile(t *testing.T) {
+	for _, name := range []string{"TASKCLUSTER_CONFIG", "TASKCLUSTER_PROFILE", "TASKCLUSTER_ROOT_URL", "TASKCLUSTER_PROXY_URL", "TASKCLUSTER_CLIENT_ID"} {
+		if value, set := os.LookupEnv(name); set {
+			defer os.Setenv(name, value)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Unsetenv(name)
+	}
+	os.Setenv("TASKCLUSTER_CONFIG", filepath.Join(os.TempDir(), "nonexistent-tc-config.yml"))
+
+	queue, err := NewFromProfile("")
+	if err == nil || !strings.Contains(err.Error(), "no root URL") {
+		t.Errorf("Expected an error when no root URL is configured, but got %v, %#v", err, queue)
+	}
+
+	os.Setenv("TASKCLUSTER_ROOT_URL", "https://tc.example.com")
+	queue, err = NewFromProfile("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "https://tc.example.com/api/queue/v1"; queue.BaseURL != expected || queue.Authenticate {
+		t.Errorf("Expected unauthenticated client with base URL %v, but got %#v", expected, queue)
+	}
+}
+
+func TestCreateTaskRequiredScopes(t *testing.T) {
+	params := scopes.Params{
+		"scopes":        []string{},
+		"routes":        []string{},
+		"schedulerId":   "-",
+		"priorities":    []string{"lowest"},
+		"provisionerId": "proj-x",
+		"workerType":    "ci",
+		"legacyScopes":  false,
+		"taskGroupId":   "tg",
+		"taskId":        "t",
+	}
+	result, err := scopes.Evaluate(RequiredScopes["createTask"], params, []string{"queue:route:*"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Authorized {
+		t.Errorf("Expected createTask without legacy scopes to require the scheduler-id and create-task scopes, but got %#v", result)
+	}
+}
+
+// fakeQueue implements API with canned pages of artifacts; calling any other
+// method panics
+type fakeQueue struct {
+	API
+	pages [][]Artifact
+}
+
+func (queue *fakeQueue) ListArtifactsAll(ctx context.Context, taskId, runId string) *ArtifactIterator {
+	return NewArtifactIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Artifact, string, error) {
+		page := 0
+		if continuationToken != "" {
+			page, _ = strconv.Atoi(continuationToken)
+		}
+		next := ""
+		if page+1 < len(queue.pages) {
+			next = strconv.Itoa(page + 1)
+		}
+		return queue.pages[page], next, nil
+	})
+}
+
+func TestFakeAPIIterator(t *testing.T) {
+	var queue API = &fakeQueue{
+		pages: [][]Artifact{
+			{{Name: "public/a"}, {Name: "public/b"}},
+			{},
+			{{Name: "public/c"}},
+		},
+	}
+	it := queue.ListArtifactsAll(context.Background(), "abc", "0")
+	names := []string{}
+	for it.Next() {
+		names = append(names, it.Item().Name)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"public/a", "public/b", "public/c"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected artifacts %v but got %v", expected, names)
+	}
+	if it.Pages() != 3 {
+		t.Errorf("Expected 3 pages to be fetched, but got %v", it.Pages())
+	}
+}
+
+func TestEnumMarshalJSON(t *testing.T) {
+	// request payload types reject invalid values
+	if _, err := json.Marshal(&TaskExceptionRequest{Reason: "worker-shutdwon"}); err == nil {
+		t.Error("Expected an error marshaling an invalid TaskExceptionRequestReason")
+	}
+	// response types marshal values added to the service later
+	data, err := json.Marshal(&TaskStatusStructure{State: "future-state"})
+	if err != nil {
+		t.Fatalf("Unexpected error marshaling a TaskStatusStructure: %v", err)
+	}
+	var status TaskStatusStructure
+	if err := json.Unmarshal(data, &status); err != nil || status.State != "future-state" {
+		t.Errorf("Expected state to survive a round trip, but got %q, %v", status.State, err)
+	}
+}
@@ -5,6 +5,7 @@ package tcqueue
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
 )
@@ -58,7 +59,7 @@ type (
 		//   * "worker"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/actions.json#/items/properties/context
-		Context string `json:"context"`
+		Context ActionContext `json:"context"`
 
 		// Description of the provisioner.
 		//
@@ -74,7 +75,7 @@ type (
 		//   * "PATCH"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/actions.json#/items/properties/method
-		Method string `json:"method"`
+		Method ActionMethod `json:"method"`
 
 		// Short names for things like logging/error messages.
 		//
@@ -138,7 +139,7 @@ type (
 		//   * "error"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/list-artifacts-response.json#/properties/artifacts/items/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType ArtifactStorageType `json:"storageType"`
 	}
 
 	// Request for an Azure Shared Access Signature (SAS) that will allow
@@ -173,7 +174,7 @@ type (
 		//   * "azure"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-request.json#/oneOf[2]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType AzureArtifactRequestStorageType `json:"storageType"`
 	}
 
 	// Response to a request for an Azure Shared Access Signature (SAS)
@@ -211,7 +212,7 @@ type (
 		//   * "azure"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-response.json#/oneOf[2]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType AzureArtifactResponseStorageType `json:"storageType"`
 	}
 
 	// Request a list of requests in a generalized format which can be run to
@@ -292,7 +293,7 @@ type (
 		//   * "blob"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-request.json#/oneOf[0]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType BlobArtifactRequestStorageType `json:"storageType"`
 
 		// The number of bytes transfered across the wire to the backing
 		// datastore.  If specified, it represents the post-content-encoding
@@ -335,7 +336,7 @@ type (
 		//   * "blob"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-response.json#/oneOf[0]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType BlobArtifactResponseStorageType `json:"storageType"`
 	}
 
 	// Request to claim a task for a worker to process.
@@ -463,7 +464,7 @@ type (
 		//   * "too-large-file-on-worker"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-request.json#/oneOf[4]/properties/reason
-		Reason string `json:"reason"`
+		Reason ErrorArtifactRequestReason `json:"reason"`
 
 		// Artifact storage type, in this case `error`
 		//
@@ -471,7 +472,7 @@ type (
 		//   * "error"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-request.json#/oneOf[4]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType ErrorArtifactRequestStorageType `json:"storageType"`
 	}
 
 	// Response to a request for the queue to reply `424` (Failed Dependency)
@@ -486,7 +487,7 @@ type (
 		//   * "error"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-response.json#/oneOf[4]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType ErrorArtifactResponseStorageType `json:"storageType"`
 	}
 
 	// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-response.json#/oneOf[0]/properties/requests/items
@@ -512,7 +513,7 @@ type (
 		//   * "PATCH"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-response.json#/oneOf[0]/properties/requests/items/properties/method
-		Method string `json:"method"`
+		Method HTTPRequestMethod `json:"method"`
 
 		// URL of request
 		//
@@ -751,7 +752,7 @@ type (
 		//   * "deprecated"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/list-provisioners-response.json#/properties/provisioners/items/properties/stability
-		Stability string `json:"stability"`
+		Stability Stability `json:"stability"`
 	}
 
 	// Request to update a provisioner.
@@ -773,7 +774,7 @@ type (
 		// deleted by the queue.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/update-provisioner-request.json#/properties/expires
-		Expires tcclient.Time `json:"expires,omitempty"`
+		Expires *tcclient.Time `json:"expires,omitempty"`
 
 		// This is the stability of the provisioner. Accepted values:
 		//   * `experimental`
@@ -786,7 +787,7 @@ type (
 		//   * "deprecated"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/update-provisioner-request.json#/properties/stability
-		Stability string `json:"stability,omitempty"`
+		Stability Stability `json:"stability,omitempty"`
 	}
 
 	// Response containing information about a provisioner.
@@ -835,7 +836,7 @@ type (
 		//   * "deprecated"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/provisioner-response.json#/properties/stability
-		Stability string `json:"stability"`
+		Stability Stability `json:"stability"`
 	}
 
 	// Request to update a worker's quarantineUntil property.
@@ -886,7 +887,7 @@ type (
 		//   * "reference"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-request.json#/oneOf[3]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType RedirectArtifactRequestStorageType `json:"storageType"`
 
 		// URL to which the queue should redirect using a `303` (See other)
 		// redirect.
@@ -907,7 +908,7 @@ type (
 		//   * "reference"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-response.json#/oneOf[3]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType RedirectArtifactResponseStorageType `json:"storageType"`
 	}
 
 	// JSON object with information about a run
@@ -926,7 +927,7 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/reasonCreated
-		ReasonCreated string `json:"reasonCreated"`
+		ReasonCreated ReasonCreated `json:"reasonCreated"`
 
 		// Reason that run was resolved, this is mainly
 		// useful for runs resolved as `exception`.
@@ -949,14 +950,14 @@ type (
 		//   * "intermittent-task"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/reasonResolved
-		ReasonResolved string `json:"reasonResolved,omitempty"`
+		ReasonResolved ReasonResolved `json:"reasonResolved,omitempty"`
 
 		// Date-time at which this run was resolved, ie. when the run changed
 		// state from `running` to either `completed`, `failed` or `exception`.
 		// This property is only present after the run as been resolved.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/resolved
-		Resolved tcclient.Time `json:"resolved,omitempty"`
+		Resolved *tcclient.Time `json:"resolved,omitempty"`
 
 		// Id of this task run, `run-id`s always starts from `0`
 		//
@@ -977,7 +978,7 @@ type (
 		// after the run has been claimed.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/started
-		Started tcclient.Time `json:"started,omitempty"`
+		Started *tcclient.Time `json:"started,omitempty"`
 
 		// State of this run
 		//
@@ -989,14 +990,14 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/state
-		State string `json:"state"`
+		State RunInformationState `json:"state"`
 
 		// Time at which the run expires and is resolved as `failed`, if the
 		// run isn't reclaimed. Note, only present after the run has been
 		// claimed.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/takenUntil
-		TakenUntil tcclient.Time `json:"takenUntil,omitempty"`
+		TakenUntil *tcclient.Time `json:"takenUntil,omitempty"`
 
 		// Identifier for group that worker who executes this run is a part of,
 		// this identifier is mainly used for efficient routing.
@@ -1052,7 +1053,7 @@ type (
 		//   * "s3"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-request.json#/oneOf[1]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType S3ArtifactRequestStorageType `json:"storageType"`
 	}
 
 	// Response to a request for a signed PUT URL that will allow you to
@@ -1087,7 +1088,7 @@ type (
 		//   * "s3"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/post-artifact-response.json#/oneOf[1]/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType S3ArtifactResponseStorageType `json:"storageType"`
 	}
 
 	// See https://taskcluster-staging.net/schemas/queue/v1/claim-work-response.json#/properties/tasks/items
@@ -1344,7 +1345,7 @@ type (
 		// plus one year (this default may change).
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task.json#/properties/expires
-		Expires tcclient.Time `json:"expires,omitempty"`
+		Expires *tcclient.Time `json:"expires,omitempty"`
 
 		// Object with properties that can hold any kind of extra data that should be
 		// associated with the task. This can be data for the task which doesn't
@@ -1395,7 +1396,7 @@ type (
 		// Default:    "lowest"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task.json#/properties/priority
-		Priority string `json:"priority,omitempty"`
+		Priority Priority `json:"priority,omitempty"`
 
 		// Unique identifier for a provisioner, that can supply specified
 		// `workerType`
@@ -1421,7 +1422,7 @@ type (
 		// Default:    "all-completed"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task.json#/properties/requires
-		Requires string `json:"requires,omitempty"`
+		Requires Requires `json:"requires,omitempty"`
 
 		// Number of times to retry the task in case of infrastructure issues.
 		// An _infrastructure issue_ is a worker node that crashes or is shutdown,
@@ -1560,7 +1561,7 @@ type (
 		// plus one year (this default may change).
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task.json#/properties/expires
-		Expires tcclient.Time `json:"expires,omitempty"`
+		Expires *tcclient.Time `json:"expires,omitempty"`
 
 		// Object with properties that can hold any kind of extra data that should be
 		// associated with the task. This can be data for the task which doesn't
@@ -1611,7 +1612,7 @@ type (
 		// Default:    "lowest"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task.json#/properties/priority
-		Priority string `json:"priority"`
+		Priority Priority `json:"priority"`
 
 		// Unique identifier for a provisioner, that can supply specified
 		// `workerType`
@@ -1637,7 +1638,7 @@ type (
 		// Default:    "all-completed"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task.json#/properties/requires
-		Requires string `json:"requires"`
+		Requires Requires `json:"requires"`
 
 		// Number of times to retry the task in case of infrastructure issues.
 		// An _infrastructure issue_ is a worker node that crashes or is shutdown,
@@ -1786,7 +1787,7 @@ type (
 		//   * "intermittent-task"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-exception-request.json#/properties/reason
-		Reason string `json:"reason"`
+		Reason TaskExceptionRequestReason `json:"reason"`
 	}
 
 	// Required task metadata
@@ -1998,7 +1999,7 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/state
-		State string `json:"state"`
+		State TaskStatusStructureState `json:"state"`
 
 		// Identifier for a group of tasks scheduled together with this task.
 		// Generally, all tasks related to a single event such as a version-control
@@ -2050,7 +2051,7 @@ type (
 		// somewhere in the past).
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/list-workers-response.json#/properties/workers/items/properties/quarantineUntil
-		QuarantineUntil tcclient.Time `json:"quarantineUntil,omitempty"`
+		QuarantineUntil *tcclient.Time `json:"quarantineUntil,omitempty"`
 
 		// Identifier for the worker group containing this worker.
 		//
@@ -2110,7 +2111,7 @@ type (
 		//   * "worker"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/worker-response.json#/properties/actions/items/properties/context
-		Context string `json:"context"`
+		Context WorkerActionContext `json:"context"`
 
 		// Description of the provisioner.
 		//
@@ -2126,7 +2127,7 @@ type (
 		//   * "PATCH"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/worker-response.json#/properties/actions/items/properties/method
-		Method string `json:"method"`
+		Method WorkerActionMethod `json:"method"`
 
 		// Short names for things like logging/error messages.
 		//
@@ -2162,7 +2163,7 @@ type (
 		// deleted by the queue.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/update-worker-request.json#/properties/expires
-		Expires tcclient.Time `json:"expires,omitempty"`
+		Expires *tcclient.Time `json:"expires,omitempty"`
 	}
 
 	// Response containing information about a worker.
@@ -2197,7 +2198,7 @@ type (
 		// somewhere in the past).
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/worker-response.json#/properties/quarantineUntil
-		QuarantineUntil tcclient.Time `json:"quarantineUntil,omitempty"`
+		QuarantineUntil *tcclient.Time `json:"quarantineUntil,omitempty"`
 
 		// List of 20 most recent tasks claimed by the worker.
 		//
@@ -2271,7 +2272,7 @@ type (
 		//   * "deprecated"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/list-workertypes-response.json#/properties/workerTypes/items/properties/stability
-		Stability string `json:"stability"`
+		Stability Stability `json:"stability"`
 
 		// WorkerType name.
 		//
@@ -2322,7 +2323,7 @@ type (
 		//   * "worker-type"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/workertype-response.json#/properties/actions/items/properties/context
-		Context string `json:"context"`
+		Context WorkerTypeActionContext `json:"context"`
 
 		// Description of the provisioner.
 		//
@@ -2338,7 +2339,7 @@ type (
 		//   * "PATCH"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/workertype-response.json#/properties/actions/items/properties/method
-		Method string `json:"method"`
+		Method WorkerTypeActionMethod `json:"method"`
 
 		// Short names for things like logging/error messages.
 		//
@@ -2379,7 +2380,7 @@ type (
 		// deleted by the queue.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/update-workertype-request.json#/properties/expires
-		Expires tcclient.Time `json:"expires,omitempty"`
+		Expires *tcclient.Time `json:"expires,omitempty"`
 
 		// This is the stability of the provisioner. Accepted values:
 		//   * `experimental`
@@ -2392,7 +2393,7 @@ type (
 		//   * "deprecated"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/update-workertype-request.json#/properties/stability
-		Stability string `json:"stability,omitempty"`
+		Stability Stability `json:"stability,omitempty"`
 	}
 
 	// Response to a worker-type request from a provisioner.
@@ -2439,7 +2440,7 @@ type (
 		//   * "deprecated"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/workertype-response.json#/properties/stability
-		Stability string `json:"stability"`
+		Stability Stability `json:"stability"`
 
 		// WorkerType name.
 		//
@@ -2483,3 +2484,670 @@ func (this *PostArtifactResponse) UnmarshalJSON(data []byte) error {
 	*this = append((*this)[0:0], data...)
 	return nil
 }
+
+// ActionContext is the type of the `context` property of Action.
+// Its possible values are declared as constants.
+type ActionContext string
+
+// Possible values of ActionContext
+const (
+	ActionContextProvisioner ActionContext = "provisioner"
+	ActionContextWorkerType  ActionContext = "worker-type"
+	ActionContextWorker      ActionContext = "worker"
+)
+
+// Valid returns true if this is one of the possible values of ActionContext.
+func (this ActionContext) Valid() bool {
+	switch this {
+	case ActionContextProvisioner, ActionContextWorkerType, ActionContextWorker:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid ActionContext.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this ActionContext) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid ActionContext %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// ActionMethod is the type of the `method` property of Action.
+// Its possible values are declared as constants.
+type ActionMethod string
+
+// Possible values of ActionMethod
+const (
+	ActionMethodPOST   ActionMethod = "POST"
+	ActionMethodPUT    ActionMethod = "PUT"
+	ActionMethodDELETE ActionMethod = "DELETE"
+	ActionMethodPATCH  ActionMethod = "PATCH"
+)
+
+// Valid returns true if this is one of the possible values of ActionMethod.
+func (this ActionMethod) Valid() bool {
+	switch this {
+	case ActionMethodPOST, ActionMethodPUT, ActionMethodDELETE, ActionMethodPATCH:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid ActionMethod.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this ActionMethod) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid ActionMethod %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// ArtifactStorageType is the type of the `storageType` property of Artifact.
+// Its possible values are declared as constants.
+type ArtifactStorageType string
+
+// Possible values of ArtifactStorageType
+const (
+	ArtifactStorageTypeBlob      ArtifactStorageType = "blob"
+	ArtifactStorageTypeS3        ArtifactStorageType = "s3"
+	ArtifactStorageTypeAzure     ArtifactStorageType = "azure"
+	ArtifactStorageTypeReference ArtifactStorageType = "reference"
+	ArtifactStorageTypeError     ArtifactStorageType = "error"
+)
+
+// Valid returns true if this is one of the possible values of ArtifactStorageType.
+func (this ArtifactStorageType) Valid() bool {
+	switch this {
+	case ArtifactStorageTypeBlob, ArtifactStorageTypeS3, ArtifactStorageTypeAzure, ArtifactStorageTypeReference, ArtifactStorageTypeError:
+		return true
+	}
+	return false
+}
+
+// AzureArtifactRequestStorageType is the type of the `storageType` property of AzureArtifactRequest.
+// Its possible values are declared as constants.
+type AzureArtifactRequestStorageType string
+
+// Possible values of AzureArtifactRequestStorageType
+const (
+	AzureArtifactRequestStorageTypeAzure AzureArtifactRequestStorageType = "azure"
+)
+
+// Valid returns true if this is one of the possible values of AzureArtifactRequestStorageType.
+func (this AzureArtifactRequestStorageType) Valid() bool {
+	switch this {
+	case AzureArtifactRequestStorageTypeAzure:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid AzureArtifactRequestStorageType.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this AzureArtifactRequestStorageType) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid AzureArtifactRequestStorageType %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// AzureArtifactResponseStorageType is the type of the `storageType` property of AzureArtifactResponse.
+// Its possible values are declared as constants.
+type AzureArtifactResponseStorageType string
+
+// Possible values of AzureArtifactResponseStorageType
+const (
+	AzureArtifactResponseStorageTypeAzure AzureArtifactResponseStorageType = "azure"
+)
+
+// Valid returns true if this is one of the possible values of AzureArtifactResponseStorageType.
+func (this AzureArtifactResponseStorageType) Valid() bool {
+	switch this {
+	case AzureArtifactResponseStorageTypeAzure:
+		return true
+	}
+	return false
+}
+
+// BlobArtifactRequestStorageType is the type of the `storageType` property of BlobArtifactRequest.
+// Its possible values are declared as constants.
+type BlobArtifactRequestStorageType string
+
+// Possible values of BlobArtifactRequestStorageType
+const (
+	BlobArtifactRequestStorageTypeBlob BlobArtifactRequestStorageType = "blob"
+)
+
+// Valid returns true if this is one of the possible values of BlobArtifactRequestStorageType.
+func (this BlobArtifactRequestStorageType) Valid() bool {
+	switch this {
+	case BlobArtifactRequestStorageTypeBlob:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid BlobArtifactRequestStorageType.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this BlobArtifactRequestStorageType) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid BlobArtifactRequestStorageType %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// BlobArtifactResponseStorageType is the type of the `storageType` property of BlobArtifactResponse.
+// Its possible values are declared as constants.
+type BlobArtifactResponseStorageType string
+
+// Possible values of BlobArtifactResponseStorageType
+const (
+	BlobArtifactResponseStorageTypeBlob BlobArtifactResponseStorageType = "blob"
+)
+
+// Valid returns true if this is one of the possible values of BlobArtifactResponseStorageType.
+func (this BlobArtifactResponseStorageType) Valid() bool {
+	switch this {
+	case BlobArtifactResponseStorageTypeBlob:
+		return true
+	}
+	return false
+}
+
+// ErrorArtifactRequestReason is the type of the `reason` property of ErrorArtifactRequest.
+// Its possible values are declared as constants.
+type ErrorArtifactRequestReason string
+
+// Possible values of ErrorArtifactRequestReason
+const (
+	ErrorArtifactRequestReasonFileMissingOnWorker     ErrorArtifactRequestReason = "file-missing-on-worker"
+	ErrorArtifactRequestReasonInvalidResourceOnWorker ErrorArtifactRequestReason = "invalid-resource-on-worker"
+	ErrorArtifactRequestReasonTooLargeFileOnWorker    ErrorArtifactRequestReason = "too-large-file-on-worker"
+)
+
+// Valid returns true if this is one of the possible values of ErrorArtifactRequestReason.
+func (this ErrorArtifactRequestReason) Valid() bool {
+	switch this {
+	case ErrorArtifactRequestReasonFileMissingOnWorker, ErrorArtifactRequestReasonInvalidResourceOnWorker, ErrorArtifactRequestReasonTooLargeFileOnWorker:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid ErrorArtifactRequestReason.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this ErrorArtifactRequestReason) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid ErrorArtifactRequestReason %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// ErrorArtifactRequestStorageType is the type of the `storageType` property of ErrorArtifactRequest.
+// Its possible values are declared as constants.
+type ErrorArtifactRequestStorageType string
+
+// Possible values of ErrorArtifactRequestStorageType
+const (
+	ErrorArtifactRequestStorageTypeError ErrorArtifactRequestStorageType = "error"
+)
+
+// Valid returns true if this is one of the possible values of ErrorArtifactRequestStorageType.
+func (this ErrorArtifactRequestStorageType) Valid() bool {
+	switch this {
+	case ErrorArtifactRequestStorageTypeError:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid ErrorArtifactRequestStorageType.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this ErrorArtifactRequestStorageType) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid ErrorArtifactRequestStorageType %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// ErrorArtifactResponseStorageType is the type of the `storageType` property of ErrorArtifactResponse.
+// Its possible values are declared as constants.
+type ErrorArtifactResponseStorageType string
+
+// Possible values of ErrorArtifactResponseStorageType
+const (
+	ErrorArtifactResponseStorageTypeError ErrorArtifactResponseStorageType = "error"
+)
+
+// Valid returns true if this is one of the possible values of ErrorArtifactResponseStorageType.
+func (this ErrorArtifactResponseStorageType) Valid() bool {
+	switch this {
+	case ErrorArtifactResponseStorageTypeError:
+		return true
+	}
+	return false
+}
+
+// HTTPRequestMethod is the type of the `method` property of HTTPRequest.
+// Its possible values are declared as constants.
+type HTTPRequestMethod string
+
+// Possible values of HTTPRequestMethod
+const (
+	HTTPRequestMethodGET     HTTPRequestMethod = "GET"
+	HTTPRequestMethodPOST    HTTPRequestMethod = "POST"
+	HTTPRequestMethodPUT     HTTPRequestMethod = "PUT"
+	HTTPRequestMethodDELETE  HTTPRequestMethod = "DELETE"
+	HTTPRequestMethodOPTIONS HTTPRequestMethod = "OPTIONS"
+	HTTPRequestMethodHEAD    HTTPRequestMethod = "HEAD"
+	HTTPRequestMethodPATCH   HTTPRequestMethod = "PATCH"
+)
+
+// Valid returns true if this is one of the possible values of HTTPRequestMethod.
+func (this HTTPRequestMethod) Valid() bool {
+	switch this {
+	case HTTPRequestMethodGET, HTTPRequestMethodPOST, HTTPRequestMethodPUT, HTTPRequestMethodDELETE, HTTPRequestMethodOPTIONS, HTTPRequestMethodHEAD, HTTPRequestMethodPATCH:
+		return true
+	}
+	return false
+}
+
+// Stability is the type of the `stability` property of ProvisionerInformation, ProvisionerRequest, ProvisionerResponse, WorkerType, WorkerTypeRequest and WorkerTypeResponse.
+// Its possible values are declared as constants.
+type Stability string
+
+// Possible values of Stability
+const (
+	StabilityExperimental Stability = "experimental"
+	StabilityStable       Stability = "stable"
+	StabilityDeprecated   Stability = "deprecated"
+)
+
+// Valid returns true if this is one of the possible values of Stability.
+func (this Stability) Valid() bool {
+	switch this {
+	case StabilityExperimental, StabilityStable, StabilityDeprecated:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid Stability.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this Stability) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid Stability %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// RedirectArtifactRequestStorageType is the type of the `storageType` property of RedirectArtifactRequest.
+// Its possible values are declared as constants.
+type RedirectArtifactRequestStorageType string
+
+// Possible values of RedirectArtifactRequestStorageType
+const (
+	RedirectArtifactRequestStorageTypeReference RedirectArtifactRequestStorageType = "reference"
+)
+
+// Valid returns true if this is one of the possible values of RedirectArtifactRequestStorageType.
+func (this RedirectArtifactRequestStorageType) Valid() bool {
+	switch this {
+	case RedirectArtifactRequestStorageTypeReference:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid RedirectArtifactRequestStorageType.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this RedirectArtifactRequestStorageType) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid RedirectArtifactRequestStorageType %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// RedirectArtifactResponseStorageType is the type of the `storageType` property of RedirectArtifactResponse.
+// Its possible values are declared as constants.
+type RedirectArtifactResponseStorageType string
+
+// Possible values of RedirectArtifactResponseStorageType
+const (
+	RedirectArtifactResponseStorageTypeReference RedirectArtifactResponseStorageType = "reference"
+)
+
+// Valid returns true if this is one of the possible values of RedirectArtifactResponseStorageType.
+func (this RedirectArtifactResponseStorageType) Valid() bool {
+	switch this {
+	case RedirectArtifactResponseStorageTypeReference:
+		return true
+	}
+	return false
+}
+
+// ReasonCreated is the type of the `reasonCreated` property of RunInformation.
+// Its possible values are declared as constants.
+type ReasonCreated string
+
+// Possible values of ReasonCreated
+const (
+	ReasonCreatedScheduled ReasonCreated = "scheduled"
+	ReasonCreatedRetry     ReasonCreated = "retry"
+	ReasonCreatedTaskRetry ReasonCreated = "task-retry"
+	ReasonCreatedRerun     ReasonCreated = "rerun"
+	ReasonCreatedException ReasonCreated = "exception"
+)
+
+// Valid returns true if this is one of the possible values of ReasonCreated.
+func (this ReasonCreated) Valid() bool {
+	switch this {
+	case ReasonCreatedScheduled, ReasonCreatedRetry, ReasonCreatedTaskRetry, ReasonCreatedRerun, ReasonCreatedException:
+		return true
+	}
+	return false
+}
+
+// ReasonResolved is the type of the `reasonResolved` property of RunInformation.
+// Its possible values are declared as constants.
+type ReasonResolved string
+
+// Possible values of ReasonResolved
+const (
+	ReasonResolvedCompleted           ReasonResolved = "completed"
+	ReasonResolvedFailed              ReasonResolved = "failed"
+	ReasonResolvedDeadlineExceeded    ReasonResolved = "deadline-exceeded"
+	ReasonResolvedCanceled            ReasonResolved = "canceled"
+	ReasonResolvedSuperseded          ReasonResolved = "superseded"
+	ReasonResolvedClaimExpired        ReasonResolved = "claim-expired"
+	ReasonResolvedWorkerShutdown      ReasonResolved = "worker-shutdown"
+	ReasonResolvedMalformedPayload    ReasonResolved = "malformed-payload"
+	ReasonResolvedResourceUnavailable ReasonResolved = "resource-unavailable"
+	ReasonResolvedInternalError       ReasonResolved = "internal-error"
+	ReasonResolvedIntermittentTask    ReasonResolved = "intermittent-task"
+)
+
+// Valid returns true if this is one of the possible values of ReasonResolved.
+func (this ReasonResolved) Valid() bool {
+	switch this {
+	case ReasonResolvedCompleted, ReasonResolvedFailed, ReasonResolvedDeadlineExceeded, ReasonResolvedCanceled, ReasonResolvedSuperseded, ReasonResolvedClaimExpired, ReasonResolvedWorkerShutdown, ReasonResolvedMalformedPayload, ReasonResolvedResourceUnavailable, ReasonResolvedInternalError, ReasonResolvedIntermittentTask:
+		return true
+	}
+	return false
+}
+
+// RunInformationState is the type of the `state` property of RunInformation.
+// Its possible values are declared as constants.
+type RunInformationState string
+
+// Possible values of RunInformationState
+const (
+	RunInformationStatePending   RunInformationState = "pending"
+	RunInformationStateRunning   RunInformationState = "running"
+	RunInformationStateCompleted RunInformationState = "completed"
+	RunInformationStateFailed    RunInformationState = "failed"
+	RunInformationStateException RunInformationState = "exception"
+)
+
+// Valid returns true if this is one of the possible values of RunInformationState.
+func (this RunInformationState) Valid() bool {
+	switch this {
+	case RunInformationStatePending, RunInformationStateRunning, RunInformationStateCompleted, RunInformationStateFailed, RunInformationStateException:
+		return true
+	}
+	return false
+}
+
+// S3ArtifactRequestStorageType is the type of the `storageType` property of S3ArtifactRequest.
+// Its possible values are declared as constants.
+type S3ArtifactRequestStorageType string
+
+// Possible values of S3ArtifactRequestStorageType
+const (
+	S3ArtifactRequestStorageTypeS3 S3ArtifactRequestStorageType = "s3"
+)
+
+// Valid returns true if this is one of the possible values of S3ArtifactRequestStorageType.
+func (this S3ArtifactRequestStorageType) Valid() bool {
+	switch this {
+	case S3ArtifactRequestStorageTypeS3:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid S3ArtifactRequestStorageType.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this S3ArtifactRequestStorageType) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid S3ArtifactRequestStorageType %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// S3ArtifactResponseStorageType is the type of the `storageType` property of S3ArtifactResponse.
+// Its possible values are declared as constants.
+type S3ArtifactResponseStorageType string
+
+// Possible values of S3ArtifactResponseStorageType
+const (
+	S3ArtifactResponseStorageTypeS3 S3ArtifactResponseStorageType = "s3"
+)
+
+// Valid returns true if this is one of the possible values of S3ArtifactResponseStorageType.
+func (this S3ArtifactResponseStorageType) Valid() bool {
+	switch this {
+	case S3ArtifactResponseStorageTypeS3:
+		return true
+	}
+	return false
+}
+
+// Priority is the type of the `priority` property of TaskDefinitionRequest and TaskDefinitionResponse.
+// Its possible values are declared as constants.
+type Priority string
+
+// Possible values of Priority
+const (
+	PriorityHighest  Priority = "highest"
+	PriorityVeryHigh Priority = "very-high"
+	PriorityHigh     Priority = "high"
+	PriorityMedium   Priority = "medium"
+	PriorityLow      Priority = "low"
+	PriorityVeryLow  Priority = "very-low"
+	PriorityLowest   Priority = "lowest"
+	PriorityNormal   Priority = "normal"
+)
+
+// Valid returns true if this is one of the possible values of Priority.
+func (this Priority) Valid() bool {
+	switch this {
+	case PriorityHighest, PriorityVeryHigh, PriorityHigh, PriorityMedium, PriorityLow, PriorityVeryLow, PriorityLowest, PriorityNormal:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid Priority.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this Priority) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid Priority %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// Requires is the type of the `requires` property of TaskDefinitionRequest and TaskDefinitionResponse.
+// Its possible values are declared as constants.
+type Requires string
+
+// Possible values of Requires
+const (
+	RequiresAllCompleted Requires = "all-completed"
+	RequiresAllResolved  Requires = "all-resolved"
+)
+
+// Valid returns true if this is one of the possible values of Requires.
+func (this Requires) Valid() bool {
+	switch this {
+	case RequiresAllCompleted, RequiresAllResolved:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid Requires.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this Requires) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid Requires %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// TaskExceptionRequestReason is the type of the `reason` property of TaskExceptionRequest.
+// Its possible values are declared as constants.
+type TaskExceptionRequestReason string
+
+// Possible values of TaskExceptionRequestReason
+const (
+	TaskExceptionRequestReasonWorkerShutdown      TaskExceptionRequestReason = "worker-shutdown"
+	TaskExceptionRequestReasonMalformedPayload    TaskExceptionRequestReason = "malformed-payload"
+	TaskExceptionRequestReasonResourceUnavailable TaskExceptionRequestReason = "resource-unavailable"
+	TaskExceptionRequestReasonInternalError       TaskExceptionRequestReason = "internal-error"
+	TaskExceptionRequestReasonSuperseded          TaskExceptionRequestReason = "superseded"
+	TaskExceptionRequestReasonIntermittentTask    TaskExceptionRequestReason = "intermittent-task"
+)
+
+// Valid returns true if this is one of the possible values of TaskExceptionRequestReason.
+func (this TaskExceptionRequestReason) Valid() bool {
+	switch this {
+	case TaskExceptionRequestReasonWorkerShutdown, TaskExceptionRequestReasonMalformedPayload, TaskExceptionRequestReasonResourceUnavailable, TaskExceptionRequestReasonInternalError, TaskExceptionRequestReasonSuperseded, TaskExceptionRequestReasonIntermittentTask:
+		return true
+	}
+	return false
+}
+
+// MarshalJSON returns an error unless this is a valid TaskExceptionRequestReason.
+// Values are not checked when unmarshaling, so that values added to the
+// service after this package was generated are still accepted.
+func (this TaskExceptionRequestReason) MarshalJSON() ([]byte, error) {
+	if !this.Valid() {
+		return nil, fmt.Errorf("invalid TaskExceptionRequestReason %q", string(this))
+	}
+	return json.Marshal(string(this))
+}
+
+// TaskStatusStructureState is the type of the `state` property of TaskStatusStructure.
+// Its possible values are declared as constants.
+type TaskStatusStructureState string
+
+// Possible values of TaskStatusStructureState
+const (
+	TaskStatusStructureStateUnscheduled TaskStatusStructureState = "unscheduled"
+	TaskStatusStructureStatePending     TaskStatusStructureState = "pending"
+	TaskStatusStructureStateRunning     TaskStatusStructureState = "running"
+	TaskStatusStructureStateCompleted   TaskStatusStructureState = "completed"
+	TaskStatusStructureStateFailed      TaskStatusStructureState = "failed"
+	TaskStatusStructureStateException   TaskStatusStructureState = "exception"
+)
+
+// Valid returns true if this is one of the possible values of TaskStatusStructureState.
+func (this TaskStatusStructureState) Valid() bool {
+	switch this {
+	case TaskStatusStructureStateUnscheduled, TaskStatusStructureStatePending, TaskStatusStructureStateRunning, TaskStatusStructureStateCompleted, TaskStatusStructureStateFailed, TaskStatusStructureStateException:
+		return true
+	}
+	return false
+}
+
+// WorkerActionContext is the type of the `context` property of WorkerAction.
+// Its possible values are declared as constants.
+type WorkerActionContext string
+
+// Possible values of WorkerActionContext
+const (
+	WorkerActionContextWorker WorkerActionContext = "worker"
+)
+
+// Valid returns true if this is one of the possible values of WorkerActionContext.
+func (this WorkerActionContext) Valid() bool {
+	switch this {
+	case WorkerActionContextWorker:
+		return true
+	}
+	return false
+}
+
+// WorkerActionMethod is the type of the `method` property of WorkerAction.
+// Its possible values are declared as constants.
+type WorkerActionMethod string
+
+// Possible values of WorkerActionMethod
+const (
+	WorkerActionMethodPOST   WorkerActionMethod = "POST"
+	WorkerActionMethodPUT    WorkerActionMethod = "PUT"
+	WorkerActionMethodDELETE WorkerActionMethod = "DELETE"
+	WorkerActionMethodPATCH  WorkerActionMethod = "PATCH"
+)
+
+// Valid returns true if this is one of the possible values of WorkerActionMethod.
+func (this WorkerActionMethod) Valid() bool {
+	switch this {
+	case WorkerActionMethodPOST, WorkerActionMethodPUT, WorkerActionMethodDELETE, WorkerActionMethodPATCH:
+		return true
+	}
+	return false
+}
+
+// WorkerTypeActionContext is the type of the `context` property of WorkerTypeAction.
+// Its possible values are declared as constants.
+type WorkerTypeActionContext string
+
+// Possible values of WorkerTypeActionContext
+const (
+	WorkerTypeActionContextWorkerType WorkerTypeActionContext = "worker-type"
+)
+
+// Valid returns true if this is one of the possible values of WorkerTypeActionContext.
+func (this WorkerTypeActionContext) Valid() bool {
+	switch this {
+	case WorkerTypeActionContextWorkerType:
+		return true
+	}
+	return false
+}
+
+// WorkerTypeActionMethod is the type of the `method` property of WorkerTypeAction.
+// Its possible values are declared as constants.
+type WorkerTypeActionMethod string
+
+// Possible values of WorkerTypeActionMethod
+const (
+	WorkerTypeActionMethodPOST   WorkerTypeActionMethod = "POST"
+	WorkerTypeActionMethodPUT    WorkerTypeActionMethod = "PUT"
+	WorkerTypeActionMethodDELETE WorkerTypeActionMethod = "DELETE"
+	WorkerTypeActionMethodPATCH  WorkerTypeActionMethod = "PATCH"
+)
+
+// Valid returns true if this is one of the possible values of WorkerTypeActionMethod.
+func (this WorkerTypeActionMethod) Valid() bool {
+	switch this {
+	case WorkerTypeActionMethodPOST, WorkerTypeActionMethodPUT, WorkerTypeActionMethodDELETE, WorkerTypeActionMethodPATCH:
+		return true
+	}
+	return false
+}
@@ -43,7 +43,7 @@ type (
 		//   * "error"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/artifact-created-message.json#/properties/artifact/properties/storageType
-		StorageType string `json:"storageType"`
+		StorageType StorageType `json:"storageType"`
 	}
 
 	// Message reporting a new artifact has been created for a given task.
@@ -114,7 +114,7 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/reasonCreated
-		ReasonCreated string `json:"reasonCreated"`
+		ReasonCreated ReasonCreated `json:"reasonCreated"`
 
 		// Reason that run was resolved, this is mainly
 		// useful for runs resolved as `exception`.
@@ -137,14 +137,14 @@ type (
 		//   * "intermittent-task"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/reasonResolved
-		ReasonResolved string `json:"reasonResolved,omitempty"`
+		ReasonResolved ReasonResolved `json:"reasonResolved,omitempty"`
 
 		// Date-time at which this run was resolved, ie. when the run changed
 		// state from `running` to either `completed`, `failed` or `exception`.
 		// This property is only present after the run as been resolved.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/resolved
-		Resolved tcclient.Time `json:"resolved,omitempty"`
+		Resolved *tcclient.Time `json:"resolved,omitempty"`
 
 		// Id of this task run, `run-id`s always starts from `0`
 		//
@@ -165,7 +165,7 @@ type (
 		// after the run has been claimed.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/started
-		Started tcclient.Time `json:"started,omitempty"`
+		Started *tcclient.Time `json:"started,omitempty"`
 
 		// State of this run
 		//
@@ -177,14 +177,14 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/state
-		State string `json:"state"`
+		State RunInformationState `json:"state"`
 
 		// Time at which the run expires and is resolved as `failed`, if the
 		// run isn't reclaimed. Note, only present after the run has been
 		// claimed.
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/runs/items/properties/takenUntil
-		TakenUntil tcclient.Time `json:"takenUntil,omitempty"`
+		TakenUntil *tcclient.Time `json:"takenUntil,omitempty"`
 
 		// Identifier for group that worker who executes this run is a part of,
 		// this identifier is mainly used for efficient routing.
@@ -591,7 +591,7 @@ type (
 		//   * "exception"
 		//
 		// See https://taskcluster-staging.net/schemas/queue/v1/task-status.json#/properties/state
-		State string `json:"state"`
+		State TaskStatusStructureState `json:"state"`
 
 		// Identifier for a group of tasks scheduled together with this task.
 		// Generally, all tasks related to a single event such as a version-control
@@ -624,3 +624,118 @@ type (
 		WorkerType string `json:"workerType"`
 	}
 )
+
+// StorageType is the type of the `storageType` property of Artifact.
+// Its possible values are declared as constants.
+type StorageType string
+
+// Possible values of StorageType
+const (
+	StorageTypeBlob      StorageType = "blob"
+	StorageTypeReference StorageType = "reference"
+	StorageTypeError     StorageType = "error"
+)
+
+// Valid returns true if this is one of the possible values of StorageType.
+func (this StorageType) Valid() bool {
+	switch this {
+	case StorageTypeBlob, StorageTypeReference, StorageTypeError:
+		return true
+	}
+	return false
+}
+
+// ReasonCreated is the type of the `reasonCreated` property of RunInformation.
+// Its possible values are declared as constants.
+type ReasonCreated string
+
+// Possible values of ReasonCreated
+const (
+	ReasonCreatedScheduled ReasonCreated = "scheduled"
+	ReasonCreatedRetry     ReasonCreated = "retry"
+	ReasonCreatedTaskRetry ReasonCreated = "task-retry"
+	ReasonCreatedRerun     ReasonCreated = "rerun"
+	ReasonCreatedException ReasonCreated = "exception"
+)
+
+// Valid returns true if this is one of the possible values of ReasonCreated.
+func (this ReasonCreated) Valid() bool {
+	switch this {
+	case ReasonCreatedScheduled, ReasonCreatedRetry, ReasonCreatedTaskRetry, ReasonCreatedRerun, ReasonCreatedException:
+		return true
+	}
+	return false
+}
+
+// ReasonResolved is the type of the `reasonResolved` property of RunInformation.
+// Its possible values are declared as constants.
+type ReasonResolved string
+
+// Possible values of ReasonResolved
+const (
+	ReasonResolvedCompleted           ReasonResolved = "completed"
+	ReasonResolvedFailed              ReasonResolved = "failed"
+	ReasonResolvedDeadlineExceeded    ReasonResolved = "deadline-exceeded"
+	ReasonResolvedCanceled            ReasonResolved = "canceled"
+	ReasonResolvedSuperseded          ReasonResolved = "superseded"
+	ReasonResolvedClaimExpired        ReasonResolved = "claim-expired"
+	ReasonResolvedWorkerShutdown      ReasonResolved = "worker-shutdown"
+	ReasonResolvedMalformedPayload    ReasonResolved = "malformed-payload"
+	ReasonResolvedResourceUnavailable ReasonResolved = "resource-unavailable"
+	ReasonResolvedInternalError       ReasonResolved = "internal-error"
+	ReasonResolvedIntermittentTask    ReasonResolved = "intermittent-task"
+)
+
+// Valid returns true if this is one of the possible values of ReasonResolved.
+func (this ReasonResolved) Valid() bool {
+	switch this {
+	case ReasonResolvedCompleted, ReasonResolvedFailed, ReasonResolvedDeadlineExceeded, ReasonResolvedCanceled, ReasonResolvedSuperseded, ReasonResolvedClaimExpired, ReasonResolvedWorkerShutdown, ReasonResolvedMalformedPayload, ReasonResolvedResourceUnavailable, ReasonResolvedInternalError, ReasonResolvedIntermittentTask:
+		return true
+	}
+	return false
+}
+
+// RunInformationState is the type of the `state` property of RunInformation.
+// Its possible values are declared as constants.
+type RunInformationState string
+
+// Possible values of RunInformationState
+const (
+	RunInformationStatePending   RunInformationState = "pending"
+	RunInformationStateRunning   RunInformationState = "running"
+	RunInformationStateCompleted RunInformationState = "completed"
+	RunInformationStateFailed    RunInformationState = "failed"
+	RunInformationStateException RunInformationState = "exception"
+)
+
+// Valid returns true if this is one of the possible values of RunInformationState.
+func (this RunInformationState) Valid() bool {
+	switch this {
+	case RunInformationStatePending, RunInformationStateRunning, RunInformationStateCompleted, RunInformationStateFailed, RunInformationStateException:
+		return true
+	}
+	return false
+}
+
+// TaskStatusStructureState is the type of the `state` property of TaskStatusStructure.
+// Its possible values are declared as constants.
+type TaskStatusStructureState string
+
+// Possible values of TaskStatusStructureState
+const (
+	TaskStatusStructureStateUnscheduled TaskStatusStructureState = "unscheduled"
+	TaskStatusStructureStatePending     TaskStatusStructureState = "pending"
+	TaskStatusStructureStateRunning     TaskStatusStructureState = "running"
+	TaskStatusStructureStateCompleted   TaskStatusStructureState = "completed"
+	TaskStatusStructureStateFailed      TaskStatusStructureState = "failed"
+	TaskStatusStructureStateException   TaskStatusStructureState = "exception"
+)
+
+// Valid returns true if this is one of the possible values of TaskStatusStructureState.
+func (this TaskStatusStructureState) Valid() bool {
+	switch this {
+	case TaskStatusStructureStateUnscheduled, TaskStatusStructureStatePending, TaskStatusStructureStateRunning, TaskStatusStructureStateCompleted, TaskStatusStructureStateFailed, TaskStatusStructureStateException:
+		return true
+	}
+	return false
+}
@@ -43,10 +43,13 @@
 package tcsecrets
 
 import (
+	"context"
+	"errors"
 	"net/url"
 	"time"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type Secrets tcclient.Client
@@ -94,13 +97,59 @@ func NewFromEnv() *Secrets {
 	}
 }
 
+// NewFromProfile returns a *Secrets configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*Secrets, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcsecrets.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *Secrets, comprising all of its
+// API methods. Code that depends on API rather than *Secrets can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	Set(name string, payload *Secret) error
+	SetWithContext(ctx context.Context, name string, payload *Secret) error
+	Remove(name string) error
+	RemoveWithContext(ctx context.Context, name string) error
+	Get(name string) (*Secret, error)
+	GetWithContext(ctx context.Context, name string) (*Secret, error)
+	Get_SignedURL(name string, duration time.Duration) (*url.URL, error)
+	List(continuationToken, limit string) (*SecretsList, error)
+	ListWithContext(ctx context.Context, continuationToken, limit string) (*SecretsList, error)
+	ListAll(ctx context.Context) *StringIterator
+}
+
+var _ API = (*Secrets)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (secrets *Secrets) Ping() error {
+	return secrets.PingWithContext(secrets.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to secrets.Context.
+//
+// See Ping for more details.
+func (secrets *Secrets) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*secrets)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "secrets", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -112,8 +161,16 @@ func (secrets *Secrets) Ping() error {
 //
 // See #set
 func (secrets *Secrets) Set(name string, payload *Secret) error {
+	return secrets.SetWithContext(secrets.Context, name, payload)
+}
+
+// SetWithContext is the same as Set, except that the
+// request is bound to ctx rather than to secrets.Context.
+//
+// See Set for more details.
+func (secrets *Secrets) SetWithContext(ctx context.Context, name string, payload *Secret) error {
 	cd := tcclient.Client(*secrets)
-	_, _, err := (&cd).APICall(payload, "PUT", "/secret/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "secrets", "set"), payload, "PUT", "/secret/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -124,8 +181,16 @@ func (secrets *Secrets) Set(name string, payload *Secret) error {
 //
 // See #remove
 func (secrets *Secrets) Remove(name string) error {
+	return secrets.RemoveWithContext(secrets.Context, name)
+}
+
+// RemoveWithContext is the same as Remove, except that the
+// request is bound to ctx rather than to secrets.Context.
+//
+// See Remove for more details.
+func (secrets *Secrets) RemoveWithContext(ctx context.Context, name string) error {
 	cd := tcclient.Client(*secrets)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/secret/"+url.QueryEscape(name), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "secrets", "remove"), nil, "DELETE", "/secret/"+url.QueryEscape(name), nil, nil)
 	return err
 }
 
@@ -139,8 +204,16 @@ func (secrets *Secrets) Remove(name string) error {
 //
 // See #get
 func (secrets *Secrets) Get(name string) (*Secret, error) {
+	return secrets.GetWithContext(secrets.Context, name)
+}
+
+// GetWithContext is the same as Get, except that the
+// request is bound to ctx rather than to secrets.Context.
+//
+// See Get for more details.
+func (secrets *Secrets) GetWithContext(ctx context.Context, name string) (*Secret, error) {
 	cd := tcclient.Client(*secrets)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/secret/"+url.QueryEscape(name), new(Secret), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "secrets", "get"), nil, "GET", "/secret/"+url.QueryEscape(name), new(Secret), nil)
 	return responseObject.(*Secret), err
 }
 
@@ -169,6 +242,14 @@ func (secrets *Secrets) Get_SignedURL(name string, duration time.Duration) (*url
 //
 // See #list
 func (secrets *Secrets) List(continuationToken, limit string) (*SecretsList, error) {
+	return secrets.ListWithContext(secrets.Context, continuationToken, limit)
+}
+
+// ListWithContext is the same as List, except that the
+// request is bound to ctx rather than to secrets.Context.
+//
+// See List for more details.
+func (secrets *Secrets) ListWithContext(ctx context.Context, continuationToken, limit string) (*SecretsList, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -177,6 +258,65 @@ func (secrets *Secrets) List(continuationToken, limit string) (*SecretsList, err
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*secrets)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/secrets", new(SecretsList), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "secrets", "list"), nil, "GET", "/secrets", new(SecretsList), v)
 	return responseObject.(*SecretsList), err
 }
+
+// ListAll returns an iterator over the string items of all
+// pages of List results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See List for more details.
+func (secrets *Secrets) ListAll(ctx context.Context) *StringIterator {
+	return NewStringIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]string, string, error) {
+		page, err := secrets.ListWithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Secrets, page.ContinuationToken, nil
+	})
+}
+
+// StringIterator iterates over the string items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type StringIterator struct {
+	*tcclient.Pager
+	items []string
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *StringIterator) Item() string {
+	return it.items[it.Index()]
+}
+
+// NewStringIterator returns a StringIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewStringIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]string, string, error)) *StringIterator {
+	it := new(StringIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"set":    scopes.RequiredScope("secrets:set:<name>"),
+	"remove": scopes.RequiredScope("secrets:set:<name>"),
+	"get":    scopes.RequiredScope("secrets:get:<name>"),
+}
@@ -69,7 +69,7 @@ type (
 		//   * "github.com"
 		//
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/origin/oneOf[1]/properties/kind
-		Kind string `json:"kind"`
+		Kind GithubPullRequestKind `json:"kind"`
 
 		// This could be the organization or the individual git username
 		// depending on who owns the repo.
@@ -108,7 +108,7 @@ type (
 		//   * "hg.mozilla.org"
 		//
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/origin/oneOf[0]/properties/kind
-		Kind string `json:"kind"`
+		Kind HGPushKind `json:"kind"`
 
 		// Syntax:     ^[\w-]+$
 		// Min length: 1
@@ -191,7 +191,7 @@ type (
 		// Default:    "other"
 		//
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/jobKind
-		JobKind string `json:"jobKind"`
+		JobKind JobKind `json:"jobKind"`
 
 		// Labels are a dimension of a platform.  The values here can vary wildly,
 		// so most strings are valid for this.  The list of labels that are used
@@ -277,7 +277,7 @@ type (
 		//   * "unknown"
 		//
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/result
-		Result string `json:"result,omitempty"`
+		Result JobDefinitionResult `json:"result,omitempty"`
 
 		// The infrastructure retry iteration on this job.  The number of times this
 		// job has been retried by the infrastructure.
@@ -305,7 +305,7 @@ type (
 		//   * "completed"
 		//
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/state
-		State string `json:"state"`
+		State State `json:"state"`
 
 		// This could just be what was formerly submitted as a job_guid in the
 		// REST API.
@@ -324,13 +324,13 @@ type (
 		Tier int64 `json:"tier,omitempty"`
 
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/timeCompleted
-		TimeCompleted tcclient.Time `json:"timeCompleted,omitempty"`
+		TimeCompleted *tcclient.Time `json:"timeCompleted,omitempty"`
 
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/timeScheduled
-		TimeScheduled tcclient.Time `json:"timeScheduled,omitempty"`
+		TimeScheduled *tcclient.Time `json:"timeScheduled,omitempty"`
 
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/timeStarted
-		TimeStarted tcclient.Time `json:"timeStarted,omitempty"`
+		TimeStarted *tcclient.Time `json:"timeStarted,omitempty"`
 
 		// Message version
 		//
@@ -475,7 +475,7 @@ type (
 		//   * "unknown"
 		//
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/logs/items/properties/steps/items/properties/result
-		Result string `json:"result"`
+		Result StepResult `json:"result"`
 
 		// See https://taskcluster-staging.net/schemas/treeherder/v1/pulse-job.json#/properties/logs/items/properties/steps/items/properties/timeFinished
 		TimeFinished tcclient.Time `json:"timeFinished"`
@@ -484,3 +484,125 @@ type (
 		TimeStarted tcclient.Time `json:"timeStarted"`
 	}
 )
+
+// GithubPullRequestKind is the type of the `kind` property of GithubPullRequest.
+// Its possible values are declared as constants.
+type GithubPullRequestKind string
+
+// Possible values of GithubPullRequestKind
+const (
+	GithubPullRequestKindGithubCom GithubPullRequestKind = "github.com"
+)
+
+// Valid returns true if this is one of the possible values of GithubPullRequestKind.
+func (this GithubPullRequestKind) Valid() bool {
+	switch this {
+	case GithubPullRequestKindGithubCom:
+		return true
+	}
+	return false
+}
+
+// HGPushKind is the type of the `kind` property of HGPush.
+// Its possible values are declared as constants.
+type HGPushKind string
+
+// Possible values of HGPushKind
+const (
+	HGPushKindHgMozillaOrg HGPushKind = "hg.mozilla.org"
+)
+
+// Valid returns true if this is one of the possible values of HGPushKind.
+func (this HGPushKind) Valid() bool {
+	switch this {
+	case HGPushKindHgMozillaOrg:
+		return true
+	}
+	return false
+}
+
+// JobKind is the type of the `jobKind` property of JobDefinition.
+// Its possible values are declared as constants.
+type JobKind string
+
+// Possible values of JobKind
+const (
+	JobKindBuild JobKind = "build"
+	JobKindTest  JobKind = "test"
+	JobKindOther JobKind = "other"
+)
+
+// Valid returns true if this is one of the possible values of JobKind.
+func (this JobKind) Valid() bool {
+	switch this {
+	case JobKindBuild, JobKindTest, JobKindOther:
+		return true
+	}
+	return false
+}
+
+// JobDefinitionResult is the type of the `result` property of JobDefinition.
+// Its possible values are declared as constants.
+type JobDefinitionResult string
+
+// Possible values of JobDefinitionResult
+const (
+	JobDefinitionResultSuccess    JobDefinitionResult = "success"
+	JobDefinitionResultFail       JobDefinitionResult = "fail"
+	JobDefinitionResultException  JobDefinitionResult = "exception"
+	JobDefinitionResultCanceled   JobDefinitionResult = "canceled"
+	JobDefinitionResultSuperseded JobDefinitionResult = "superseded"
+	JobDefinitionResultUnknown    JobDefinitionResult = "unknown"
+)
+
+// Valid returns true if this is one of the possible values of JobDefinitionResult.
+func (this JobDefinitionResult) Valid() bool {
+	switch this {
+	case JobDefinitionResultSuccess, JobDefinitionResultFail, JobDefinitionResultException, JobDefinitionResultCanceled, JobDefinitionResultSuperseded, JobDefinitionResultUnknown:
+		return true
+	}
+	return false
+}
+
+// State is the type of the `state` property of JobDefinition.
+// Its possible values are declared as constants.
+type State string
+
+// Possible values of State
+const (
+	StateUnscheduled State = "unscheduled"
+	StatePending     State = "pending"
+	StateRunning     State = "running"
+	StateCompleted   State = "completed"
+)
+
+// Valid returns true if this is one of the possible values of State.
+func (this State) Valid() bool {
+	switch this {
+	case StateUnscheduled, StatePending, StateRunning, StateCompleted:
+		return true
+	}
+	return false
+}
+
+// StepResult is the type of the `result` property of Step.
+// Its possible values are declared as constants.
+type StepResult string
+
+// Possible values of StepResult
+const (
+	StepResultSuccess   StepResult = "success"
+	StepResultFail      StepResult = "fail"
+	StepResultException StepResult = "exception"
+	StepResultCanceled  StepResult = "canceled"
+	StepResultUnknown   StepResult = "unknown"
+)
+
+// Valid returns true if this is one of the possible values of StepResult.
+func (this StepResult) Valid() bool {
+	switch this {
+	case StepResultSuccess, StepResultFail, StepResultException, StepResultCanceled, StepResultUnknown:
+		return true
+	}
+	return false
+}
@@ -35,9 +35,12 @@
 package tcworkermanager
 
 import (
+	"context"
+	"errors"
 	"net/url"
 
 	tcclient "github.com/taskcluster/taskcluster-client-go"
+	"github.com/taskcluster/taskcluster-client-go/scopes"
 )
 
 type WorkerManager tcclient.Client
@@ -85,13 +88,80 @@ func NewFromEnv() *WorkerManager {
 	}
 }
 
+// NewFromProfile returns a *WorkerManager configured from the named profile of
+// the Taskcluster config file, with environment variables taking precedence
+// over the settings of the profile. If profile is empty, the profile named by
+// TASKCLUSTER_PROFILE, or otherwise the default profile, is used. It is an
+// error if neither the profile nor the environment specifies a root URL.
+//
+// See tcclient.ResolveSettings for details.
+func NewFromProfile(profile string) (*WorkerManager, error) {
+	creds, rootURL, err := tcclient.ResolveSettings(nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if rootURL == "" {
+		return nil, errors.New("tcworkermanager.NewFromProfile: no root URL is set in the Taskcluster config file profile or in the environment")
+	}
+	return New(creds, rootURL), nil
+}
+
+// API is the interface implemented by *WorkerManager, comprising all of its
+// API methods. Code that depends on API rather than *WorkerManager can be tested
+// against a fake implementation instead of a live service.
+type API interface {
+	Ping() error
+	PingWithContext(ctx context.Context) error
+	ListProviders(continuationToken, limit string) (*ProviderList, error)
+	ListProvidersWithContext(ctx context.Context, continuationToken, limit string) (*ProviderList, error)
+	ListProvidersAll(ctx context.Context) *VarIterator
+	CreateWorkerPool(workerPoolId string, payload *WorkerPoolDefinition) (*WorkerPoolFullDefinition, error)
+	CreateWorkerPoolWithContext(ctx context.Context, workerPoolId string, payload *WorkerPoolDefinition) (*WorkerPoolFullDefinition, error)
+	UpdateWorkerPool(workerPoolId string, payload *WorkerPoolDefinition1) (*WorkerPoolFullDefinition, error)
+	UpdateWorkerPoolWithContext(ctx context.Context, workerPoolId string, payload *WorkerPoolDefinition1) (*WorkerPoolFullDefinition, error)
+	WorkerPool(workerPoolId string) (*WorkerPoolFullDefinition, error)
+	WorkerPoolWithContext(ctx context.Context, workerPoolId string) (*WorkerPoolFullDefinition, error)
+	ListWorkerPools(continuationToken, limit string) (*WorkerPoolList, error)
+	ListWorkerPoolsWithContext(ctx context.Context, continuationToken, limit string) (*WorkerPoolList, error)
+	ListWorkerPoolsAll(ctx context.Context) *WorkerPoolFullDefinitionIterator
+	ReportWorkerError(workerPoolId string, payload *WorkerErrorReport) (*WorkerPoolError, error)
+	ReportWorkerErrorWithContext(ctx context.Context, workerPoolId string, payload *WorkerErrorReport) (*WorkerPoolError, error)
+	ListWorkerPoolErrors(workerPoolId, continuationToken, limit string) (*WorkerPoolErrorList, error)
+	ListWorkerPoolErrorsWithContext(ctx context.Context, workerPoolId, continuationToken, limit string) (*WorkerPoolErrorList, error)
+	ListWorkerPoolErrorsAll(ctx context.Context, workerPoolId string) *WorkerPoolErrorIterator
+	ListWorkersForWorkerGroup(workerPoolId, workerGroup, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error)
+	ListWorkersForWorkerGroupWithContext(ctx context.Context, workerPoolId, workerGroup, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error)
+	ListWorkersForWorkerGroupAll(ctx context.Context, workerPoolId, workerGroup string) *WorkerFullDefinitionIterator
+	Worker(workerPoolId, workerGroup, workerId string) (*WorkerFullDefinition, error)
+	WorkerWithContext(ctx context.Context, workerPoolId, workerGroup, workerId string) (*WorkerFullDefinition, error)
+	CreateWorker(workerPoolId, workerGroup, workerId string, payload *WorkerCreationRequest) (*WorkerFullDefinition, error)
+	CreateWorkerWithContext(ctx context.Context, workerPoolId, workerGroup, workerId string, payload *WorkerCreationRequest) (*WorkerFullDefinition, error)
+	RemoveWorker(workerPoolId, workerGroup, workerId string) error
+	RemoveWorkerWithContext(ctx context.Context, workerPoolId, workerGroup, workerId string) error
+	ListWorkersForWorkerPool(workerPoolId, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error)
+	ListWorkersForWorkerPoolWithContext(ctx context.Context, workerPoolId, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error)
+	ListWorkersForWorkerPoolAll(ctx context.Context, workerPoolId string) *WorkerFullDefinitionIterator
+	RegisterWorker(payload *RegisterWorkerRequest) (*RegisterWorkerResponse, error)
+	RegisterWorkerWithContext(ctx context.Context, payload *RegisterWorkerRequest) (*RegisterWorkerResponse, error)
+}
+
+var _ API = (*WorkerManager)(nil)
+
 // Respond without doing anything.
 // This endpoint is used to check that the service is up.
 //
 // See #ping
 func (workerManager *WorkerManager) Ping() error {
+	return workerManager.PingWithContext(workerManager.Context)
+}
+
+// PingWithContext is the same as Ping, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See Ping for more details.
+func (workerManager *WorkerManager) PingWithContext(ctx context.Context) error {
 	cd := tcclient.Client(*workerManager)
-	_, _, err := (&cd).APICall(nil, "GET", "/ping", nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "ping"), nil, "GET", "/ping", nil, nil)
 	return err
 }
 
@@ -99,6 +169,14 @@ func (workerManager *WorkerManager) Ping() error {
 //
 // See #listProviders
 func (workerManager *WorkerManager) ListProviders(continuationToken, limit string) (*ProviderList, error) {
+	return workerManager.ListProvidersWithContext(workerManager.Context, continuationToken, limit)
+}
+
+// ListProvidersWithContext is the same as ListProviders, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See ListProviders for more details.
+func (workerManager *WorkerManager) ListProvidersWithContext(ctx context.Context, continuationToken, limit string) (*ProviderList, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -107,10 +185,27 @@ func (workerManager *WorkerManager) ListProviders(continuationToken, limit strin
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/providers", new(ProviderList), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "listProviders"), nil, "GET", "/providers", new(ProviderList), v)
 	return responseObject.(*ProviderList), err
 }
 
+// ListProvidersAll returns an iterator over the Var items of all
+// pages of ListProviders results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListProviders for more details.
+func (workerManager *WorkerManager) ListProvidersAll(ctx context.Context) *VarIterator {
+	return NewVarIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]Var, string, error) {
+		page, err := workerManager.ListProvidersWithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Providers, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Create a new worker pool. If the worker pool already exists, this will throw an error.
@@ -122,8 +217,16 @@ func (workerManager *WorkerManager) ListProviders(continuationToken, limit strin
 //
 // See #createWorkerPool
 func (workerManager *WorkerManager) CreateWorkerPool(workerPoolId string, payload *WorkerPoolDefinition) (*WorkerPoolFullDefinition, error) {
+	return workerManager.CreateWorkerPoolWithContext(workerManager.Context, workerPoolId, payload)
+}
+
+// CreateWorkerPoolWithContext is the same as CreateWorkerPool, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See CreateWorkerPool for more details.
+func (workerManager *WorkerManager) CreateWorkerPoolWithContext(ctx context.Context, workerPoolId string, payload *WorkerPoolDefinition) (*WorkerPoolFullDefinition, error) {
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/worker-pool/"+url.QueryEscape(workerPoolId), new(WorkerPoolFullDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "createWorkerPool"), payload, "PUT", "/worker-pool/"+url.QueryEscape(workerPoolId), new(WorkerPoolFullDefinition), nil)
 	return responseObject.(*WorkerPoolFullDefinition), err
 }
 
@@ -144,8 +247,16 @@ func (workerManager *WorkerManager) CreateWorkerPool(workerPoolId string, payloa
 //
 // See #updateWorkerPool
 func (workerManager *WorkerManager) UpdateWorkerPool(workerPoolId string, payload *WorkerPoolDefinition1) (*WorkerPoolFullDefinition, error) {
+	return workerManager.UpdateWorkerPoolWithContext(workerManager.Context, workerPoolId, payload)
+}
+
+// UpdateWorkerPoolWithContext is the same as UpdateWorkerPool, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See UpdateWorkerPool for more details.
+func (workerManager *WorkerManager) UpdateWorkerPoolWithContext(ctx context.Context, workerPoolId string, payload *WorkerPoolDefinition1) (*WorkerPoolFullDefinition, error) {
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/worker-pool/"+url.QueryEscape(workerPoolId), new(WorkerPoolFullDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "updateWorkerPool"), payload, "POST", "/worker-pool/"+url.QueryEscape(workerPoolId), new(WorkerPoolFullDefinition), nil)
 	return responseObject.(*WorkerPoolFullDefinition), err
 }
 
@@ -155,8 +266,16 @@ func (workerManager *WorkerManager) UpdateWorkerPool(workerPoolId string, payloa
 //
 // See #workerPool
 func (workerManager *WorkerManager) WorkerPool(workerPoolId string) (*WorkerPoolFullDefinition, error) {
+	return workerManager.WorkerPoolWithContext(workerManager.Context, workerPoolId)
+}
+
+// WorkerPoolWithContext is the same as WorkerPool, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See WorkerPool for more details.
+func (workerManager *WorkerManager) WorkerPoolWithContext(ctx context.Context, workerPoolId string) (*WorkerPoolFullDefinition, error) {
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-pool/"+url.QueryEscape(workerPoolId), new(WorkerPoolFullDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "workerPool"), nil, "GET", "/worker-pool/"+url.QueryEscape(workerPoolId), new(WorkerPoolFullDefinition), nil)
 	return responseObject.(*WorkerPoolFullDefinition), err
 }
 
@@ -166,6 +285,14 @@ func (workerManager *WorkerManager) WorkerPool(workerPoolId string) (*WorkerPool
 //
 // See #listWorkerPools
 func (workerManager *WorkerManager) ListWorkerPools(continuationToken, limit string) (*WorkerPoolList, error) {
+	return workerManager.ListWorkerPoolsWithContext(workerManager.Context, continuationToken, limit)
+}
+
+// ListWorkerPoolsWithContext is the same as ListWorkerPools, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See ListWorkerPools for more details.
+func (workerManager *WorkerManager) ListWorkerPoolsWithContext(ctx context.Context, continuationToken, limit string) (*WorkerPoolList, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -174,10 +301,27 @@ func (workerManager *WorkerManager) ListWorkerPools(continuationToken, limit str
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-pools", new(WorkerPoolList), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "listWorkerPools"), nil, "GET", "/worker-pools", new(WorkerPoolList), v)
 	return responseObject.(*WorkerPoolList), err
 }
 
+// ListWorkerPoolsAll returns an iterator over the WorkerPoolFullDefinition items of all
+// pages of ListWorkerPools results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListWorkerPools for more details.
+func (workerManager *WorkerManager) ListWorkerPoolsAll(ctx context.Context) *WorkerPoolFullDefinitionIterator {
+	return NewWorkerPoolFullDefinitionIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]WorkerPoolFullDefinition, string, error) {
+		page, err := workerManager.ListWorkerPoolsWithContext(ctx, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.WorkerPools, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Report an error that occurred on a worker.  This error will be included
@@ -197,8 +341,16 @@ func (workerManager *WorkerManager) ListWorkerPools(continuationToken, limit str
 //
 // See #reportWorkerError
 func (workerManager *WorkerManager) ReportWorkerError(workerPoolId string, payload *WorkerErrorReport) (*WorkerPoolError, error) {
+	return workerManager.ReportWorkerErrorWithContext(workerManager.Context, workerPoolId, payload)
+}
+
+// ReportWorkerErrorWithContext is the same as ReportWorkerError, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See ReportWorkerError for more details.
+func (workerManager *WorkerManager) ReportWorkerErrorWithContext(ctx context.Context, workerPoolId string, payload *WorkerErrorReport) (*WorkerPoolError, error) {
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/worker-pool-errors/"+url.QueryEscape(workerPoolId), new(WorkerPoolError), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "reportWorkerError"), payload, "POST", "/worker-pool-errors/"+url.QueryEscape(workerPoolId), new(WorkerPoolError), nil)
 	return responseObject.(*WorkerPoolError), err
 }
 
@@ -208,6 +360,14 @@ func (workerManager *WorkerManager) ReportWorkerError(workerPoolId string, paylo
 //
 // See #listWorkerPoolErrors
 func (workerManager *WorkerManager) ListWorkerPoolErrors(workerPoolId, continuationToken, limit string) (*WorkerPoolErrorList, error) {
+	return workerManager.ListWorkerPoolErrorsWithContext(workerManager.Context, workerPoolId, continuationToken, limit)
+}
+
+// ListWorkerPoolErrorsWithContext is the same as ListWorkerPoolErrors, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See ListWorkerPoolErrors for more details.
+func (workerManager *WorkerManager) ListWorkerPoolErrorsWithContext(ctx context.Context, workerPoolId, continuationToken, limit string) (*WorkerPoolErrorList, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -216,16 +376,41 @@ func (workerManager *WorkerManager) ListWorkerPoolErrors(workerPoolId, continuat
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/worker-pool-errors/"+url.QueryEscape(workerPoolId), new(WorkerPoolErrorList), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "listWorkerPoolErrors"), nil, "GET", "/worker-pool-errors/"+url.QueryEscape(workerPoolId), new(WorkerPoolErrorList), v)
 	return responseObject.(*WorkerPoolErrorList), err
 }
 
+// ListWorkerPoolErrorsAll returns an iterator over the WorkerPoolError items of all
+// pages of ListWorkerPoolErrors results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListWorkerPoolErrors for more details.
+func (workerManager *WorkerManager) ListWorkerPoolErrorsAll(ctx context.Context, workerPoolId string) *WorkerPoolErrorIterator {
+	return NewWorkerPoolErrorIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]WorkerPoolError, string, error) {
+		page, err := workerManager.ListWorkerPoolErrorsWithContext(ctx, workerPoolId, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.WorkerPoolErrors, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Get the list of all the existing workers in a given group in a given worker pool.
 //
 // See #listWorkersForWorkerGroup
 func (workerManager *WorkerManager) ListWorkersForWorkerGroup(workerPoolId, workerGroup, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error) {
+	return workerManager.ListWorkersForWorkerGroupWithContext(workerManager.Context, workerPoolId, workerGroup, continuationToken, limit)
+}
+
+// ListWorkersForWorkerGroupWithContext is the same as ListWorkersForWorkerGroup, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See ListWorkersForWorkerGroup for more details.
+func (workerManager *WorkerManager) ListWorkersForWorkerGroupWithContext(ctx context.Context, workerPoolId, workerGroup, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -234,18 +419,43 @@ func (workerManager *WorkerManager) ListWorkersForWorkerGroup(workerPoolId, work
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup), new(WorkerListInAGivenWorkerPool), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "listWorkersForWorkerGroup"), nil, "GET", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup), new(WorkerListInAGivenWorkerPool), v)
 	return responseObject.(*WorkerListInAGivenWorkerPool), err
 }
 
+// ListWorkersForWorkerGroupAll returns an iterator over the WorkerFullDefinition items of all
+// pages of ListWorkersForWorkerGroup results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListWorkersForWorkerGroup for more details.
+func (workerManager *WorkerManager) ListWorkersForWorkerGroupAll(ctx context.Context, workerPoolId, workerGroup string) *WorkerFullDefinitionIterator {
+	return NewWorkerFullDefinitionIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]WorkerFullDefinition, string, error) {
+		page, err := workerManager.ListWorkersForWorkerGroupWithContext(ctx, workerPoolId, workerGroup, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Workers, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Get a single worker.
 //
 // See #worker
 func (workerManager *WorkerManager) Worker(workerPoolId, workerGroup, workerId string) (*WorkerFullDefinition, error) {
+	return workerManager.WorkerWithContext(workerManager.Context, workerPoolId, workerGroup, workerId)
+}
+
+// WorkerWithContext is the same as Worker, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See Worker for more details.
+func (workerManager *WorkerManager) WorkerWithContext(ctx context.Context, workerPoolId, workerGroup, workerId string) (*WorkerFullDefinition, error) {
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerFullDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "worker"), nil, "GET", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerFullDefinition), nil)
 	return responseObject.(*WorkerFullDefinition), err
 }
 
@@ -260,8 +470,16 @@ func (workerManager *WorkerManager) Worker(workerPoolId, workerGroup, workerId s
 //
 // See #createWorker
 func (workerManager *WorkerManager) CreateWorker(workerPoolId, workerGroup, workerId string, payload *WorkerCreationRequest) (*WorkerFullDefinition, error) {
+	return workerManager.CreateWorkerWithContext(workerManager.Context, workerPoolId, workerGroup, workerId, payload)
+}
+
+// CreateWorkerWithContext is the same as CreateWorker, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See CreateWorker for more details.
+func (workerManager *WorkerManager) CreateWorkerWithContext(ctx context.Context, workerPoolId, workerGroup, workerId string, payload *WorkerCreationRequest) (*WorkerFullDefinition, error) {
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(payload, "PUT", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerFullDefinition), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "createWorker"), payload, "PUT", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), new(WorkerFullDefinition), nil)
 	return responseObject.(*WorkerFullDefinition), err
 }
 
@@ -278,8 +496,16 @@ func (workerManager *WorkerManager) CreateWorker(workerPoolId, workerGroup, work
 //
 // See #removeWorker
 func (workerManager *WorkerManager) RemoveWorker(workerPoolId, workerGroup, workerId string) error {
+	return workerManager.RemoveWorkerWithContext(workerManager.Context, workerPoolId, workerGroup, workerId)
+}
+
+// RemoveWorkerWithContext is the same as RemoveWorker, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See RemoveWorker for more details.
+func (workerManager *WorkerManager) RemoveWorkerWithContext(ctx context.Context, workerPoolId, workerGroup, workerId string) error {
 	cd := tcclient.Client(*workerManager)
-	_, _, err := (&cd).APICall(nil, "DELETE", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), nil, nil)
+	_, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "removeWorker"), nil, "DELETE", "/workers/"+url.QueryEscape(workerPoolId)+":/"+url.QueryEscape(workerGroup)+"/"+url.QueryEscape(workerId), nil, nil)
 	return err
 }
 
@@ -289,6 +515,14 @@ func (workerManager *WorkerManager) RemoveWorker(workerPoolId, workerGroup, work
 //
 // See #listWorkersForWorkerPool
 func (workerManager *WorkerManager) ListWorkersForWorkerPool(workerPoolId, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error) {
+	return workerManager.ListWorkersForWorkerPoolWithContext(workerManager.Context, workerPoolId, continuationToken, limit)
+}
+
+// ListWorkersForWorkerPoolWithContext is the same as ListWorkersForWorkerPool, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See ListWorkersForWorkerPool for more details.
+func (workerManager *WorkerManager) ListWorkersForWorkerPoolWithContext(ctx context.Context, workerPoolId, continuationToken, limit string) (*WorkerListInAGivenWorkerPool, error) {
 	v := url.Values{}
 	if continuationToken != "" {
 		v.Add("continuationToken", continuationToken)
@@ -297,10 +531,27 @@ func (workerManager *WorkerManager) ListWorkersForWorkerPool(workerPoolId, conti
 		v.Add("limit", limit)
 	}
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(nil, "GET", "/workers/"+url.QueryEscape(workerPoolId), new(WorkerListInAGivenWorkerPool), v)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "listWorkersForWorkerPool"), nil, "GET", "/workers/"+url.QueryEscape(workerPoolId), new(WorkerListInAGivenWorkerPool), v)
 	return responseObject.(*WorkerListInAGivenWorkerPool), err
 }
 
+// ListWorkersForWorkerPoolAll returns an iterator over the WorkerFullDefinition items of all
+// pages of ListWorkersForWorkerPool results, fetching each page as it is needed, so
+// that callers need not follow continuation tokens themselves. The
+// iteration stops early if ctx is cancelled; always check Err once Next
+// has returned false.
+//
+// See ListWorkersForWorkerPool for more details.
+func (workerManager *WorkerManager) ListWorkersForWorkerPoolAll(ctx context.Context, workerPoolId string) *WorkerFullDefinitionIterator {
+	return NewWorkerFullDefinitionIterator(ctx, func(ctx context.Context, continuationToken, limit string) ([]WorkerFullDefinition, string, error) {
+		page, err := workerManager.ListWorkersForWorkerPoolWithContext(ctx, workerPoolId, continuationToken, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Workers, page.ContinuationToken, nil
+	})
+}
+
 // Stability: *** EXPERIMENTAL ***
 //
 // Register a running worker.  Workers call this method on worker start-up.
@@ -311,7 +562,164 @@ func (workerManager *WorkerManager) ListWorkersForWorkerPool(workerPoolId, conti
 //
 // See #registerWorker
 func (workerManager *WorkerManager) RegisterWorker(payload *RegisterWorkerRequest) (*RegisterWorkerResponse, error) {
+	return workerManager.RegisterWorkerWithContext(workerManager.Context, payload)
+}
+
+// RegisterWorkerWithContext is the same as RegisterWorker, except that the
+// request is bound to ctx rather than to workerManager.Context.
+//
+// See RegisterWorker for more details.
+func (workerManager *WorkerManager) RegisterWorkerWithContext(ctx context.Context, payload *RegisterWorkerRequest) (*RegisterWorkerResponse, error) {
 	cd := tcclient.Client(*workerManager)
-	responseObject, _, err := (&cd).APICall(payload, "POST", "/worker/register", new(RegisterWorkerResponse), nil)
+	responseObject, _, err := (&cd).APICallWithContext(tcclient.WithEndpoint(ctx, "worker-manager", "registerWorker"), payload, "POST", "/worker/register", new(RegisterWorkerResponse), nil)
 	return responseObject.(*RegisterWorkerResponse), err
 }
+
+// VarIterator iterates over the Var items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type VarIterator struct {
+	*tcclient.Pager
+	items []Var
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *VarIterator) Item() Var {
+	return it.items[it.Index()]
+}
+
+// NewVarIterator returns a VarIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewVarIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]Var, string, error)) *VarIterator {
+	it := new(VarIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// WorkerPoolFullDefinitionIterator iterates over the WorkerPoolFullDefinition items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type WorkerPoolFullDefinitionIterator struct {
+	*tcclient.Pager
+	items []WorkerPoolFullDefinition
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *WorkerPoolFullDefinitionIterator) Item() WorkerPoolFullDefinition {
+	return it.items[it.Index()]
+}
+
+// NewWorkerPoolFullDefinitionIterator returns a WorkerPoolFullDefinitionIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewWorkerPoolFullDefinitionIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]WorkerPoolFullDefinition, string, error)) *WorkerPoolFullDefinitionIterator {
+	it := new(WorkerPoolFullDefinitionIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// WorkerPoolErrorIterator iterates over the WorkerPoolError items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type WorkerPoolErrorIterator struct {
+	*tcclient.Pager
+	items []WorkerPoolError
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *WorkerPoolErrorIterator) Item() WorkerPoolError {
+	return it.items[it.Index()]
+}
+
+// NewWorkerPoolErrorIterator returns a WorkerPoolErrorIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewWorkerPoolErrorIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]WorkerPoolError, string, error)) *WorkerPoolErrorIterator {
+	it := new(WorkerPoolErrorIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// WorkerFullDefinitionIterator iterates over the WorkerFullDefinition items of all pages of
+// a paginated API method, fetching each page as it is needed. See
+// tcclient.Pager for details.
+type WorkerFullDefinitionIterator struct {
+	*tcclient.Pager
+	items []WorkerFullDefinition
+}
+
+// Item returns the current item. It may only be called after Next has
+// returned true.
+func (it *WorkerFullDefinitionIterator) Item() WorkerFullDefinition {
+	return it.items[it.Index()]
+}
+
+// NewWorkerFullDefinitionIterator returns a WorkerFullDefinitionIterator which fetches pages of
+// items with fetch, bound to ctx. See tcclient.PageFetcher for the meaning of
+// the arguments and results of fetch, which returns the items of the page
+// rather than their number. Fakes of API can use it to implement the XXXAll
+// methods.
+func NewWorkerFullDefinitionIterator(ctx context.Context, fetch func(ctx context.Context, continuationToken, limit string) ([]WorkerFullDefinition, string, error)) *WorkerFullDefinitionIterator {
+	it := new(WorkerFullDefinitionIterator)
+	it.Pager = tcclient.NewPager(ctx, func(ctx context.Context, continuationToken, limit string) (int, string, error) {
+		items, next, err := fetch(ctx, continuationToken, limit)
+		if err != nil {
+			return 0, "", err
+		}
+		it.items = items
+		return len(items), next, nil
+	})
+	return it
+}
+
+// RequiredScopes holds the scope expression templates of the API methods
+// that require scopes, keyed by API method name as reported by
+// tcclient.EndpointFromContext. Use scopes.Evaluate to check whether a set of
+// scopes will authorize a call.
+var RequiredScopes = map[string]scopes.Template{
+	"createWorkerPool": scopes.AllOf{
+		scopes.RequiredScope("worker-manager:create-worker-type:<workerPoolId>"),
+		scopes.RequiredScope("worker-manager:provider:<providerId>"),
+	},
+	"updateWorkerPool": scopes.AllOf{
+		scopes.RequiredScope("worker-manager:update-worker-type:<workerPoolId>"),
+		scopes.RequiredScope("worker-manager:provider:<providerId>"),
+	},
+	"reportWorkerError": scopes.AllOf{
+		scopes.RequiredScope("assume:worker-pool:<workerPoolId>"),
+		scopes.RequiredScope("assume:worker-id:<workerGroup>/<workerId>"),
+	},
+	"createWorker": scopes.RequiredScope("worker-manager:create-worker:<workerPoolId>/<workerGroup>/<workerId>"),
+	"removeWorker": scopes.RequiredScope("worker-manager:remove-worker:<workerPoolId>/<workerGroup>/<workerId>"),
+}
@@ -230,7 +230,7 @@ type (
 		//   * "requested"
 		//   * "running"
 		//   * "stopped"
-		State string `json:"state"`
+		State State `json:"state"`
 
 		// Worker group to which this worker belongs
 		//
@@ -314,7 +314,7 @@ type (
 		Config json.RawMessage `json:"config"`
 
 		// Ignored on update
-		Created tcclient.Time `json:"created,omitempty"`
+		Created *tcclient.Time `json:"created,omitempty"`
 
 		// A description of this worker pool.
 		//
@@ -325,7 +325,7 @@ type (
 		EmailOnError bool `json:"emailOnError"`
 
 		// Ignored on update
-		LastModified tcclient.Time `json:"lastModified,omitempty"`
+		LastModified *tcclient.Time `json:"lastModified,omitempty"`
 
 		// An email address to notify when there are provisioning errors for this
 		// worker pool.
@@ -455,3 +455,23 @@ type (
 		WorkerPools []WorkerPoolFullDefinition `json:"workerPools"`
 	}
 )
+
+// State is the type of the `state` property of WorkerFullDefinition.
+// Its possible values are declared as constants.
+type State string
+
+// Possible values of State
+const (
+	StateRequested State = "requested"
+	StateRunning   State = "running"
+	StateStopped   State = "stopped"
+)
+
+// Valid returns true if this is one of the possible values of State.
+func (this State) Valid() bool {
+	switch this {
+	case StateRequested, StateRunning, StateStopped:
+		return true
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package tcclient
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -16,7 +18,12 @@ type Time time.Time
 
 // MarshalJSON implements the json.Marshaler interface.
 // The time is a quoted string in RFC 3339 format, with sub-second precision added if present.
+// The zero time is marshaled as null. Optional properties of generated types
+// are *Time, so that unset times are omitted instead.
 func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
 	if y := time.Time(t).Year(); y < 0 || y >= 10000 {
 		// RFC 3339 is clear that years are 4 digits exactly.
 		// See golang.org/issue/4556#c15 for more discussion.
@@ -25,14 +32,47 @@ func (t Time) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + t.String() + `"`), nil
 }
 
+// timeLayouts are the layouts accepted by UnmarshalJSON, in order of
+// preference. Layouts without a timezone are interpreted as UTC.
+var timeLayouts = []string{
+	// also matches fractional seconds of any precision
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
-// The time is expected to be a quoted string in RFC 3339 format.
+// The time is expected to be a quoted string in RFC 3339 format, although
+// any precision of fractional seconds, timezone offsets without a colon,
+// times without seconds or timezone (taken to be UTC), a space in place of
+// the 'T', and plain dates are also accepted. The JSON value null and the
+// empty string leave the time unchanged, i.e. the zero time for a new
+// value.
 func (t *Time) UnmarshalJSON(data []byte) (err error) {
-	// Fractional seconds are handled implicitly by Parse.
-	x := new(time.Time)
-	*x, err = time.Parse(`"`+time.RFC3339+`"`, string(data))
-	*t = Time(*x)
-	return
+	if string(data) == "null" || string(data) == `""` {
+		return nil
+	}
+	var s string
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		return fmt.Errorf("tcclient.Time.UnmarshalJSON: expected a string but got %s", data)
+	}
+	for _, layout := range timeLayouts {
+		// Fractional seconds are handled implicitly by Parse.
+		x, parseErr := time.Parse(layout, s)
+		if parseErr == nil {
+			*t = Time(x)
+			return nil
+		}
+		if err == nil {
+			err = parseErr
+		}
+	}
+	return fmt.Errorf("tcclient.Time.UnmarshalJSON: cannot parse %q as a time: %v", s, err)
 }
 
 // Returns the Time in canonical RFC3339 representation, e.g.
@@ -40,3 +80,44 @@ func (t *Time) UnmarshalJSON(data []byte) (err error) {
 func (t Time) String() string {
 	return time.Time(t).UTC().Format("2006-01-02T15:04:05.000Z")
 }
+
+// Time returns t as a time.Time.
+func (t Time) Time() time.Time {
+	return time.Time(t)
+}
+
+// IsZero reports whether t is the zero time.
+func (t Time) IsZero() bool {
+	return time.Time(t).IsZero()
+}
+
+// Before reports whether t is before u.
+func (t Time) Before(u Time) bool {
+	return time.Time(t).Before(time.Time(u))
+}
+
+// After reports whether t is after u.
+func (t Time) After(u Time) bool {
+	return time.Time(t).After(time.Time(u))
+}
+
+// Equal reports whether t and u represent the same instant.
+func (t Time) Equal(u Time) bool {
+	return time.Time(t).Equal(time.Time(u))
+}
+
+// Add returns t+d.
+func (t Time) Add(d time.Duration) Time {
+	return Time(time.Time(t).Add(d))
+}
+
+// AddDate returns t with the given number of years, months and days added,
+// normalized in the same way as time.Time.AddDate.
+func (t Time) AddDate(years, months, days int) Time {
+	return Time(time.Time(t).AddDate(years, months, days))
+}
+
+// Sub returns the duration t-u.
+func (t Time) Sub(u Time) time.Duration {
+	return time.Time(t).Sub(time.Time(u))
+}
@@ -0,0 +1,89 @@
+package tcclient
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeMarshalJSON(t *testing.T) {
+	testCases := []struct {
+		time     Time
+		expected string
+	}{
+		{Time{}, `null`},
+		{Time(time.Date(2015, time.October, 27, 20, 36, 19, 255123456, time.UTC)), `"2015-10-27T20:36:19.255Z"`},
+		{Time(time.Date(2015, time.October, 27, 21, 36, 19, 0, time.FixedZone("CET", 60*60))), `"2015-10-27T20:36:19.000Z"`},
+	}
+	for _, tc := range testCases {
+		data, err := json.Marshal(tc.time)
+		if err != nil {
+			t.Errorf("Unexpected error marshaling %v: %v", tc.time, err)
+			continue
+		}
+		if string(data) != tc.expected {
+			t.Errorf("Expected %v but got %s", tc.expected, data)
+		}
+	}
+}
+
+func TestTimeUnmarshalJSON(t *testing.T) {
+	expected := time.Date(2015, time.October, 27, 20, 36, 19, 0, time.UTC)
+	testCases := []struct {
+		json     string
+		expected time.Time
+	}{
+		{`"2015-10-27T20:36:19.255Z"`, expected.Add(255 * time.Millisecond)},
+		{`"2015-10-27T20:36:19Z"`, expected},
+		{`"2015-10-27T20:36:19.123456789Z"`, expected.Add(123456789)},
+		{`"2015-10-27T20:36:19.1Z"`, expected.Add(100 * time.Millisecond)},
+		{`"2015-10-27T21:36:19+01:00"`, expected},
+		{`"2015-10-27T21:36:19+0100"`, expected},
+		{`"2015-10-27T20:36Z"`, expected.Add(-19 * time.Second)},
+		{`"2015-10-27T20:36:19"`, expected},
+		{`"2015-10-27 20:36:19Z"`, expected},
+		{`"2015-10-27 20:36:19.5"`, expected.Add(500 * time.Millisecond)},
+		{`"2015-10-27"`, time.Date(2015, time.October, 27, 0, 0, 0, 0, time.UTC)},
+		{`null`, time.Time{}},
+		{`""`, time.Time{}},
+	}
+	for _, tc := range testCases {
+		var got Time
+		err := json.Unmarshal([]byte(tc.json), &got)
+		if err != nil {
+			t.Errorf("Unexpected error unmarshaling %v: %v", tc.json, err)
+			continue
+		}
+		if !got.Time().Equal(tc.expected) {
+			t.Errorf("Unmarshaling %v: expected %v but got %v", tc.json, tc.expected, got.Time())
+		}
+	}
+	for _, invalid := range []string{`"yesterday"`, `"2015-13-27T20:36:19Z"`, `12345`, `true`} {
+		var got Time
+		if err := json.Unmarshal([]byte(invalid), &got); err == nil {
+			t.Errorf("Expected an error unmarshaling %v, but got %v", invalid, got)
+		}
+	}
+}
+
+func TestTimeUnmarshalOptionalField(t *testing.T) {
+	var status struct {
+		NextScheduledDate *Time `json:"nextScheduledDate,omitempty"`
+		Resolved          *Time `json:"resolved,omitempty"`
+	}
+	err := json.Unmarshal([]byte(`{"nextScheduledDate": null, "resolved": "2015-10-27T20:36:19.255Z"}`), &status)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status.NextScheduledDate != nil || status.Resolved == nil || status.Resolved.IsZero() {
+		t.Errorf("Unexpected result %#v", status)
+	}
+	status.Resolved = nil
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != "{}" {
+		t.Errorf("Expected unset optional times to be omitted, but got %s", data)
+	}
+}
@@ -0,0 +1,90 @@
+package tcclient
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	hawk "github.com/tent/hawk-go"
+)
+
+// ResponseVerificationError is returned when Client.VerifyResponses is set,
+// and a response from a Taskcluster service could not be authenticated.
+type ResponseVerificationError struct {
+	// Why verification failed
+	Reason string
+}
+
+func (err *ResponseVerificationError) Error() string {
+	return "tcclient: response verification failed: " + err.Reason
+}
+
+var serverAuthHashAttr = regexp.MustCompile(`(?:^|[\s,])hash="([^"]*)"`)
+
+// verifyResponse checks the Server-Authorization header of resp against the
+// Hawk auth of the request. Since the payload hash can only be checked once
+// the whole response body has been read, resp.Body is replaced by a reader
+// that returns a *ResponseVerificationError instead of io.EOF if the body does
+// not match the hash.
+func verifyResponse(auth *hawk.Auth, resp *http.Response) error {
+	header := resp.Header.Get("Server-Authorization")
+	if header == "" {
+		return &ResponseVerificationError{Reason: "no Server-Authorization header in response"}
+	}
+	if err := auth.ValidResponse(header); err != nil {
+		return &ResponseVerificationError{Reason: "invalid Server-Authorization header: " + err.Error()}
+	}
+	match := serverAuthHashAttr.FindStringSubmatch(header)
+	if match == nil {
+		return &ResponseVerificationError{Reason: "no payload hash in Server-Authorization header"}
+	}
+	expected, err := base64.StdEncoding.DecodeString(match[1])
+	if err != nil {
+		return &ResponseVerificationError{Reason: "invalid payload hash in Server-Authorization header: " + err.Error()}
+	}
+	resp.Body = &verifyingReader{
+		ReadCloser: resp.Body,
+		hash:       auth.PayloadHash(hawkContentType(resp.Header.Get("Content-Type"))),
+		expected:   expected,
+	}
+	return nil
+}
+
+// hawkContentType normalizes a Content-Type header value as required for
+// Hawk payload hashes, i.e. lower case, without parameters.
+func hawkContentType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+// verifyingReader hashes the response body as it is read, and checks the
+// hash once the end of the body is reached.
+type verifyingReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected []byte
+	// result of verification, once the end of the body has been reached
+	done   bool
+	result error
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, r.result
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		r.done = true
+		r.result = io.EOF
+		r.hash.Write([]byte("\n"))
+		if !hmac.Equal(r.hash.Sum(nil), r.expected) {
+			r.result = &ResponseVerificationError{Reason: "response body does not match payload hash in Server-Authorization header"}
+		}
+		return n, r.result
+	}
+	return n, err
+}
@@ -0,0 +1,118 @@
+package tcclient
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	hawk "github.com/tent/hawk-go"
+)
+
+var hawkAttr = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// hawkServer returns a test server that responds with body, signed with a
+// Server-Authorization header, which tamper may then alter.
+func hawkServer(creds *Credentials, body string, tamper func(header http.Header, body string) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attrs := map[string]string{}
+		for _, match := range hawkAttr.FindAllStringSubmatch(r.Header.Get("Authorization"), -1) {
+			attrs[match[1]] = match[2]
+		}
+		auth := hawk.NewRequestAuth(r, &hawk.Credentials{
+			ID:   creds.ClientID,
+			Key:  creds.AccessToken,
+			Hash: sha256.New,
+		}, 0)
+		ts, _ := strconv.ParseInt(attrs["ts"], 10, 64)
+		auth.Timestamp = time.Unix(ts, 0)
+		auth.Nonce = attrs["nonce"]
+		h := auth.PayloadHash("application/json")
+		h.Write([]byte(body))
+		auth.SetHash(h)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Server-Authorization", auth.ResponseHeader(""))
+		tampered := tamper(w.Header(), body)
+		w.WriteHeader(200)
+		w.Write([]byte(tampered))
+	}))
+}
+
+func TestVerifyResponses(t *testing.T) {
+	creds := &Credentials{
+		ClientID:    "tester",
+		AccessToken: "no-secret",
+	}
+	body := `{"value": "hello world"}`
+	testCases := map[string]struct {
+		tamper func(header http.Header, body string) string
+		valid  bool
+	}{
+		"untouched": {
+			tamper: func(header http.Header, body string) string { return body },
+			valid:  true,
+		},
+		"missing header": {
+			tamper: func(header http.Header, body string) string {
+				header.Del("Server-Authorization")
+				return body
+			},
+		},
+		"wrong mac": {
+			tamper: func(header http.Header, body string) string {
+				header.Set("Server-Authorization", `Hawk mac="bm90IGEgbWFj", hash="bm90IGEgaGFzaA=="`)
+				return body
+			},
+		},
+		"altered body": {
+			tamper: func(header http.Header, body string) string { return `{"value": "goodbye world"}` },
+		},
+	}
+	for name, tc := range testCases {
+		for _, streaming := range []bool{false, true} {
+			s := hawkServer(creds, body, tc.tamper)
+			client := Client{
+				Credentials:        creds,
+				BaseURL:            s.URL,
+				Authenticate:       true,
+				VerifyResponses:    true,
+				RetryPolicy:        NoRetries,
+				DisableBodyCapture: streaming,
+			}
+			var result struct {
+				Value string `json:"value"`
+			}
+			_, _, err := client.APICall(nil, "GET", "/whatever?a=b", &result, nil)
+			s.Close()
+			if tc.valid && err != nil {
+				t.Errorf("%v (streaming: %v): unexpected error: %v", name, streaming, err)
+			}
+			if tc.valid {
+				continue
+			}
+			if err == nil {
+				t.Errorf("%v (streaming: %v): expected verification to fail", name, streaming)
+				continue
+			}
+			if _, ok := err.(*APICallException).RootCause.(*ResponseVerificationError); !ok {
+				t.Errorf("%v (streaming: %v): expected *ResponseVerificationError but got %v", name, streaming, err)
+			}
+		}
+	}
+}
+
+func TestVerifyResponsesRequiresAuthentication(t *testing.T) {
+	s, _ := failingServer(0, 200, nil)
+	defer s.Close()
+	client := Client{
+		BaseURL:         s.URL,
+		VerifyResponses: true,
+	}
+	_, err := client.Request(nil, "GET", "/whatever", nil)
+	if _, ok := err.(*ResponseVerificationError); !ok {
+		t.Fatalf("Expected *ResponseVerificationError but got %v", err)
+	}
+}